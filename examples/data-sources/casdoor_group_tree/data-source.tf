@@ -0,0 +1,10 @@
+data "casdoor_group_tree" "org" {
+  owner = "my-organization"
+}
+
+# Map every group to its member count, including members of subgroups.
+output "group_member_counts" {
+  value = {
+    for g in data.casdoor_group_tree.org.groups : join("/", g.path) => g.total_member_count
+  }
+}
@@ -0,0 +1,10 @@
+data "casdoor_orders" "january" {
+  owner          = "my-organization"
+  state          = "Paid"
+  created_after  = "2026-01-01T00:00:00Z"
+  created_before = "2026-02-01T00:00:00Z"
+}
+
+output "january_revenue" {
+  value = sum(concat([0], [for o in data.casdoor_orders.january.orders : o.price]))
+}
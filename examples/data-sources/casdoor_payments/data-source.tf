@@ -0,0 +1,13 @@
+data "casdoor_payments" "pending" {
+  owner   = "my-organization"
+  product = "legacy-plan"
+  state   = "Created"
+}
+
+# Refuse to go on while payments for the product are still pending.
+check "no_pending_payments" {
+  assert {
+    condition     = length(data.casdoor_payments.pending.payments) == 0
+    error_message = "There are pending payments for legacy-plan."
+  }
+}
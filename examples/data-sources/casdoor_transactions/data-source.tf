@@ -0,0 +1,8 @@
+data "casdoor_transactions" "alice" {
+  owner = "my-organization"
+  user  = "alice"
+}
+
+output "alice_net_amount" {
+  value = sum(concat([0], [for t in data.casdoor_transactions.alice.transactions : t.amount]))
+}
@@ -19,3 +19,11 @@ provider "casdoor" {
 #   username          = "admin"
 #   password          = var.casdoor_admin_password
 # }
+
+# Authentication Method 3: User Access Key (for machine-to-machine use)
+# provider "casdoor" {
+#   endpoint          = "https://casdoor.example.com"
+#   organization_name = "built-in"
+#   access_key        = var.casdoor_access_key
+#   access_secret     = var.casdoor_access_secret
+# }
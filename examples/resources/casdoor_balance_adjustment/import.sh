@@ -0,0 +1,3 @@
+# Balance adjustments can be imported using the owner/transaction format,
+# where transaction is the name Casdoor gave the transaction
+terraform import casdoor_balance_adjustment.welcome_credit my-organization/transaction_a1b2c3
@@ -0,0 +1,19 @@
+# Credit a user's balance
+resource "casdoor_balance_adjustment" "welcome_credit" {
+  owner    = "my-organization"
+  name     = "welcome-credit"
+  user     = "alice"
+  amount   = 10
+  currency = "USD"
+  reason   = "Welcome credit"
+}
+
+# Debit it again; a recorded adjustment is corrected by another one
+resource "casdoor_balance_adjustment" "welcome_credit_reversal" {
+  owner    = "my-organization"
+  name     = "welcome-credit-reversal"
+  user     = "alice"
+  amount   = -10
+  currency = "USD"
+  reason   = "Welcome credit granted twice"
+}
@@ -0,0 +1,2 @@
+# Invitations can be imported using the owner/name format
+terraform import casdoor_invitation.partner my-organization/partner-onboarding
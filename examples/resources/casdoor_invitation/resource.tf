@@ -0,0 +1,22 @@
+# Invitation with a generated code for up to 20 signups
+resource "casdoor_invitation" "partner" {
+  owner        = "my-organization"
+  name         = "partner-onboarding"
+  display_name = "Partner Onboarding"
+  quota        = 20
+  application  = "my-app"
+  signup_group = "my-organization/partners"
+}
+
+# Invitation for a single email address
+resource "casdoor_invitation" "contractor" {
+  owner = "my-organization"
+  name  = "contractor-jane"
+  code  = "welcome-jane"
+  email = "jane@example.com"
+}
+
+output "partner_invitation_code" {
+  value     = casdoor_invitation.partner.code
+  sensitive = true
+}
@@ -1,2 +1,2 @@
-# Applications can be imported by their name
+# LDAP configurations can be imported by their ID, or by "owner/id" if not owned by admin
 terraform import casdoor_ldap.basic ldap-basic
@@ -25,6 +25,9 @@ resource "casdoor_organization" "full" {
   # User defaults
   default_avatar = "https://cdn.casbin.org/img/casbin.svg"
 
+  # Refuse to destroy the organization by mistake
+  deletion_protection = true
+
   # Feature flags
   enable_soft_deletion = false
   is_profile_public    = true
@@ -338,6 +341,18 @@ resource "casdoor_organization" "full" {
   ]
 }
 
+# Organization whose users, groups, applications and other objects are
+# deleted along with it
+resource "casdoor_organization" "ephemeral" {
+  name          = "ephemeral-org"
+  display_name  = "Ephemeral Organization"
+  delete_policy = "cascade"
+
+  timeouts {
+    delete = "30m"
+  }
+}
+
 # Organization with theme customization
 resource "casdoor_organization" "themed" {
   name         = "themed-org"
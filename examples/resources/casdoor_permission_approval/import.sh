@@ -0,0 +1,2 @@
+# Approvals can be imported using the owner/name of the permission
+terraform import casdoor_permission_approval.deploy my-organization/deploy
@@ -0,0 +1,18 @@
+# A permission submitted for approval is created in the "Pending" state.
+resource "casdoor_permission" "deploy" {
+  owner        = "my-organization"
+  name         = "deploy"
+  display_name = "Deploy"
+  submitter    = "my-organization/alice"
+
+  roles     = ["my-organization/developers"]
+  resources = ["deployments/*"]
+  actions   = ["Write"]
+}
+
+# Approve it, recording the approver and approval time on the permission.
+resource "casdoor_permission_approval" "deploy" {
+  permission_id = casdoor_permission.deploy.id
+  approver      = "my-organization/bob"
+  decision      = "Approved"
+}
@@ -0,0 +1,2 @@
+# Subscriptions can be imported using the owner/name format
+terraform import casdoor_subscription.acme my-organization/acme-premium
@@ -0,0 +1,12 @@
+# Contract subscription assigned to a B2B customer
+resource "casdoor_subscription" "acme" {
+  owner        = "my-organization"
+  name         = "acme-premium"
+  display_name = "ACME Premium Contract"
+  user         = "acme-admin"
+  pricing      = "pricing-saas"
+  plan         = "plan-premium"
+  start_time   = "2026-01-01T00:00:00Z"
+  end_time     = "2027-01-01T00:00:00Z"
+  period       = "Yearly"
+}
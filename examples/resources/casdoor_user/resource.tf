@@ -24,3 +24,21 @@ resource "casdoor_user" "admin" {
 
   groups = ["admins", "developers"]
 }
+
+# User whose profile is partly managed by the user themselves
+resource "casdoor_user" "self_service" {
+  owner        = "my-organization"
+  name         = "jane.doe"
+  display_name = "Jane Doe"
+  email        = "jane.doe@example.com"
+  update_mode  = "partial"
+}
+
+# User in an organization with enable_soft_deletion, brought back with its
+# history if it was deleted before
+resource "casdoor_user" "returning" {
+  owner                = "my-organization"
+  name                 = "john.doe"
+  display_name         = "John Doe"
+  restore_soft_deleted = true
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
@@ -22,28 +23,32 @@ var (
 	_ resource.Resource                = &AdapterResource{}
 	_ resource.ResourceWithConfigure   = &AdapterResource{}
 	_ resource.ResourceWithImportState = &AdapterResource{}
+	_ resource.ResourceWithModifyPlan  = &AdapterResource{}
 )
 
 type AdapterResource struct {
-	client *casdoorsdk.Client
+	client       casdoorClient
+	providerData *CasdoorProviderData
 }
 
 type AdapterResourceModel struct {
-	ID              types.String `tfsdk:"id"`
-	Owner           types.String `tfsdk:"owner"`
-	Name            types.String `tfsdk:"name"`
-	CreatedTime     types.String `tfsdk:"created_time"`
-	UseSameDb       types.Bool   `tfsdk:"use_same_db"`
-	Type            types.String `tfsdk:"type"`
-	DatabaseType    types.String `tfsdk:"database_type"`
-	Host            types.String `tfsdk:"host"`
-	Port            types.Int64  `tfsdk:"port"`
-	User            types.String `tfsdk:"user"`
-	Password        types.String `tfsdk:"password"`
-	Database        types.String `tfsdk:"database"`
-	Table           types.String `tfsdk:"table"`
-	TableNamePrefix types.String `tfsdk:"table_name_prefix"`
-	IsEnabled       types.Bool   `tfsdk:"is_enabled"`
+	ID              types.String   `tfsdk:"id"`
+	Owner           types.String   `tfsdk:"owner"`
+	Name            types.String   `tfsdk:"name"`
+	CreatedTime     types.String   `tfsdk:"created_time"`
+	UseSameDb       types.Bool     `tfsdk:"use_same_db"`
+	Type            types.String   `tfsdk:"type"`
+	DatabaseType    types.String   `tfsdk:"database_type"`
+	Host            types.String   `tfsdk:"host"`
+	Port            types.Int64    `tfsdk:"port"`
+	User            types.String   `tfsdk:"user"`
+	Password        types.String   `tfsdk:"password"`
+	Database        types.String   `tfsdk:"database"`
+	Table           types.String   `tfsdk:"table"`
+	TableNamePrefix types.String   `tfsdk:"table_name_prefix"`
+	IsEnabled       types.Bool     `tfsdk:"is_enabled"`
+	AdoptExisting   types.Bool     `tfsdk:"adopt_existing"`
+	Timeouts        timeouts.Value `tfsdk:"timeouts"`
 }
 
 func NewAdapterResource() resource.Resource {
@@ -54,7 +59,7 @@ func (r *AdapterResource) Metadata(_ context.Context, req resource.MetadataReque
 	resp.TypeName = req.ProviderTypeName + "_adapter"
 }
 
-func (r *AdapterResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+func (r *AdapterResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		Description: "Manages a Casdoor Casbin adapter for policy storage.",
 		Attributes: map[string]schema.Attribute{
@@ -65,13 +70,7 @@ func (r *AdapterResource) Schema(_ context.Context, _ resource.SchemaRequest, re
 					stringplanmodifier.UseStateForUnknown(),
 				},
 			},
-			"owner": schema.StringAttribute{
-				Description: "The organization that owns this adapter.",
-				Required:    true,
-				PlanModifiers: []planmodifier.String{
-					stringplanmodifier.RequiresReplace(),
-				},
-			},
+			"owner": ownerAttribute("adapter"),
 			"name": schema.StringAttribute{
 				Description: "The unique name of the adapter.",
 				Required:    true,
@@ -153,6 +152,15 @@ func (r *AdapterResource) Schema(_ context.Context, _ resource.SchemaRequest, re
 				Computed:    true,
 				Default:     booldefault.StaticBool(false),
 			},
+			"adopt_existing": adoptExistingAttribute("adapter"),
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Read:   true,
+				Update: true,
+				Delete: true,
+			}),
 		},
 	}
 }
@@ -162,16 +170,26 @@ func (r *AdapterResource) Configure(_ context.Context, req resource.ConfigureReq
 		return
 	}
 
-	client, ok := req.ProviderData.(*casdoorsdk.Client)
+	data, ok := req.ProviderData.(*CasdoorProviderData)
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Resource Configure Type",
-			fmt.Sprintf("Expected *casdoorsdk.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected *CasdoorProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 		return
 	}
 
-	r.client = client
+	r.client = data.Client
+	r.providerData = data
+}
+
+func (r *AdapterResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	planDefaultOwner(ctx, req, resp, r.providerData)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	planAdoption(ctx, req, resp, r.providerData, "adapter", r.Read)
 }
 
 func adapterPlanToSDK(plan AdapterResourceModel, createdTime string) *casdoorsdk.Adapter {
@@ -194,6 +212,10 @@ func adapterPlanToSDK(plan AdapterResourceModel, createdTime string) *casdoorsdk
 }
 
 func (r *AdapterResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	if checkUnconfigured(r.client, &resp.Diagnostics) {
+		return
+	}
+
 	var plan AdapterResourceModel
 
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
@@ -201,6 +223,12 @@ func (r *AdapterResource) Create(ctx context.Context, req resource.CreateRequest
 		return
 	}
 
+	ctx, done := startOperation(ctx, plan.Timeouts.Create, defaultOperationTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	createdTime := plan.CreatedTime.ValueString()
 	if createdTime == "" {
 		createdTime = time.Now().UTC().Format(time.RFC3339)
@@ -208,13 +236,20 @@ func (r *AdapterResource) Create(ctx context.Context, req resource.CreateRequest
 
 	adapter := adapterPlanToSDK(plan, createdTime)
 
-	ok, err := r.client.AddAdapter(adapter)
-	if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("creating adapter %q", plan.Name.ValueString())) {
+	adopted := adoptOnCreate(ctx, r.providerData, req.Plan, req.Config, &resp.Diagnostics, "adapter", plan.Owner.ValueString()+"/"+plan.Name.ValueString(), adapter, r.client.Adapters())
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
+	if !adopted {
+		ok, err := r.client.Adapters().Add(ctx, adapter)
+		if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("creating adapter %q", plan.Name.ValueString())) {
+			return
+		}
+	}
+
 	// Read back the adapter to get server-generated values like CreatedTime.
-	createdAdapter, err := r.client.GetAdapter(plan.Owner.ValueString() + "/" + plan.Name.ValueString())
+	createdAdapter, err := r.client.Adapters().Get(ctx, plan.Owner.ValueString()+"/"+plan.Name.ValueString())
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Reading Adapter",
@@ -232,6 +267,10 @@ func (r *AdapterResource) Create(ctx context.Context, req resource.CreateRequest
 }
 
 func (r *AdapterResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	if r.client == nil {
+		return
+	}
+
 	var state AdapterResourceModel
 
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
@@ -239,7 +278,13 @@ func (r *AdapterResource) Read(ctx context.Context, req resource.ReadRequest, re
 		return
 	}
 
-	adapter, err := r.client.GetAdapter(state.Owner.ValueString() + "/" + state.Name.ValueString())
+	ctx, done := startOperation(ctx, state.Timeouts.Read, defaultReadTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	adapter, err := r.client.Adapters().Get(ctx, state.Owner.ValueString()+"/"+state.Name.ValueString())
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Reading Adapter",
@@ -269,29 +314,50 @@ func (r *AdapterResource) Read(ctx context.Context, req resource.ReadRequest, re
 	state.TableNamePrefix = types.StringValue(adapter.TableNamePrefix)
 	state.IsEnabled = types.BoolValue(adapter.IsEnabled)
 
+	resp.Diagnostics.Append(setObjectFingerprint(ctx, resp.Private, adapter)...)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
 }
 
 func (r *AdapterResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	var plan AdapterResourceModel
+	if checkUnconfigured(r.client, &resp.Diagnostics) {
+		return
+	}
+
+	var plan, state AdapterResourceModel
 
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, done := startOperation(ctx, plan.Timeouts.Update, defaultOperationTimeout, &resp.Diagnostics)
+	defer done()
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
+	if checkUpdateConflict(ctx, r.providerData, &resp.Diagnostics, req.Private, "adapter", state.Owner.ValueString()+"/"+state.Name.ValueString(), r.client.Adapters().Get) {
+		return
+	}
+
 	adapter := adapterPlanToSDK(plan, plan.CreatedTime.ValueString())
 
-	ok, err := r.client.UpdateAdapter(adapter)
+	ok, err := r.client.Adapters().Update(ctx, adapter)
 	if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("updating adapter %q", plan.Name.ValueString())) {
 		return
 	}
 
 	plan.ID = types.StringValue(plan.Owner.ValueString() + "/" + plan.Name.ValueString())
+	resp.Diagnostics.Append(clearObjectFingerprint(ctx, resp.Private)...)
 	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
 }
 
 func (r *AdapterResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	if checkUnconfigured(r.client, &resp.Diagnostics) {
+		return
+	}
+
 	var state AdapterResourceModel
 
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
@@ -299,12 +365,18 @@ func (r *AdapterResource) Delete(ctx context.Context, req resource.DeleteRequest
 		return
 	}
 
+	ctx, done := startOperation(ctx, state.Timeouts.Delete, defaultOperationTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	adapter := &casdoorsdk.Adapter{
 		Owner: state.Owner.ValueString(),
 		Name:  state.Name.ValueString(),
 	}
 
-	ok, err := r.client.DeleteAdapter(adapter)
+	ok, err := r.client.Adapters().Delete(ctx, adapter)
 	if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("deleting adapter %q", state.Name.ValueString())) {
 		return
 	}
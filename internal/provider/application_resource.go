@@ -8,8 +8,10 @@ import (
 	"time"
 
 	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
 	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
@@ -29,11 +31,13 @@ var (
 	_ resource.Resource                = &ApplicationResource{}
 	_ resource.ResourceWithConfigure   = &ApplicationResource{}
 	_ resource.ResourceWithImportState = &ApplicationResource{}
+	_ resource.ResourceWithModifyPlan  = &ApplicationResource{}
 )
 
 // ApplicationResource defines the resource implementation.
 type ApplicationResource struct {
-	client *casdoorsdk.Client
+	client       casdoorClient
+	providerData *CasdoorProviderData
 }
 
 // ProviderItemModel represents a provider item configuration.
@@ -282,12 +286,15 @@ type ApplicationResourceModel struct {
 	SigninItems   types.List `tfsdk:"signin_items"`
 
 	// Scopes and reverse proxy
-	Scopes       types.List   `tfsdk:"scopes"`
-	Domain       types.String `tfsdk:"domain"`
-	OtherDomains types.List   `tfsdk:"other_domains"`
-	UpstreamHost types.String `tfsdk:"upstream_host"`
-	SslMode      types.String `tfsdk:"ssl_mode"`
-	SslCert      types.String `tfsdk:"ssl_cert"`
+	Scopes             types.List     `tfsdk:"scopes"`
+	Domain             types.String   `tfsdk:"domain"`
+	OtherDomains       types.List     `tfsdk:"other_domains"`
+	UpstreamHost       types.String   `tfsdk:"upstream_host"`
+	SslMode            types.String   `tfsdk:"ssl_mode"`
+	SslCert            types.String   `tfsdk:"ssl_cert"`
+	AdoptExisting      types.Bool     `tfsdk:"adopt_existing"`
+	DeletionProtection types.Bool     `tfsdk:"deletion_protection"`
+	Timeouts           timeouts.Value `tfsdk:"timeouts"`
 }
 
 // NewApplicationResource creates a new Application resource.
@@ -299,7 +306,7 @@ func (r *ApplicationResource) Metadata(_ context.Context, req resource.MetadataR
 	resp.TypeName = req.ProviderTypeName + "_application"
 }
 
-func (r *ApplicationResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+func (r *ApplicationResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		Description: "Manages a Casdoor application.",
 		Attributes: map[string]schema.Attribute{
@@ -307,25 +314,14 @@ func (r *ApplicationResource) Schema(_ context.Context, _ resource.SchemaRequest
 				Description: "The ID of the application in the format 'owner/name'.",
 				Computed:    true,
 				PlanModifiers: []planmodifier.String{
-					stringplanmodifier.UseStateForUnknown(),
+					useStateUnlessChanged(path.Root("name")),
 				},
 			},
 			// Core fields
-			"owner": schema.StringAttribute{
-				Description: "The owner of the application. Defaults to 'admin'.",
-				Optional:    true,
-				Computed:    true,
-				Default:     stringdefault.StaticString("admin"),
-				PlanModifiers: []planmodifier.String{
-					stringplanmodifier.RequiresReplace(),
-				},
-			},
+			"owner": adminOwnerAttribute("application"),
 			"name": schema.StringAttribute{
-				Description: "The unique name of the application.",
+				Description: "The unique name of the application. Changing it renames the application in place.",
 				Required:    true,
-				PlanModifiers: []planmodifier.String{
-					stringplanmodifier.RequiresReplace(),
-				},
 			},
 			"created_time": schema.StringAttribute{
 				Description: "The time when the application was created.",
@@ -1059,6 +1055,16 @@ func (r *ApplicationResource) Schema(_ context.Context, _ resource.SchemaRequest
 				Computed:    true,
 				Default:     stringdefault.StaticString(""),
 			},
+			"adopt_existing":      adoptExistingAttribute("application"),
+			"deletion_protection": deletionProtectionAttribute("application"),
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Read:   true,
+				Update: true,
+				Delete: true,
+			}),
 		},
 	}
 }
@@ -1068,16 +1074,21 @@ func (r *ApplicationResource) Configure(_ context.Context, req resource.Configur
 		return
 	}
 
-	client, ok := req.ProviderData.(*casdoorsdk.Client)
+	data, ok := req.ProviderData.(*CasdoorProviderData)
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Resource Configure Type",
-			fmt.Sprintf("Expected *casdoorsdk.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected *CasdoorProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 		return
 	}
 
-	r.client = client
+	r.client = data.Client
+	r.providerData = data
+}
+
+func (r *ApplicationResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	planAdoption(ctx, req, resp, r.providerData, "application", r.Read)
 }
 
 // applicationPlanToSDK converts an ApplicationResourceModel into a casdoorsdk.Application.
@@ -1358,6 +1369,10 @@ func applicationPlanToSDK(ctx context.Context, plan ApplicationResourceModel, cr
 }
 
 func (r *ApplicationResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	if checkUnconfigured(r.client, &resp.Diagnostics) {
+		return
+	}
+
 	var plan ApplicationResourceModel
 
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
@@ -1365,6 +1380,12 @@ func (r *ApplicationResource) Create(ctx context.Context, req resource.CreateReq
 		return
 	}
 
+	ctx, done := startOperation(ctx, plan.Timeouts.Create, defaultOperationTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	createdTime := plan.CreatedTime.ValueString()
 	if createdTime == "" {
 		createdTime = time.Now().UTC().Format(time.RFC3339)
@@ -1376,13 +1397,20 @@ func (r *ApplicationResource) Create(ctx context.Context, req resource.CreateReq
 		return
 	}
 
-	ok, err := r.client.AddApplication(app)
-	if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("creating application %q", plan.Name.ValueString())) {
+	adopted := adoptOnCreate(ctx, r.providerData, req.Plan, req.Config, &resp.Diagnostics, "application", plan.Owner.ValueString()+"/"+plan.Name.ValueString(), app, r.client.Applications())
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
+	if !adopted {
+		ok, err := r.client.Applications().Add(ctx, app)
+		if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("creating application %q", plan.Name.ValueString())) {
+			return
+		}
+	}
+
 	// Read back the created application to get computed fields.
-	createdApp, err := r.client.GetApplication(plan.Owner.ValueString() + "/" + plan.Name.ValueString())
+	createdApp, err := r.client.Applications().Get(ctx, plan.Owner.ValueString()+"/"+plan.Name.ValueString())
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Reading Application",
@@ -1647,6 +1675,10 @@ func (r *ApplicationResource) Create(ctx context.Context, req resource.CreateReq
 }
 
 func (r *ApplicationResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	if r.client == nil {
+		return
+	}
+
 	var state ApplicationResourceModel
 
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
@@ -1654,7 +1686,13 @@ func (r *ApplicationResource) Read(ctx context.Context, req resource.ReadRequest
 		return
 	}
 
-	app, err := r.client.GetApplication(state.Owner.ValueString() + "/" + state.Name.ValueString())
+	ctx, done := startOperation(ctx, state.Timeouts.Read, defaultReadTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	app, err := r.client.Applications().Get(ctx, state.Owner.ValueString()+"/"+state.Name.ValueString())
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Reading Application",
@@ -1948,10 +1986,19 @@ func (r *ApplicationResource) Read(ctx context.Context, req resource.ReadRequest
 		return
 	}
 
+	if state.DeletionProtection.IsNull() {
+		state.DeletionProtection = types.BoolValue(false)
+	}
+
+	resp.Diagnostics.Append(setObjectFingerprint(ctx, resp.Private, app)...)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
 }
 
 func (r *ApplicationResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	if checkUnconfigured(r.client, &resp.Diagnostics) {
+		return
+	}
+
 	var plan ApplicationResourceModel
 
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
@@ -1959,6 +2006,12 @@ func (r *ApplicationResource) Update(ctx context.Context, req resource.UpdateReq
 		return
 	}
 
+	ctx, done := startOperation(ctx, plan.Timeouts.Update, defaultOperationTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	// Preserve computed fields from state.
 	var state ApplicationResourceModel
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
@@ -1966,14 +2019,21 @@ func (r *ApplicationResource) Update(ctx context.Context, req resource.UpdateReq
 		return
 	}
 
+	if checkBuiltInModify(r.providerData, &resp.Diagnostics, "application", state.Owner.ValueString()+"/"+state.Name.ValueString()) {
+		return
+	}
+
+	if checkUpdateConflict(ctx, r.providerData, &resp.Diagnostics, req.Private, "application", state.Owner.ValueString()+"/"+state.Name.ValueString(), r.client.Applications().Get) {
+		return
+	}
+
 	app, diags := applicationPlanToSDK(ctx, plan, state.CreatedTime.ValueString(), state.ClientID.ValueString(), state.ClientSecret.ValueString())
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	ok, err := r.client.UpdateApplication(app)
-	if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("updating application %q", plan.Name.ValueString())) {
+	if renameUpdate(ctx, resp, r.client, "application", plan.Owner.ValueString(), state.Name.ValueString(), plan.Name.ValueString(), app) {
 		return
 	}
 
@@ -2022,10 +2082,15 @@ func (r *ApplicationResource) Update(ctx context.Context, req resource.UpdateReq
 	}
 
 	plan.ID = types.StringValue(plan.Owner.ValueString() + "/" + plan.Name.ValueString())
+	resp.Diagnostics.Append(clearObjectFingerprint(ctx, resp.Private)...)
 	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
 }
 
 func (r *ApplicationResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	if checkUnconfigured(r.client, &resp.Diagnostics) {
+		return
+	}
+
 	var state ApplicationResourceModel
 
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
@@ -2033,6 +2098,18 @@ func (r *ApplicationResource) Delete(ctx context.Context, req resource.DeleteReq
 		return
 	}
 
+	ctx, done := startOperation(ctx, state.Timeouts.Delete, defaultOperationTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	id := state.Owner.ValueString() + "/" + state.Name.ValueString()
+	if checkDeletionProtection(&resp.Diagnostics, state.DeletionProtection, "application", id) ||
+		checkBuiltInDelete(r.providerData, &resp.Diagnostics, "application", id) {
+		return
+	}
+
 	// Include organization field as the Casdoor API requires it for deletion.
 	app := &casdoorsdk.Application{
 		Owner:        state.Owner.ValueString(),
@@ -2040,7 +2117,7 @@ func (r *ApplicationResource) Delete(ctx context.Context, req resource.DeleteReq
 		Organization: state.Organization.ValueString(),
 	}
 
-	ok, err := r.client.DeleteApplication(app)
+	ok, err := r.client.Applications().Delete(ctx, app)
 	if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("deleting application %q", state.Name.ValueString())) {
 		return
 	}
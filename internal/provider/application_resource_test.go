@@ -7,8 +7,12 @@ import (
 	"testing"
 
 	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+	"github.com/hashicorp/terraform-plugin-testing/compare"
 	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
 )
 
 // TestCasdoorSDKApplicationClient tests the Casdoor SDK client directly for applications.
@@ -148,6 +152,45 @@ func TestAccApplicationResource_basic(t *testing.T) {
 	})
 }
 
+func TestAccApplicationResource_rename(t *testing.T) {
+	config := setupTestConfig(t)
+	rName := "tf-test-" + acctest.RandStringFromCharSet(8, acctest.CharSetAlphaNum)
+	resourceName := "casdoor_application.test"
+	sameClientID := statecheck.CompareValue(compare.ValuesSame())
+	sameClientSecret := statecheck.CompareValue(compare.ValuesSame())
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories(config),
+		Steps: []resource.TestStep{
+			// Create the application.
+			{
+				Config: testAccProviderConfig(config) + testAccApplicationResourceConfig(rName, config.OrganizationName, "Test Application"),
+				ConfigStateChecks: []statecheck.StateCheck{
+					sameClientID.AddStateValue(resourceName, tfjsonpath.New("client_id")),
+					sameClientSecret.AddStateValue(resourceName, tfjsonpath.New("client_secret")),
+				},
+			},
+			// Rename in place; the credentials must survive.
+			{
+				Config: testAccProviderConfig(config) + testAccApplicationResourceConfig(rName+"-renamed", config.OrganizationName, "Test Application"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction(resourceName, plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "name", rName+"-renamed"),
+					resource.TestCheckResourceAttr(resourceName, "id", "admin/"+rName+"-renamed"),
+				),
+				ConfigStateChecks: []statecheck.StateCheck{
+					sameClientID.AddStateValue(resourceName, tfjsonpath.New("client_id")),
+					sameClientSecret.AddStateValue(resourceName, tfjsonpath.New("client_secret")),
+				},
+			},
+		},
+	})
+}
+
 func TestAccApplicationResource_import(t *testing.T) {
 	config := setupTestConfig(t)
 	rName := "tf-test-" + acctest.RandStringFromCharSet(8, acctest.CharSetAlphaNum)
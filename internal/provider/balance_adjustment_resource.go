@@ -0,0 +1,413 @@
+// Copyright (c) HashiCorp, Inc.
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// balanceAdjustmentCategory is the category of the transactions recording
+// balance adjustments. Their type is the name of the adjustment.
+const balanceAdjustmentCategory = "Adjustment"
+
+var (
+	_ resource.Resource                = &BalanceAdjustmentResource{}
+	_ resource.ResourceWithConfigure   = &BalanceAdjustmentResource{}
+	_ resource.ResourceWithImportState = &BalanceAdjustmentResource{}
+	_ resource.ResourceWithModifyPlan  = &BalanceAdjustmentResource{}
+)
+
+type BalanceAdjustmentResource struct {
+	client       casdoorClient
+	providerData *CasdoorProviderData
+}
+
+type BalanceAdjustmentResourceModel struct {
+	ID          types.String   `tfsdk:"id"`
+	Owner       types.String   `tfsdk:"owner"`
+	Name        types.String   `tfsdk:"name"`
+	User        types.String   `tfsdk:"user"`
+	Amount      types.Float64  `tfsdk:"amount"`
+	Currency    types.String   `tfsdk:"currency"`
+	Reason      types.String   `tfsdk:"reason"`
+	Transaction types.String   `tfsdk:"transaction"`
+	CreatedTime types.String   `tfsdk:"created_time"`
+	Timeouts    timeouts.Value `tfsdk:"timeouts"`
+}
+
+func NewBalanceAdjustmentResource() resource.Resource {
+	return &BalanceAdjustmentResource{}
+}
+
+func (r *BalanceAdjustmentResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_balance_adjustment"
+}
+
+func (r *BalanceAdjustmentResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Credits or debits the balance of a Casdoor user by recording a transaction. " +
+			"An adjustment is recorded once and cannot be changed; destroying it leaves the transaction and the balance as they are.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "The ID of the transaction in the format 'owner/transaction'.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"owner": schema.StringAttribute{
+				Description: "The organization that owns this balance adjustment. Defaults to the provider's default_owner. " +
+					"It cannot be changed once the adjustment is recorded.",
+				Optional: true,
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"name": schema.StringAttribute{
+				Description: "The name of the adjustment, unique among the adjustments of the user. " +
+					"It is recorded with the transaction, so that the adjustment is never applied twice. " +
+					"It cannot be changed once the adjustment is recorded.",
+				Required: true,
+			},
+			"user": schema.StringAttribute{
+				Description: "The name of the user whose balance is adjusted. It cannot be changed once the adjustment is recorded.",
+				Required:    true,
+			},
+			"amount": schema.Float64Attribute{
+				Description: "The amount added to the balance; negative for a debit. It cannot be changed once the adjustment is recorded; " +
+					"record a correction as another adjustment.",
+				Required: true,
+			},
+			"currency": schema.StringAttribute{
+				Description: "The currency of the amount (e.g., 'USD'). It cannot be changed once the adjustment is recorded.",
+				Required:    true,
+			},
+			"reason": schema.StringAttribute{
+				Description: "Why the balance is adjusted. It is recorded as the display name of the transaction, " +
+					"and cannot be changed once the adjustment is recorded.",
+				Optional: true,
+				Computed: true,
+				Default:  stringdefault.StaticString(""),
+			},
+			"transaction": schema.StringAttribute{
+				Description: "The name Casdoor gave the transaction.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"created_time": schema.StringAttribute{
+				Description: "The time when the transaction was recorded.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Read:   true,
+				Delete: true,
+			}),
+		},
+	}
+}
+
+func (r *BalanceAdjustmentResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*CasdoorProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *CasdoorProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.client = data.Client
+	r.providerData = data
+}
+
+// ModifyPlan refuses a zero amount, and any change to a recorded adjustment:
+// the transaction cannot be changed, and replacing the adjustment would record
+// a second transaction while the first one stays, applying it twice.
+func (r *BalanceAdjustmentResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	planOwner(ctx, req, resp, r.providerData)
+	if resp.Diagnostics.HasError() || req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan BalanceAdjustmentResourceModel
+	resp.Diagnostics.Append(resp.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !plan.Amount.IsUnknown() && plan.Amount.ValueFloat64() == 0 {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("amount"),
+			"Invalid Amount",
+			"amount must not be zero: a positive amount credits the balance, a negative one debits it.",
+		)
+		return
+	}
+
+	if req.State.Raw.IsNull() {
+		return
+	}
+
+	var state BalanceAdjustmentResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !(plan.Owner.Equal(state.Owner) && plan.Name.Equal(state.Name) &&
+		plan.User.Equal(state.User) && plan.Amount.Equal(state.Amount) &&
+		plan.Currency.Equal(state.Currency) && plan.Reason.Equal(state.Reason)) {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("name"),
+			"Balance Adjustment Cannot Change",
+			fmt.Sprintf("The balance adjustment %q is recorded as transaction %q and cannot be changed. "+
+				"Record the correction as another adjustment with a new name, e.g. with the opposite amount, "+
+				"or remove the adjustment from the configuration to stop managing it.",
+				state.Name.ValueString(), state.Transaction.ValueString()),
+		)
+	}
+}
+
+// findBalanceAdjustment returns the transaction recording the adjustment
+// with the given name for the user, or nil if there is none.
+func (r *BalanceAdjustmentResource) findBalanceAdjustment(ctx context.Context, owner, user, name string) (*casdoorsdk.Transaction, error) {
+	transactions, err := r.client.ListTransactions(ctx, owner, user, defaultBillingPageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, transaction := range transactions {
+		if transaction.User == user && transaction.Category == balanceAdjustmentCategory && transaction.Type == name {
+			return transaction, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (r *BalanceAdjustmentResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	if checkUnconfigured(r.client, &resp.Diagnostics) {
+		return
+	}
+
+	var plan BalanceAdjustmentResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, done := startOperation(ctx, plan.Timeouts.Create, defaultOperationTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	owner := plan.Owner.ValueString()
+
+	// A transaction already recording the adjustment, e.g. by an apply whose
+	// result was lost, is taken over instead of applying the amount again.
+	existing, err := r.findBalanceAdjustment(ctx, owner, plan.User.ValueString(), plan.Name.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Checking for Existing Object",
+			fmt.Sprintf("Could not list the transactions of user %q: %s", owner+"/"+plan.User.ValueString(), err),
+		)
+		return
+	}
+
+	var name string
+	if existing != nil {
+		if existing.Amount != plan.Amount.ValueFloat64() || existing.Currency != plan.Currency.ValueString() ||
+			existing.DisplayName != plan.Reason.ValueString() {
+			resp.Diagnostics.AddError(
+				"Balance Adjustment Exists",
+				fmt.Sprintf("Transaction %q already records the balance adjustment %q of user %q, for %v %s with reason %q. "+
+					"Give the adjustment another name.",
+					existing.Name, plan.Name.ValueString(), plan.User.ValueString(), existing.Amount, existing.Currency, existing.DisplayName),
+			)
+			return
+		}
+
+		tflog.Info(ctx, "Balance adjustment is already recorded", map[string]any{"transaction": owner + "/" + existing.Name})
+		name = existing.Name
+	} else {
+		transaction := &casdoorsdk.Transaction{
+			Owner:       owner,
+			CreatedTime: time.Now().UTC().Format(time.RFC3339),
+			DisplayName: plan.Reason.ValueString(),
+			Category:    balanceAdjustmentCategory,
+			Type:        plan.Name.ValueString(),
+			User:        plan.User.ValueString(),
+			Tag:         "User",
+			Amount:      plan.Amount.ValueFloat64(),
+			Currency:    plan.Currency.ValueString(),
+			State:       "Paid",
+		}
+
+		name, err = r.client.AddTransaction(ctx, transaction)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error Creating Balance Adjustment",
+				fmt.Sprintf("Could not record balance adjustment %q: %s", plan.Name.ValueString(), err),
+			)
+			return
+		}
+	}
+
+	// Read back the transaction to get the time Casdoor recorded it at.
+	transaction, err := r.client.Transactions().Get(ctx, owner+"/"+name)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading Balance Adjustment",
+			fmt.Sprintf("Could not read transaction %q after recording balance adjustment %q: %s", name, plan.Name.ValueString(), err),
+		)
+		return
+	}
+
+	if transaction == nil {
+		resp.Diagnostics.AddError(
+			"Error Reading Balance Adjustment",
+			fmt.Sprintf("Transaction %q not found after recording balance adjustment %q", owner+"/"+name, plan.Name.ValueString()),
+		)
+		return
+	}
+
+	plan.ID = types.StringValue(owner + "/" + name)
+	plan.Transaction = types.StringValue(name)
+	plan.CreatedTime = types.StringValue(transaction.CreatedTime)
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+}
+
+func (r *BalanceAdjustmentResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	if r.client == nil {
+		return
+	}
+
+	var state BalanceAdjustmentResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, done := startOperation(ctx, state.Timeouts.Read, defaultReadTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	id := state.Owner.ValueString() + "/" + state.Transaction.ValueString()
+
+	transaction, err := r.client.Transactions().Get(ctx, id)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading Balance Adjustment",
+			fmt.Sprintf("Could not read transaction %q: %s", id, err),
+		)
+		return
+	}
+
+	if transaction == nil {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	if transaction.Category != balanceAdjustmentCategory {
+		resp.Diagnostics.AddError(
+			"Not a Balance Adjustment",
+			fmt.Sprintf("Transaction %q has category %q, not %q, so it does not record a balance adjustment.",
+				id, transaction.Category, balanceAdjustmentCategory),
+		)
+		return
+	}
+
+	state.ID = types.StringValue(transaction.Owner + "/" + transaction.Name)
+	state.Owner = types.StringValue(transaction.Owner)
+	state.Name = types.StringValue(transaction.Type)
+	state.User = types.StringValue(transaction.User)
+	state.Amount = types.Float64Value(transaction.Amount)
+	state.Currency = types.StringValue(transaction.Currency)
+	state.Reason = types.StringValue(transaction.DisplayName)
+	state.Transaction = types.StringValue(transaction.Name)
+	state.CreatedTime = types.StringValue(transaction.CreatedTime)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// Update only ever sees changes to the timeouts block, as ModifyPlan refuses
+// a change to any other attribute.
+func (r *BalanceAdjustmentResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	if checkUnconfigured(r.client, &resp.Diagnostics) {
+		return
+	}
+
+	var plan BalanceAdjustmentResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+}
+
+// Delete only removes the adjustment from state. The transaction stays in
+// Casdoor's ledger and the balance is left as it is; an adjustment is undone
+// by recording another with the opposite amount.
+func (r *BalanceAdjustmentResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	if checkUnconfigured(r.client, &resp.Diagnostics) {
+		return
+	}
+
+	var state BalanceAdjustmentResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Info(ctx, "Balance adjustment removed from state, its transaction is kept", map[string]any{"transaction": state.ID.ValueString()})
+}
+
+func (r *BalanceAdjustmentResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	owner, transaction, ok := strings.Cut(req.ID, "/")
+	if !ok || owner == "" || transaction == "" {
+		resp.Diagnostics.AddError(
+			"Invalid Import ID",
+			fmt.Sprintf("Expected import ID in the format 'owner/transaction', got: %q", req.ID),
+		)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("owner"), owner)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("transaction"), transaction)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), req.ID)...)
+}
@@ -0,0 +1,157 @@
+// Copyright (c) HashiCorp, Inc.
+
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+)
+
+func TestAccBalanceAdjustmentResource_basic(t *testing.T) {
+	config := setupTestConfig(t)
+	rName := "tf-test-" + acctest.RandStringFromCharSet(8, acctest.CharSetAlphaNum)
+	resourceName := "casdoor_balance_adjustment.test"
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories(config),
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: testAccProviderConfig(config) + testAccBalanceAdjustmentResourceConfig(rName, "welcome-credit", 10),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "owner", "built-in"),
+					resource.TestCheckResourceAttr(resourceName, "name", "welcome-credit"),
+					resource.TestCheckResourceAttr(resourceName, "user", rName),
+					resource.TestCheckResourceAttr(resourceName, "amount", "10"),
+					resource.TestCheckResourceAttr(resourceName, "currency", "USD"),
+					resource.TestCheckResourceAttr(resourceName, "reason", "Welcome credit"),
+					resource.TestCheckResourceAttrSet(resourceName, "transaction"),
+					resource.TestCheckResourceAttrSet(resourceName, "created_time"),
+				),
+			},
+			// The user's balance is read from Casdoor, not planned.
+			{
+				RefreshState: true,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("casdoor_user.test", "balance", "10"),
+				),
+			},
+			// A recorded adjustment cannot be changed under the same name.
+			{
+				Config:      testAccProviderConfig(config) + testAccBalanceAdjustmentResourceConfig(rName, "welcome-credit", 20),
+				ExpectError: regexp.MustCompile("Balance Adjustment Cannot Change"),
+			},
+			// Nor renamed, as the replacement would be applied on top of it.
+			{
+				Config:      testAccProviderConfig(config) + testAccBalanceAdjustmentResourceConfig(rName, "welcome-bonus", 10),
+				ExpectError: regexp.MustCompile("Balance Adjustment Cannot Change"),
+			},
+		},
+	})
+}
+
+func TestAccBalanceAdjustmentResource_idempotent(t *testing.T) {
+	config := setupTestConfig(t)
+	rName := "tf-test-" + acctest.RandStringFromCharSet(8, acctest.CharSetAlphaNum)
+	resourceName := "casdoor_balance_adjustment.test"
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories(config),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(config) + testAccUserResourceConfig("built-in", rName, "Balance User"),
+			},
+			// The adjustment is recorded as if by an apply whose result was
+			// lost; applying it takes the transaction over.
+			{
+				PreConfig: func() { addTestBalanceAdjustment(t, config, rName, "welcome-credit", 10) },
+				Config:    testAccProviderConfig(config) + testAccBalanceAdjustmentResourceConfig(rName, "welcome-credit", 10),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrSet(resourceName, "transaction"),
+				),
+			},
+			// The amount was applied once.
+			{
+				RefreshState: true,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("casdoor_user.test", "balance", "10"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccBalanceAdjustmentResource_import(t *testing.T) {
+	config := setupTestConfig(t)
+	rName := "tf-test-" + acctest.RandStringFromCharSet(8, acctest.CharSetAlphaNum)
+	resourceName := "casdoor_balance_adjustment.test"
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories(config),
+		Steps: []resource.TestStep{
+			// Create the resource first
+			{
+				Config: testAccProviderConfig(config) + testAccBalanceAdjustmentResourceConfig(rName, "welcome-credit", -5),
+			},
+			// ImportState testing
+			{
+				Config:       testAccProviderConfig(config) + testAccBalanceAdjustmentResourceConfig(rName, "welcome-credit", -5),
+				ResourceName: resourceName,
+				ImportState:  true,
+				ImportStateIdFunc: func(s *terraform.State) (string, error) {
+					return s.RootModule().Resources[resourceName].Primary.ID, nil
+				},
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+// addTestBalanceAdjustment records the balance adjustment with the given
+// name for user, as the casdoor_balance_adjustment resource would.
+func addTestBalanceAdjustment(t *testing.T, config CasdoorTestConfig, user, name string, amount float64) {
+	t.Helper()
+
+	client := casdoorsdk.NewClient(
+		config.Endpoint,
+		config.ClientID,
+		config.ClientSecret,
+		config.Certificate,
+		config.OrganizationName,
+		config.ApplicationName,
+	)
+
+	transaction := &casdoorsdk.Transaction{
+		Owner:       "built-in",
+		DisplayName: "Welcome credit",
+		Category:    balanceAdjustmentCategory,
+		Type:        name,
+		User:        user,
+		Tag:         "User",
+		Amount:      amount,
+		Currency:    "USD",
+		State:       "Paid",
+	}
+	if _, _, err := client.AddTransaction(transaction); err != nil {
+		t.Fatalf("Failed to record balance adjustment %q: %v", name, err)
+	}
+}
+
+func testAccBalanceAdjustmentResourceConfig(user, name string, amount float64) string {
+	return testAccUserResourceConfig("built-in", user, "Balance User") + fmt.Sprintf(`
+resource "casdoor_balance_adjustment" "test" {
+  owner    = "built-in"
+  name     = %q
+  user     = casdoor_user.test.name
+  amount   = %v
+  currency = "USD"
+  reason   = "Welcome credit"
+}
+`, name, amount)
+}
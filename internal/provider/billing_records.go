@@ -0,0 +1,282 @@
+// Copyright (c) HashiCorp, Inc.
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// defaultBillingPageSize is how many payments, orders or transactions are
+// fetched per request unless page_size is set.
+const defaultBillingPageSize = 100
+
+// billingRecords describes the payments, orders or transactions listed by a
+// data source: T is the SDK type of a record and M its model.
+type billingRecords[T, M any] struct {
+	// kind is the singular and plural the plural of the records listed,
+	// e.g. "payment" and "payments". The list attribute is named plural.
+	kind   string
+	plural string
+	// description is the description of the data source.
+	description string
+	// withProduct is whether records can be filtered by product.
+	withProduct bool
+	// attributes are the attributes of a record besides its name, creation
+	// time and display name.
+	attributes map[string]schema.Attribute
+
+	// list lists the records of owner, only those of user if it is set.
+	list func(client casdoorClient, ctx context.Context, owner, user string, pageSize int) ([]*T, error)
+	// match reports whether a record passes filter.
+	match func(filter billingFilter, record *T) bool
+	// flatten converts a record to its model.
+	flatten func(ctx context.Context, record *T) (M, diag.Diagnostics)
+}
+
+// schema returns the schema of the data source listing the records.
+func (r billingRecords[T, M]) schema() schema.Schema {
+	records := map[string]schema.Attribute{
+		"name": schema.StringAttribute{
+			Description: fmt.Sprintf("The name of the %s.", r.kind),
+			Computed:    true,
+		},
+		"created_time": schema.StringAttribute{
+			Description: fmt.Sprintf("The time when the %s was created.", r.kind),
+			Computed:    true,
+		},
+		"display_name": schema.StringAttribute{
+			Description: fmt.Sprintf("The display name of the %s.", r.kind),
+			Computed:    true,
+		},
+	}
+	maps.Copy(records, r.attributes)
+
+	attributes := billingFilterAttributes(r.plural, r.withProduct)
+	attributes[r.plural] = schema.ListNestedAttribute{
+		Description: fmt.Sprintf("The %s that match the filters, oldest first.", r.plural),
+		Computed:    true,
+		NestedObject: schema.NestedAttributeObject{
+			Attributes: records,
+		},
+	}
+
+	return schema.Schema{
+		Description: r.description,
+		Attributes:  attributes,
+	}
+}
+
+// read lists the records of the configured owner that pass the filters
+// into the list attribute.
+func (r billingRecords[T, M]) read(ctx context.Context, client casdoorClient, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	if checkUnconfigured(client, &resp.Diagnostics) {
+		return
+	}
+
+	var owner, user, state, createdAfter, createdBefore types.String
+	var pageSize types.Int64
+	// Records without products have no product attribute, so the filter
+	// stays null.
+	product := types.StringNull()
+
+	for name, target := range map[string]any{
+		"owner":          &owner,
+		"user":           &user,
+		"state":          &state,
+		"created_after":  &createdAfter,
+		"created_before": &createdBefore,
+		"page_size":      &pageSize,
+	} {
+		resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root(name), target)...)
+	}
+	if r.withProduct {
+		resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("product"), &product)...)
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	filter, diags := newBillingFilter(user, product, state, createdAfter, createdBefore)
+	resp.Diagnostics.Append(diags...)
+	size, diags := billingPageSize(pageSize)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, done := startOperation(ctx, nil, defaultReadTimeout, &resp.Diagnostics)
+	defer done()
+
+	records, err := r.list(client, ctx, owner.ValueString(), filter.user, size)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading "+strings.ToUpper(r.plural[:1])+r.plural[1:],
+			fmt.Sprintf("Could not list %s of organization %q: %s", r.plural, owner.ValueString(), err),
+		)
+		return
+	}
+
+	models := []M{}
+	for _, record := range records {
+		if !r.match(filter, record) {
+			continue
+		}
+
+		model, diags := r.flatten(ctx, record)
+		resp.Diagnostics.Append(diags...)
+		models = append(models, model)
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root(r.plural), models)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), owner)...)
+}
+
+// billingFilterAttributes returns the schema attributes shared by the data
+// sources listing payments, orders and transactions: the owner, the filters
+// and the page size. records is the plural of the kind listed. The product
+// filter is left out for records without products.
+func billingFilterAttributes(records string, withProduct bool) map[string]schema.Attribute {
+	attributes := map[string]schema.Attribute{
+		"id": schema.StringAttribute{
+			Description: "The organization name.",
+			Computed:    true,
+		},
+		"owner": schema.StringAttribute{
+			Description: fmt.Sprintf("The organization whose %s are listed.", records),
+			Required:    true,
+		},
+		"user": schema.StringAttribute{
+			Description: fmt.Sprintf("Only list the %s of the user with this name.", records),
+			Optional:    true,
+		},
+		"state": schema.StringAttribute{
+			Description: fmt.Sprintf("Only list the %s in this state.", records),
+			Optional:    true,
+		},
+		"created_after": schema.StringAttribute{
+			Description: fmt.Sprintf("Only list the %s created at or after this time, in RFC 3339 format.", records),
+			Optional:    true,
+		},
+		"created_before": schema.StringAttribute{
+			Description: fmt.Sprintf("Only list the %s created before this time, in RFC 3339 format.", records),
+			Optional:    true,
+		},
+		"page_size": schema.Int64Attribute{
+			Description: fmt.Sprintf("How many %s are fetched from Casdoor per request. Defaults to %d.", records, defaultBillingPageSize),
+			Optional:    true,
+		},
+	}
+
+	if withProduct {
+		attributes["product"] = schema.StringAttribute{
+			Description: fmt.Sprintf("Only list the %s that include the product with this name.", records),
+			Optional:    true,
+		}
+	}
+
+	return attributes
+}
+
+// billingFilter selects payments, orders and transactions by the filter
+// attributes of their data source. Unset filters match every record.
+type billingFilter struct {
+	user    string
+	product string
+	state   string
+	after   time.Time
+	before  time.Time
+}
+
+// newBillingFilter parses the filter attributes. product is ignored if
+// null.
+func newBillingFilter(user, product, state, createdAfter, createdBefore types.String) (billingFilter, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	filter := billingFilter{
+		user:    user.ValueString(),
+		product: product.ValueString(),
+		state:   state.ValueString(),
+	}
+
+	parse := func(name string, value types.String) time.Time {
+		if value.ValueString() == "" {
+			return time.Time{}
+		}
+		t, err := time.Parse(time.RFC3339, value.ValueString())
+		if err != nil {
+			diags.AddAttributeError(
+				path.Root(name),
+				"Invalid Time",
+				fmt.Sprintf("Expected a time in RFC 3339 format, e.g. '2026-01-02T15:04:05Z', got: %q", value.ValueString()),
+			)
+		}
+		return t
+	}
+	filter.after = parse("created_after", createdAfter)
+	filter.before = parse("created_before", createdBefore)
+
+	return filter, diags
+}
+
+// billingPageSize returns the page_size attribute, or the default if unset.
+func billingPageSize(pageSize types.Int64) (int, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	if pageSize.IsNull() {
+		return defaultBillingPageSize, diags
+	}
+	if pageSize.ValueInt64() < 1 {
+		diags.AddAttributeError(
+			path.Root("page_size"),
+			"Invalid Page Size",
+			fmt.Sprintf("page_size must be at least 1, got: %d", pageSize.ValueInt64()),
+		)
+	}
+
+	return int(pageSize.ValueInt64()), diags
+}
+
+// match reports whether a record with the given user, products, state and
+// creation time passes the filter. A record whose creation time cannot be
+// parsed does not pass a time range.
+func (f billingFilter) match(user string, products []string, state, createdTime string) bool {
+	if f.user != "" && user != f.user {
+		return false
+	}
+	if f.product != "" && !slices.Contains(products, f.product) {
+		return false
+	}
+	if f.state != "" && state != f.state {
+		return false
+	}
+
+	if f.after.IsZero() && f.before.IsZero() {
+		return true
+	}
+	created, err := time.Parse(time.RFC3339, createdTime)
+	if err != nil {
+		return false
+	}
+	if !f.after.IsZero() && created.Before(f.after) {
+		return false
+	}
+	if !f.before.IsZero() && !created.Before(f.before) {
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,270 @@
+// Copyright (c) HashiCorp, Inc.
+
+package provider
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// addTestBillingRecords adds the payments, orders or transactions in
+// records with add, and deletes them with del once the test is done.
+func addTestBillingRecords[T any](t *testing.T, config CasdoorTestConfig, kind string, records []*T, add, del func(*casdoorsdk.Client, *T) (bool, error)) {
+	t.Helper()
+
+	client := casdoorsdk.NewClient(
+		config.Endpoint,
+		config.ClientID,
+		config.ClientSecret,
+		config.Certificate,
+		config.OrganizationName,
+		config.ApplicationName,
+	)
+
+	for i, record := range records {
+		if _, err := add(client, record); err != nil {
+			t.Fatalf("Failed to add %s %d: %v", kind, i+1, err)
+		}
+		t.Cleanup(func() {
+			if _, err := del(client, record); err != nil {
+				t.Errorf("Failed to delete %s %d: %v", kind, i+1, err)
+			}
+		})
+	}
+}
+
+func TestBillingFilterMatch(t *testing.T) {
+	filter := func(user, product, state, after, before string) billingFilter {
+		t.Helper()
+
+		optional := func(v string) types.String {
+			if v == "" {
+				return types.StringNull()
+			}
+			return types.StringValue(v)
+		}
+		f, diags := newBillingFilter(optional(user), optional(product), optional(state), optional(after), optional(before))
+		if diags.HasError() {
+			t.Fatalf("Failed to create filter: %v", diags)
+		}
+		return f
+	}
+
+	january := "2026-01-15T10:00:00Z"
+	tests := []struct {
+		name     string
+		filter   billingFilter
+		user     string
+		products []string
+		state    string
+		created  string
+		want     bool
+	}{
+		{"no filter", filter("", "", "", "", ""), "alice", nil, "Paid", january, true},
+		{"no filter, unparsable time", filter("", "", "", "", ""), "alice", nil, "Paid", "yesterday", true},
+		{"user", filter("alice", "", "", "", ""), "alice", nil, "Paid", january, true},
+		{"other user", filter("alice", "", "", "", ""), "alice-2", nil, "Paid", january, false},
+		{"product", filter("", "premium", "", "", ""), "alice", []string{"basic", "premium"}, "Paid", january, true},
+		{"other product", filter("", "premium", "", "", ""), "alice", []string{"basic"}, "Paid", january, false},
+		{"state", filter("", "", "Paid", "", ""), "alice", nil, "Paid", january, true},
+		{"other state", filter("", "", "Paid", "", ""), "alice", nil, "Canceled", january, false},
+		{"created after", filter("", "", "", "2026-01-01T00:00:00Z", ""), "alice", nil, "Paid", january, true},
+		{"created at the start", filter("", "", "", january, ""), "alice", nil, "Paid", january, true},
+		{"created before the start", filter("", "", "", "2026-02-01T00:00:00Z", ""), "alice", nil, "Paid", january, false},
+		{"created before", filter("", "", "", "", "2026-02-01T00:00:00Z"), "alice", nil, "Paid", january, true},
+		{"created at the end", filter("", "", "", "", january), "alice", nil, "Paid", january, false},
+		{"other time zone", filter("", "", "", "2026-01-15T11:00:00+02:00", ""), "alice", nil, "Paid", january, true},
+		{"time range, unparsable time", filter("", "", "", "2026-01-01T00:00:00Z", ""), "alice", nil, "Paid", "yesterday", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.match(tt.user, tt.products, tt.state, tt.created); got != tt.want {
+				t.Errorf("match(%q, %q, %q, %q) = %t, want %t", tt.user, tt.products, tt.state, tt.created, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewBillingFilter_invalidTime(t *testing.T) {
+	_, diags := newBillingFilter(types.StringNull(), types.StringNull(), types.StringNull(), types.StringValue("2026-01-15"), types.StringNull())
+	if !diags.HasError() {
+		t.Fatal("Expected an error for a created_after that is not in RFC 3339 format")
+	}
+}
+
+func TestBillingPageSize(t *testing.T) {
+	tests := []struct {
+		name     string
+		pageSize types.Int64
+		want     int
+		wantErr  bool
+	}{
+		{"unset", types.Int64Null(), defaultBillingPageSize, false},
+		{"set", types.Int64Value(25), 25, false},
+		{"one", types.Int64Value(1), 1, false},
+		{"zero", types.Int64Value(0), 0, true},
+		{"negative", types.Int64Value(-5), 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, diags := billingPageSize(tt.pageSize)
+			if diags.HasError() != tt.wantErr {
+				t.Fatalf("billingPageSize(%v) errors = %v, want error %t", tt.pageSize, diags, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("billingPageSize(%v) = %d, want %d", tt.pageSize, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBillingRecordsDataSources(t *testing.T) {
+	// Each kind gets the same records: two of alice, paid in January and
+	// canceled in March, one of bob, paid in February, and one of another
+	// organization.
+	records := []map[string]any{
+		{"owner": "built-in", "name": "1", "createdTime": "2026-01-15T10:00:00Z", "user": "alice", "products": []any{"basic"}, "state": "Paid", "price": 9.99},
+		{"owner": "built-in", "name": "2", "createdTime": "2026-02-15T10:00:00Z", "user": "bob", "products": []any{"premium"}, "state": "Paid", "price": 29.99},
+		{"owner": "built-in", "name": "3", "createdTime": "2026-03-15T10:00:00Z", "user": "alice", "products": []any{"premium"}, "state": "Canceled", "price": 29.99},
+		{"owner": "acme", "name": "4", "createdTime": "2026-01-15T10:00:00Z", "user": "alice", "products": []any{"basic"}, "state": "Paid", "price": 9.99},
+	}
+
+	tests := []struct {
+		name       string
+		dataSource datasource.DataSource
+		kind       string
+		plural     string
+		config     map[string]tftypes.Value
+		want       []string
+		wantErr    bool
+	}{
+		{
+			name:       "payments of a user",
+			dataSource: NewPaymentsDataSource(),
+			kind:       "payment",
+			plural:     "payments",
+			config:     map[string]tftypes.Value{"user": tftypes.NewValue(tftypes.String, "alice")},
+			want:       []string{"1", "3"},
+		},
+		{
+			name:       "orders of a product in a state",
+			dataSource: NewOrdersDataSource(),
+			kind:       "order",
+			plural:     "orders",
+			config: map[string]tftypes.Value{
+				"product": tftypes.NewValue(tftypes.String, "premium"),
+				"state":   tftypes.NewValue(tftypes.String, "Paid"),
+			},
+			want: []string{"2"},
+		},
+		{
+			name:       "transactions in a time range",
+			dataSource: NewTransactionsDataSource(),
+			kind:       "transaction",
+			plural:     "transactions",
+			config: map[string]tftypes.Value{
+				"created_after":  tftypes.NewValue(tftypes.String, "2026-02-01T00:00:00Z"),
+				"created_before": tftypes.NewValue(tftypes.String, "2026-03-15T10:00:00Z"),
+			},
+			want: []string{"2"},
+		},
+		{
+			name:       "no match",
+			dataSource: NewTransactionsDataSource(),
+			kind:       "transaction",
+			plural:     "transactions",
+			config:     map[string]tftypes.Value{"user": tftypes.NewValue(tftypes.String, "carol")},
+			want:       []string{},
+		},
+		{
+			name:       "invalid time",
+			dataSource: NewPaymentsDataSource(),
+			kind:       "payment",
+			plural:     "payments",
+			config:     map[string]tftypes.Value{"created_after": tftypes.NewValue(tftypes.String, "2026-02-01")},
+			wantErr:    true,
+		},
+		{
+			name:       "invalid page size",
+			dataSource: NewOrdersDataSource(),
+			kind:       "order",
+			plural:     "orders",
+			config:     map[string]tftypes.Value{"page_size": tftypes.NewValue(tftypes.Number, 0)},
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			client := newFakeClient()
+			for _, record := range records {
+				client.put(tt.kind, record)
+			}
+
+			d := tt.dataSource.(datasource.DataSourceWithConfigure)
+			var configureResp datasource.ConfigureResponse
+			d.Configure(ctx, datasource.ConfigureRequest{ProviderData: &CasdoorProviderData{Client: client}}, &configureResp)
+			var schemaResp datasource.SchemaResponse
+			d.Schema(ctx, datasource.SchemaRequest{}, &schemaResp)
+			if diags := append(configureResp.Diagnostics, schemaResp.Diagnostics...); diags.HasError() {
+				t.Fatalf("Failed to set up data source: %v", diags)
+			}
+
+			config := maps.Clone(tt.config)
+			config["owner"] = tftypes.NewValue(tftypes.String, "built-in")
+			raw := testObjectValue(t, schemaResp.Schema, config)
+
+			resp := &datasource.ReadResponse{State: tfsdk.State{Schema: schemaResp.Schema, Raw: raw.Copy()}}
+			d.Read(ctx, datasource.ReadRequest{Config: tfsdk.Config{Schema: schemaResp.Schema, Raw: raw}}, resp)
+			if resp.Diagnostics.HasError() != tt.wantErr {
+				t.Fatalf("Expected an error: %t, got %v", tt.wantErr, resp.Diagnostics)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			var state map[string]tftypes.Value
+			if err := resp.State.Raw.As(&state); err != nil {
+				t.Fatalf("Failed to read state: %v", err)
+			}
+			var id string
+			var listed []tftypes.Value
+			if err := state["id"].As(&id); err != nil {
+				t.Fatalf("Failed to read id: %v", err)
+			}
+			if err := state[tt.plural].As(&listed); err != nil {
+				t.Fatalf("Failed to read %s: %v", tt.plural, err)
+			}
+
+			names := []string{}
+			for _, record := range listed {
+				var attrs map[string]tftypes.Value
+				var name string
+				if err := record.As(&attrs); err != nil {
+					t.Fatalf("Failed to read record: %v", err)
+				}
+				if err := attrs["name"].As(&name); err != nil {
+					t.Fatalf("Failed to read name: %v", err)
+				}
+				names = append(names, name)
+			}
+			if id != "built-in" {
+				t.Errorf("Expected id %q, got %q", "built-in", id)
+			}
+			if !slices.Equal(names, tt.want) {
+				t.Errorf("Expected %s %v, got %v", tt.plural, tt.want, names)
+			}
+		})
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64default"
@@ -21,27 +22,32 @@ var (
 	_ resource.Resource                = &CertResource{}
 	_ resource.ResourceWithConfigure   = &CertResource{}
 	_ resource.ResourceWithImportState = &CertResource{}
+	_ resource.ResourceWithModifyPlan  = &CertResource{}
 )
 
 type CertResource struct {
-	client *casdoorsdk.Client
+	client       casdoorClient
+	providerData *CasdoorProviderData
 }
 
 type CertResourceModel struct {
-	ID                     types.String `tfsdk:"id"`
-	Owner                  types.String `tfsdk:"owner"`
-	Name                   types.String `tfsdk:"name"`
-	CreatedTime            types.String `tfsdk:"created_time"`
-	DisplayName            types.String `tfsdk:"display_name"`
-	Scope                  types.String `tfsdk:"scope"`
-	Type                   types.String `tfsdk:"type"`
-	CryptoAlgorithm        types.String `tfsdk:"crypto_algorithm"`
-	BitSize                types.Int64  `tfsdk:"bit_size"`
-	ExpireInYears          types.Int64  `tfsdk:"expire_in_years"`
-	Certificate            types.String `tfsdk:"certificate"`
-	PrivateKey             types.String `tfsdk:"private_key"`
-	AuthorityPublicKey     types.String `tfsdk:"authority_public_key"`
-	AuthorityRootPublicKey types.String `tfsdk:"authority_root_public_key"`
+	ID                     types.String   `tfsdk:"id"`
+	Owner                  types.String   `tfsdk:"owner"`
+	Name                   types.String   `tfsdk:"name"`
+	CreatedTime            types.String   `tfsdk:"created_time"`
+	DisplayName            types.String   `tfsdk:"display_name"`
+	Scope                  types.String   `tfsdk:"scope"`
+	Type                   types.String   `tfsdk:"type"`
+	CryptoAlgorithm        types.String   `tfsdk:"crypto_algorithm"`
+	BitSize                types.Int64    `tfsdk:"bit_size"`
+	ExpireInYears          types.Int64    `tfsdk:"expire_in_years"`
+	Certificate            types.String   `tfsdk:"certificate"`
+	PrivateKey             types.String   `tfsdk:"private_key"`
+	AuthorityPublicKey     types.String   `tfsdk:"authority_public_key"`
+	AuthorityRootPublicKey types.String   `tfsdk:"authority_root_public_key"`
+	AdoptExisting          types.Bool     `tfsdk:"adopt_existing"`
+	DeletionProtection     types.Bool     `tfsdk:"deletion_protection"`
+	Timeouts               timeouts.Value `tfsdk:"timeouts"`
 }
 
 func NewCertResource() resource.Resource {
@@ -52,7 +58,7 @@ func (r *CertResource) Metadata(_ context.Context, req resource.MetadataRequest,
 	resp.TypeName = req.ProviderTypeName + "_cert"
 }
 
-func (r *CertResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+func (r *CertResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		Description: "Manages a Casdoor certificate.",
 		Attributes: map[string]schema.Attribute{
@@ -63,13 +69,7 @@ func (r *CertResource) Schema(_ context.Context, _ resource.SchemaRequest, resp
 					stringplanmodifier.UseStateForUnknown(),
 				},
 			},
-			"owner": schema.StringAttribute{
-				Description: "The organization that owns this certificate.",
-				Required:    true,
-				PlanModifiers: []planmodifier.String{
-					stringplanmodifier.RequiresReplace(),
-				},
-			},
+			"owner": ownerAttribute("certificate"),
 			"name": schema.StringAttribute{
 				Description: "The unique name of the certificate.",
 				Required:    true,
@@ -143,6 +143,16 @@ func (r *CertResource) Schema(_ context.Context, _ resource.SchemaRequest, resp
 				Computed:    true,
 				Default:     stringdefault.StaticString(""),
 			},
+			"adopt_existing":      adoptExistingAttribute("certificate"),
+			"deletion_protection": deletionProtectionAttribute("certificate"),
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Read:   true,
+				Update: true,
+				Delete: true,
+			}),
 		},
 	}
 }
@@ -152,16 +162,26 @@ func (r *CertResource) Configure(_ context.Context, req resource.ConfigureReques
 		return
 	}
 
-	client, ok := req.ProviderData.(*casdoorsdk.Client)
+	data, ok := req.ProviderData.(*CasdoorProviderData)
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Resource Configure Type",
-			fmt.Sprintf("Expected *casdoorsdk.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected *CasdoorProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 		return
 	}
 
-	r.client = client
+	r.client = data.Client
+	r.providerData = data
+}
+
+func (r *CertResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	planDefaultOwner(ctx, req, resp, r.providerData)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	planAdoption(ctx, req, resp, r.providerData, "certificate", r.Read)
 }
 
 func certPlanToSDK(plan CertResourceModel, createdTime string) *casdoorsdk.Cert {
@@ -183,6 +203,10 @@ func certPlanToSDK(plan CertResourceModel, createdTime string) *casdoorsdk.Cert
 }
 
 func (r *CertResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	if checkUnconfigured(r.client, &resp.Diagnostics) {
+		return
+	}
+
 	var plan CertResourceModel
 
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
@@ -190,6 +214,12 @@ func (r *CertResource) Create(ctx context.Context, req resource.CreateRequest, r
 		return
 	}
 
+	ctx, done := startOperation(ctx, plan.Timeouts.Create, defaultOperationTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	if plan.Certificate.ValueString() == "" {
 		resp.Diagnostics.AddError(
 			"Missing Certificate",
@@ -213,13 +243,20 @@ func (r *CertResource) Create(ctx context.Context, req resource.CreateRequest, r
 
 	cert := certPlanToSDK(plan, createdTime)
 
-	ok, err := r.client.AddCert(cert)
-	if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("creating certificate %q", plan.Name.ValueString())) {
+	adopted := adoptOnCreate(ctx, r.providerData, req.Plan, req.Config, &resp.Diagnostics, "certificate", plan.Owner.ValueString()+"/"+plan.Name.ValueString(), cert, r.client.Certs())
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
+	if !adopted {
+		ok, err := r.client.Certs().Add(ctx, cert)
+		if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("creating certificate %q", plan.Name.ValueString())) {
+			return
+		}
+	}
+
 	// Read back the cert to get generated values.
-	createdCert, err := r.client.GetCert(plan.Owner.ValueString() + "/" + plan.Name.ValueString())
+	createdCert, err := r.client.Certs().Get(ctx, plan.Owner.ValueString()+"/"+plan.Name.ValueString())
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Reading Certificate After Create",
@@ -250,6 +287,10 @@ func (r *CertResource) Create(ctx context.Context, req resource.CreateRequest, r
 }
 
 func (r *CertResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	if r.client == nil {
+		return
+	}
+
 	var state CertResourceModel
 
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
@@ -257,7 +298,13 @@ func (r *CertResource) Read(ctx context.Context, req resource.ReadRequest, resp
 		return
 	}
 
-	cert, err := r.client.GetCert(state.Owner.ValueString() + "/" + state.Name.ValueString())
+	ctx, done := startOperation(ctx, state.Timeouts.Read, defaultReadTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	cert, err := r.client.Certs().Get(ctx, state.Owner.ValueString()+"/"+state.Name.ValueString())
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Reading Certificate",
@@ -286,30 +333,59 @@ func (r *CertResource) Read(ctx context.Context, req resource.ReadRequest, resp
 	state.AuthorityPublicKey = types.StringValue(cert.AuthorityPublicKey)
 	state.AuthorityRootPublicKey = types.StringValue(cert.AuthorityRootPublicKey)
 
+	if state.DeletionProtection.IsNull() {
+		state.DeletionProtection = types.BoolValue(false)
+	}
+
+	resp.Diagnostics.Append(setObjectFingerprint(ctx, resp.Private, cert)...)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
 }
 
 func (r *CertResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	var plan CertResourceModel
+	if checkUnconfigured(r.client, &resp.Diagnostics) {
+		return
+	}
+
+	var plan, state CertResourceModel
 
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
+	ctx, done := startOperation(ctx, plan.Timeouts.Update, defaultOperationTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if checkBuiltInModify(r.providerData, &resp.Diagnostics, "certificate", state.Owner.ValueString()+"/"+state.Name.ValueString()) {
+		return
+	}
+
+	if checkUpdateConflict(ctx, r.providerData, &resp.Diagnostics, req.Private, "cert", state.Owner.ValueString()+"/"+state.Name.ValueString(), r.client.Certs().Get) {
+		return
+	}
+
 	cert := certPlanToSDK(plan, plan.CreatedTime.ValueString())
 
-	ok, err := r.client.UpdateCert(cert)
+	ok, err := r.client.Certs().Update(ctx, cert)
 	if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("updating certificate %q", plan.Name.ValueString())) {
 		return
 	}
 
 	plan.ID = types.StringValue(plan.Owner.ValueString() + "/" + plan.Name.ValueString())
 
+	resp.Diagnostics.Append(clearObjectFingerprint(ctx, resp.Private)...)
 	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
 }
 
 func (r *CertResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	if checkUnconfigured(r.client, &resp.Diagnostics) {
+		return
+	}
+
 	var state CertResourceModel
 
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
@@ -317,12 +393,24 @@ func (r *CertResource) Delete(ctx context.Context, req resource.DeleteRequest, r
 		return
 	}
 
+	ctx, done := startOperation(ctx, state.Timeouts.Delete, defaultOperationTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	id := state.Owner.ValueString() + "/" + state.Name.ValueString()
+	if checkDeletionProtection(&resp.Diagnostics, state.DeletionProtection, "certificate", id) ||
+		checkBuiltInDelete(r.providerData, &resp.Diagnostics, "certificate", id) {
+		return
+	}
+
 	cert := &casdoorsdk.Cert{
 		Owner: state.Owner.ValueString(),
 		Name:  state.Name.ValueString(),
 	}
 
-	ok, err := r.client.DeleteCert(cert)
+	ok, err := r.client.Certs().Delete(ctx, cert)
 	if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("deleting certificate %q", state.Name.ValueString())) {
 		return
 	}
@@ -0,0 +1,484 @@
+// Copyright (c) HashiCorp, Inc.
+
+package provider
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"strconv"
+	"strings"
+
+	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// casdoorClient is the Casdoor API as resources and data sources use it.
+// They depend on this interface rather than on the SDK, so that they can be
+// served by any implementation. sdkClient implements it on top of the Casdoor
+// Go SDK and fills the gaps the SDK leaves with plain HTTP calls. Every method
+// takes the context of the operation it is made for.
+type casdoorClient interface {
+	Adapters() objectAPI[casdoorsdk.Adapter]
+	Applications() objectAPI[casdoorsdk.Application]
+	Certs() objectAPI[casdoorsdk.Cert]
+	Enforcers() objectAPI[casdoorsdk.Enforcer]
+	Groups() objectAPI[casdoorsdk.Group]
+	Invitations() objectAPI[casdoorsdk.Invitation]
+	Ldaps() objectAPI[casdoorsdk.Ldap]
+	Models() objectAPI[casdoorsdk.Model]
+	Organizations() objectAPI[casdoorsdk.Organization]
+	Permissions() objectAPI[casdoorsdk.Permission]
+	Plans() objectAPI[casdoorsdk.Plan]
+	Pricings() objectAPI[casdoorsdk.Pricing]
+	Products() objectAPI[casdoorsdk.Product]
+	Providers() objectAPI[casdoorsdk.Provider]
+	Resources() objectAPI[casdoorsdk.Resource]
+	Roles() objectAPI[casdoorsdk.Role]
+	Subscriptions() objectAPI[casdoorsdk.Subscription]
+	Syncers() objectAPI[casdoorsdk.Syncer]
+	Tokens() objectAPI[casdoorsdk.Token]
+	Transactions() objectAPI[casdoorsdk.Transaction]
+	Users() objectAPI[casdoorsdk.User]
+	Webhooks() objectAPI[casdoorsdk.Webhook]
+
+	// OrganizationApplications lists the applications of an organization.
+	// Applications are owned by admin, so they cannot be listed by owner.
+	OrganizationApplications(ctx context.Context, organization string) ([]*casdoorsdk.Application, error)
+
+	// ListPayments, ListOrders and ListTransactions list the records owned
+	// by owner, pageSize at a time. If user is set, Casdoor narrows the list
+	// to records whose user contains it, so callers still match it exactly.
+	ListPayments(ctx context.Context, owner, user string, pageSize int) ([]*casdoorsdk.Payment, error)
+	ListOrders(ctx context.Context, owner, user string, pageSize int) ([]*casdoorsdk.Order, error)
+	ListTransactions(ctx context.Context, owner, user string, pageSize int) ([]*casdoorsdk.Transaction, error)
+
+	// AddTransaction records a transaction and returns the name Casdoor
+	// gave it. Casdoor names transactions itself, and applies the amount of
+	// a user transaction to the user's balance.
+	AddTransaction(ctx context.Context, transaction *casdoorsdk.Transaction) (string, error)
+
+	// UpdateUserColumns updates only the given database columns of the user
+	// with id. Like UpdateByID, it renames the user when the name differs.
+	UpdateUserColumns(ctx context.Context, id string, user *casdoorsdk.User, columns []string) (bool, error)
+
+	// UpdateByID posts obj to the update endpoint for kind (e.g. "group" for
+	// update-group), addressing the object by id instead of by the owner and
+	// name in obj. Casdoor renames the object when the two differ.
+	UpdateByID(ctx context.Context, kind, id string, obj any) (bool, error)
+
+	// ObjectExists reports whether the get endpoint for kind returns an
+	// object for id.
+	ObjectExists(ctx context.Context, kind, id string) (bool, error)
+
+	// UploadResource uploads a file as a Casdoor resource of the owner
+	// organization and returns its URL and name.
+	UploadResource(ctx context.Context, owner, user, tag, parent, fullFilePath string, fileBytes []byte, createdTime, description string) (string, string, error)
+
+	// ParseJwtToken verifies a JWT issued by Casdoor and returns its
+	// claims. The signature is checked with the configured certificate, or,
+	// if there is none, with the key the token names from Casdoor's JSON Web
+	// Key Set.
+	ParseJwtToken(ctx context.Context, token string) (*casdoorsdk.Claims, error)
+
+	// OrganizationName is the organization the provider is configured with.
+	OrganizationName() string
+
+	// ReadOnly reports whether the provider is configured with read_only,
+	// in which case every call that could change Casdoor fails.
+	ReadOnly() bool
+}
+
+// objectAPI is the API of one kind of Casdoor object. IDs are in the format
+// 'owner/name'.
+type objectAPI[T any] interface {
+	// Get returns nil if the object does not exist.
+	Get(ctx context.Context, id string) (*T, error)
+	// List returns the objects owned by owner.
+	List(ctx context.Context, owner string) ([]*T, error)
+	Add(ctx context.Context, obj *T) (bool, error)
+	Update(ctx context.Context, obj *T) (bool, error)
+	Delete(ctx context.Context, obj *T) (bool, error)
+}
+
+// sdkClient implements casdoorClient with the Casdoor Go SDK.
+type sdkClient struct {
+	client *casdoorsdk.Client
+	// keys verifies JWTs if the client has no certificate.
+	keys *jwksKeySet
+}
+
+var _ casdoorClient = &sdkClient{}
+
+func newSDKClient(client *casdoorsdk.Client) *sdkClient {
+	useContextHTTPClient()
+
+	c := &sdkClient{client: client}
+	if client.Certificate == "" {
+		c.keys = newJWKSKeySet(client.Endpoint, client.ApplicationName)
+	}
+
+	return c
+}
+
+// bound returns a copy of the SDK client whose requests are sent with ctx,
+// and a function releasing it.
+func (c *sdkClient) bound(ctx context.Context) (*casdoorsdk.Client, func()) {
+	return sdkHTTPClient.bind(ctx, c.client)
+}
+
+// sdkObjects implements objectAPI with the SDK functions for one kind of
+// object. Kinds the SDK cannot add or update are added and updated by
+// posting to add-<kind> and update-<kind> directly.
+type sdkObjects[T any] struct {
+	client *sdkClient
+	kind   string
+	get    func(*casdoorsdk.Client, string) (*T, error)
+	add    func(*casdoorsdk.Client, *T) (bool, error)
+	update func(*casdoorsdk.Client, *T) (bool, error)
+	del    func(*casdoorsdk.Client, *T) (bool, error)
+}
+
+func (o sdkObjects[T]) Get(ctx context.Context, id string) (*T, error) {
+	client, release := o.client.bound(ctx)
+	defer release()
+
+	return o.get(client, id)
+}
+
+// List calls get-<kind>s directly, as the SDK's list functions always list
+// the provider's organization.
+func (o sdkObjects[T]) List(ctx context.Context, owner string) ([]*T, error) {
+	return listObjects[T](ctx, o.client, "get-"+o.kind+"s", map[string]string{"owner": owner})
+}
+
+func (o sdkObjects[T]) Add(ctx context.Context, obj *T) (bool, error) {
+	if o.add == nil {
+		return o.client.post(ctx, "add-"+o.kind, nil, obj)
+	}
+
+	client, release := o.client.bound(ctx)
+	defer release()
+
+	return o.add(client, obj)
+}
+
+func (o sdkObjects[T]) Update(ctx context.Context, obj *T) (bool, error) {
+	if o.update == nil {
+		id, err := objectID(obj)
+		if err != nil {
+			return false, err
+		}
+		return o.client.UpdateByID(ctx, o.kind, id, obj)
+	}
+
+	client, release := o.client.bound(ctx)
+	defer release()
+
+	return o.update(client, obj)
+}
+
+func (o sdkObjects[T]) Delete(ctx context.Context, obj *T) (bool, error) {
+	client, release := o.client.bound(ctx)
+	defer release()
+
+	return o.del(client, obj)
+}
+
+func (c *sdkClient) Adapters() objectAPI[casdoorsdk.Adapter] {
+	return sdkObjects[casdoorsdk.Adapter]{c, "adapter", (*casdoorsdk.Client).GetAdapter, (*casdoorsdk.Client).AddAdapter, (*casdoorsdk.Client).UpdateAdapter, (*casdoorsdk.Client).DeleteAdapter}
+}
+
+func (c *sdkClient) Applications() objectAPI[casdoorsdk.Application] {
+	return sdkObjects[casdoorsdk.Application]{c, "application", (*casdoorsdk.Client).GetApplication, (*casdoorsdk.Client).AddApplication, (*casdoorsdk.Client).UpdateApplication, (*casdoorsdk.Client).DeleteApplication}
+}
+
+func (c *sdkClient) Certs() objectAPI[casdoorsdk.Cert] {
+	return sdkObjects[casdoorsdk.Cert]{c, "cert", (*casdoorsdk.Client).GetCert, (*casdoorsdk.Client).AddCert, (*casdoorsdk.Client).UpdateCert, (*casdoorsdk.Client).DeleteCert}
+}
+
+func (c *sdkClient) Enforcers() objectAPI[casdoorsdk.Enforcer] {
+	return sdkObjects[casdoorsdk.Enforcer]{c, "enforcer", (*casdoorsdk.Client).GetEnforcer, (*casdoorsdk.Client).AddEnforcer, (*casdoorsdk.Client).UpdateEnforcer, (*casdoorsdk.Client).DeleteEnforcer}
+}
+
+func (c *sdkClient) Groups() objectAPI[casdoorsdk.Group] {
+	return sdkObjects[casdoorsdk.Group]{c, "group", (*casdoorsdk.Client).GetGroup, (*casdoorsdk.Client).AddGroup, (*casdoorsdk.Client).UpdateGroup, (*casdoorsdk.Client).DeleteGroup}
+}
+
+func (c *sdkClient) Invitations() objectAPI[casdoorsdk.Invitation] {
+	return sdkObjects[casdoorsdk.Invitation]{c, "invitation", (*casdoorsdk.Client).GetInvitation, (*casdoorsdk.Client).AddInvitation, (*casdoorsdk.Client).UpdateInvitation, (*casdoorsdk.Client).DeleteInvitation}
+}
+
+func (c *sdkClient) Ldaps() objectAPI[casdoorsdk.Ldap] {
+	return sdkObjects[casdoorsdk.Ldap]{c, "ldap", (*casdoorsdk.Client).GetLdap, (*casdoorsdk.Client).AddLdap, (*casdoorsdk.Client).UpdateLdap, (*casdoorsdk.Client).DeleteLdap}
+}
+
+func (c *sdkClient) Models() objectAPI[casdoorsdk.Model] {
+	return sdkObjects[casdoorsdk.Model]{c, "model", (*casdoorsdk.Client).GetModel, (*casdoorsdk.Client).AddModel, (*casdoorsdk.Client).UpdateModel, (*casdoorsdk.Client).DeleteModel}
+}
+
+func (c *sdkClient) Organizations() objectAPI[casdoorsdk.Organization] {
+	return sdkObjects[casdoorsdk.Organization]{c, "organization", (*casdoorsdk.Client).GetOrganization, (*casdoorsdk.Client).AddOrganization, (*casdoorsdk.Client).UpdateOrganization, (*casdoorsdk.Client).DeleteOrganization}
+}
+
+func (c *sdkClient) Permissions() objectAPI[casdoorsdk.Permission] {
+	return sdkObjects[casdoorsdk.Permission]{c, "permission", (*casdoorsdk.Client).GetPermission, (*casdoorsdk.Client).AddPermission, (*casdoorsdk.Client).UpdatePermission, (*casdoorsdk.Client).DeletePermission}
+}
+
+func (c *sdkClient) Plans() objectAPI[casdoorsdk.Plan] {
+	return sdkObjects[casdoorsdk.Plan]{c, "plan", (*casdoorsdk.Client).GetPlan, (*casdoorsdk.Client).AddPlan, (*casdoorsdk.Client).UpdatePlan, (*casdoorsdk.Client).DeletePlan}
+}
+
+func (c *sdkClient) Pricings() objectAPI[casdoorsdk.Pricing] {
+	return sdkObjects[casdoorsdk.Pricing]{c, "pricing", (*casdoorsdk.Client).GetPricing, (*casdoorsdk.Client).AddPricing, (*casdoorsdk.Client).UpdatePricing, (*casdoorsdk.Client).DeletePricing}
+}
+
+func (c *sdkClient) Products() objectAPI[casdoorsdk.Product] {
+	return sdkObjects[casdoorsdk.Product]{c, "product", (*casdoorsdk.Client).GetProduct, (*casdoorsdk.Client).AddProduct, (*casdoorsdk.Client).UpdateProduct, (*casdoorsdk.Client).DeleteProduct}
+}
+
+func (c *sdkClient) Providers() objectAPI[casdoorsdk.Provider] {
+	return sdkObjects[casdoorsdk.Provider]{c, "provider", (*casdoorsdk.Client).GetProvider, (*casdoorsdk.Client).AddProvider, (*casdoorsdk.Client).UpdateProvider, (*casdoorsdk.Client).DeleteProvider}
+}
+
+// Resources are uploaded with UploadResource; the SDK has no add or update
+// for them.
+func (c *sdkClient) Resources() objectAPI[casdoorsdk.Resource] {
+	return sdkObjects[casdoorsdk.Resource]{c, "resource", (*casdoorsdk.Client).GetResource, nil, nil, (*casdoorsdk.Client).DeleteResource}
+}
+
+func (c *sdkClient) Roles() objectAPI[casdoorsdk.Role] {
+	return sdkObjects[casdoorsdk.Role]{c, "role", (*casdoorsdk.Client).GetRole, (*casdoorsdk.Client).AddRole, (*casdoorsdk.Client).UpdateRole, (*casdoorsdk.Client).DeleteRole}
+}
+
+func (c *sdkClient) Subscriptions() objectAPI[casdoorsdk.Subscription] {
+	return sdkObjects[casdoorsdk.Subscription]{c, "subscription", (*casdoorsdk.Client).GetSubscription, (*casdoorsdk.Client).AddSubscription, (*casdoorsdk.Client).UpdateSubscription, (*casdoorsdk.Client).DeleteSubscription}
+}
+
+func (c *sdkClient) Syncers() objectAPI[casdoorsdk.Syncer] {
+	return sdkObjects[casdoorsdk.Syncer]{c, "syncer", (*casdoorsdk.Client).GetSyncer, (*casdoorsdk.Client).AddSyncer, (*casdoorsdk.Client).UpdateSyncer, (*casdoorsdk.Client).DeleteSyncer}
+}
+
+func (c *sdkClient) Tokens() objectAPI[casdoorsdk.Token] {
+	return sdkObjects[casdoorsdk.Token]{c, "token", (*casdoorsdk.Client).GetToken, (*casdoorsdk.Client).AddToken, (*casdoorsdk.Client).UpdateToken, (*casdoorsdk.Client).DeleteToken}
+}
+
+// Transactions are added with AddTransaction, which returns the name Casdoor
+// gives them.
+func (c *sdkClient) Transactions() objectAPI[casdoorsdk.Transaction] {
+	return sdkObjects[casdoorsdk.Transaction]{c, "transaction", (*casdoorsdk.Client).GetTransaction, nil, (*casdoorsdk.Client).UpdateTransaction, (*casdoorsdk.Client).DeleteTransaction}
+}
+
+func (c *sdkClient) Users() objectAPI[casdoorsdk.User] {
+	return sdkObjects[casdoorsdk.User]{c, "user", (*casdoorsdk.Client).GetUser, (*casdoorsdk.Client).AddUser, (*casdoorsdk.Client).UpdateUser, (*casdoorsdk.Client).DeleteUser}
+}
+
+func (c *sdkClient) Webhooks() objectAPI[casdoorsdk.Webhook] {
+	return sdkObjects[casdoorsdk.Webhook]{c, "webhook", (*casdoorsdk.Client).GetWebhook, (*casdoorsdk.Client).AddWebhook, (*casdoorsdk.Client).UpdateWebhook, (*casdoorsdk.Client).DeleteWebhook}
+}
+
+func (c *sdkClient) OrganizationApplications(ctx context.Context, organization string) ([]*casdoorsdk.Application, error) {
+	return listObjects[casdoorsdk.Application](ctx, c, "get-organization-applications", map[string]string{"owner": "admin", "organization": organization})
+}
+
+func (c *sdkClient) ListPayments(ctx context.Context, owner, user string, pageSize int) ([]*casdoorsdk.Payment, error) {
+	return listObjectPages[casdoorsdk.Payment](ctx, c, "get-payments", userQuery(owner, user), pageSize)
+}
+
+func (c *sdkClient) ListOrders(ctx context.Context, owner, user string, pageSize int) ([]*casdoorsdk.Order, error) {
+	return listObjectPages[casdoorsdk.Order](ctx, c, "get-orders", userQuery(owner, user), pageSize)
+}
+
+func (c *sdkClient) ListTransactions(ctx context.Context, owner, user string, pageSize int) ([]*casdoorsdk.Transaction, error) {
+	return listObjectPages[casdoorsdk.Transaction](ctx, c, "get-transactions", userQuery(owner, user), pageSize)
+}
+
+// userQuery is the query listing the objects of owner whose user field
+// contains user, or all of them if user is empty.
+func userQuery(owner, user string) map[string]string {
+	query := map[string]string{"owner": owner}
+	if user != "" {
+		query["field"] = "user"
+		query["value"] = user
+	}
+	return query
+}
+
+func (c *sdkClient) UpdateUserColumns(ctx context.Context, id string, user *casdoorsdk.User, columns []string) (bool, error) {
+	return c.post(ctx, "update-user", map[string]string{"id": id, "columns": strings.Join(columns, ",")}, user)
+}
+
+func (c *sdkClient) UpdateByID(ctx context.Context, kind, id string, obj any) (bool, error) {
+	return c.post(ctx, "update-"+kind, map[string]string{"id": id}, obj)
+}
+
+func (c *sdkClient) ObjectExists(ctx context.Context, kind, id string) (bool, error) {
+	client, release := c.bound(ctx)
+	defer release()
+
+	resp, err := client.DoGetResponse(client.GetUrl("get-"+kind, map[string]string{"id": id}))
+	if err != nil {
+		return false, err
+	}
+
+	return resp.Data != nil, nil
+}
+
+func (c *sdkClient) AddTransaction(ctx context.Context, transaction *casdoorsdk.Transaction) (string, error) {
+	client, release := c.bound(ctx)
+	defer release()
+
+	_, name, err := client.AddTransaction(transaction)
+	return name, err
+}
+
+func (c *sdkClient) UploadResource(ctx context.Context, owner, user, tag, parent, fullFilePath string, fileBytes []byte, createdTime, description string) (string, string, error) {
+	client, release := c.bound(ctx)
+	defer release()
+
+	// The SDK uploads to the client's organization. The bound client is a
+	// copy, so this leaves c as it is.
+	client.OrganizationName = owner
+
+	return client.UploadResourceEx(user, tag, parent, fullFilePath, fileBytes, createdTime, description)
+}
+
+func (c *sdkClient) ParseJwtToken(ctx context.Context, token string) (*casdoorsdk.Claims, error) {
+	if c.keys == nil {
+		return c.client.ParseJwtToken(token)
+	}
+
+	claims := &casdoorsdk.Claims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		key, err := c.keys.Key(ctx, kid)
+		if err != nil {
+			return nil, err
+		}
+
+		switch t.Method.(type) {
+		case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS:
+			if _, ok := key.(*rsa.PublicKey); ok {
+				return key, nil
+			}
+		case *jwt.SigningMethodECDSA:
+			if _, ok := key.(*ecdsa.PublicKey); ok {
+				return key, nil
+			}
+		}
+
+		return nil, fmt.Errorf("key %q cannot verify %v signatures", kid, t.Header["alg"])
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+func (c *sdkClient) OrganizationName() string {
+	return c.client.OrganizationName
+}
+
+func (c *sdkClient) ReadOnly() bool {
+	return c.client.CustomHeaders[readOnlyHeader] != ""
+}
+
+// post sends obj as JSON to a Casdoor endpoint and reports whether Casdoor
+// changed anything.
+func (c *sdkClient) post(ctx context.Context, action string, query map[string]string, obj any) (bool, error) {
+	postBytes, err := json.Marshal(obj)
+	if err != nil {
+		return false, err
+	}
+
+	client, release := c.bound(ctx)
+	defer release()
+
+	resp, err := client.DoPost(action, query, postBytes, false, false)
+	if err != nil {
+		return false, err
+	}
+
+	return resp.Data == "Affected", nil
+}
+
+// listObjects fetches a Casdoor list endpoint, such as get-users, with the
+// given query.
+func listObjects[T any](ctx context.Context, c *sdkClient, action string, query map[string]string) ([]*T, error) {
+	client, release := c.bound(ctx)
+	defer release()
+
+	bytes, err := client.DoGetBytes(client.GetUrl(action, query))
+	if err != nil {
+		return nil, err
+	}
+
+	var objects []*T
+	if err := json.Unmarshal(bytes, &objects); err != nil {
+		return nil, err
+	}
+
+	return objects, nil
+}
+
+// listObjectPages calls a Casdoor list endpoint page by page, pageSize
+// objects at a time, in the order they were created, until it has the total
+// Casdoor reports.
+func listObjectPages[T any](ctx context.Context, c *sdkClient, action string, query map[string]string, pageSize int) ([]*T, error) {
+	client, release := c.bound(ctx)
+	defer release()
+
+	var objects []*T
+	for page := 1; ; page++ {
+		pageQuery := maps.Clone(query)
+		pageQuery["p"] = strconv.Itoa(page)
+		pageQuery["pageSize"] = strconv.Itoa(pageSize)
+		pageQuery["sortField"] = "createdTime"
+		pageQuery["sortOrder"] = "ascend"
+
+		resp, err := client.DoGetResponse(client.GetUrl(action, pageQuery))
+		if err != nil {
+			return nil, err
+		}
+
+		content, err := json.Marshal(resp.Data)
+		if err != nil {
+			return nil, err
+		}
+		var pageObjects []*T
+		if err := json.Unmarshal(content, &pageObjects); err != nil {
+			return nil, err
+		}
+		objects = append(objects, pageObjects...)
+
+		total, ok := resp.Data2.(float64)
+		if !ok {
+			return nil, fmt.Errorf("%s returned no total count", action)
+		}
+		if len(pageObjects) < pageSize || len(objects) >= int(total) {
+			return objects, nil
+		}
+	}
+}
+
+// objectID returns the 'owner/name' ID of a Casdoor object.
+func objectID(obj any) (string, error) {
+	content, err := json.Marshal(obj)
+	if err != nil {
+		return "", err
+	}
+
+	var fields struct {
+		Owner string `json:"owner"`
+		Name  string `json:"name"`
+	}
+	if err := json.Unmarshal(content, &fields); err != nil {
+		return "", err
+	}
+
+	return fields.Owner + "/" + fields.Name, nil
+}
@@ -0,0 +1,230 @@
+// Copyright (c) HashiCorp, Inc.
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+const (
+	// defaultOperationTimeout bounds create, update and delete operations
+	// whose timeouts block does not say otherwise.
+	defaultOperationTimeout = 20 * time.Minute
+	// defaultReadTimeout bounds read operations whose timeouts block does not
+	// say otherwise.
+	defaultReadTimeout = 5 * time.Minute
+
+	// operationHeader tags the requests made with one context so that
+	// contextHTTPClient can find the context. It is removed before the
+	// request is sent.
+	operationHeader = "X-Casdoor-Provider-Operation"
+
+	// accessKeyHeader and accessSecretHeader carry the access key and secret
+	// of a client that authenticates as a user. contextHTTPClient moves them
+	// into the query parameters Casdoor reads them from.
+	accessKeyHeader    = "X-Casdoor-Provider-Access-Key"
+	accessSecretHeader = "X-Casdoor-Provider-Access-Secret"
+
+	// readOnlyHeader tags the requests of a client that must not change
+	// anything in Casdoor. contextHTTPClient refuses to send its mutating
+	// requests.
+	readOnlyHeader = "X-Casdoor-Provider-Read-Only"
+)
+
+// contextHTTPClient is installed as the Casdoor SDK's HTTP client. The SDK
+// neither takes a context nor lets one be set per request, so sdkClient makes
+// every call through its own copy of the SDK client whose requests carry
+// operationHeader, and the context registered under the header's value is
+// attached to the request before it is sent. Requests without the header are
+// sent as they are. The SDK cannot authenticate with access keys or login
+// sessions either, so requests of clients set up with withAccessKey or
+// withSession are rewritten to do so, and it knows only one endpoint, so
+// requests of clients set up with withEndpoints are sent through their
+// endpointPool.
+type contextHTTPClient struct {
+	client *http.Client
+
+	mu       sync.Mutex
+	contexts map[string]context.Context
+	sessions map[string]*loginSession
+	pools    map[string]*endpointPool
+	lastID   atomic.Uint64
+}
+
+var (
+	sdkHTTPClient = &contextHTTPClient{
+		client:   &http.Client{},
+		contexts: make(map[string]context.Context),
+		sessions: make(map[string]*loginSession),
+		pools:    make(map[string]*endpointPool),
+	}
+	installSDKHTTPClient sync.Once
+)
+
+// useContextHTTPClient makes the Casdoor SDK send its requests through
+// sdkHTTPClient. It is safe to call more than once.
+func useContextHTTPClient() {
+	installSDKHTTPClient.Do(func() {
+		casdoorsdk.SetHttpClient(sdkHTTPClient)
+	})
+}
+
+func (h *contextHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	accessSecret := req.Header.Get(accessSecretHeader)
+	if accessKey := req.Header.Get(accessKeyHeader); accessKey != "" {
+		authenticateWithAccessKey(req, accessKey, accessSecret)
+	}
+
+	resp, err := h.do(req)
+	if err != nil && accessSecret != "" {
+		err = &redactedError{err: err, secret: accessSecret}
+	}
+
+	return resp, err
+}
+
+func (h *contextHTTPClient) do(req *http.Request) (*http.Response, error) {
+	readOnly := req.Header.Get(readOnlyHeader) != ""
+	req.Header.Del(readOnlyHeader)
+	if readOnly && mutating(req) {
+		return nil, fmt.Errorf("the provider is configured with read_only = true and refuses to call %s", path.Base(req.URL.Path))
+	}
+
+	id := req.Header.Get(operationHeader)
+	sessionID := req.Header.Get(sessionHeader)
+	poolID := req.Header.Get(endpointsHeader)
+	req.Header.Del(operationHeader)
+	req.Header.Del(sessionHeader)
+	req.Header.Del(endpointsHeader)
+
+	h.mu.Lock()
+	ctx, ok := h.contexts[id]
+	session := h.sessions[sessionID]
+	pool := h.pools[poolID]
+	h.mu.Unlock()
+
+	if ok {
+		req = req.WithContext(ctx)
+	}
+	if session != nil {
+		return session.do(req)
+	}
+	if pool != nil {
+		return pool.do(h.client, req)
+	}
+
+	return h.client.Do(req)
+}
+
+// bind returns a copy of client whose requests are sent with ctx, and a
+// function that forgets ctx again once the operation is done.
+func (h *contextHTTPClient) bind(ctx context.Context, client *casdoorsdk.Client) (*casdoorsdk.Client, func()) {
+	id := strconv.FormatUint(h.lastID.Add(1), 10)
+
+	h.mu.Lock()
+	h.contexts[id] = ctx
+	h.mu.Unlock()
+
+	bound := *client
+	bound.CustomHeaders = make(map[string]string, len(client.CustomHeaders)+1)
+	for k, v := range client.CustomHeaders {
+		bound.CustomHeaders[k] = v
+	}
+	bound.CustomHeaders[operationHeader] = id
+
+	return &bound, func() {
+		h.mu.Lock()
+		delete(h.contexts, id)
+		h.mu.Unlock()
+	}
+}
+
+// withAccessKey makes client authenticate as the user owning the access key
+// instead of with its client ID and secret.
+func withAccessKey(client *casdoorsdk.Client, accessKey, accessSecret string) {
+	if client.CustomHeaders == nil {
+		client.CustomHeaders = make(map[string]string, 2)
+	}
+	client.CustomHeaders[accessKeyHeader] = accessKey
+	client.CustomHeaders[accessSecretHeader] = accessSecret
+}
+
+// withReadOnly makes client fail every request that could change something
+// in Casdoor.
+func withReadOnly(client *casdoorsdk.Client) {
+	if client.CustomHeaders == nil {
+		client.CustomHeaders = make(map[string]string, 1)
+	}
+	client.CustomHeaders[readOnlyHeader] = "true"
+}
+
+// mutating reports whether req may change something in Casdoor. The API
+// reads with GET, except for the get- and enforce endpoints, which take
+// their arguments in a POST body.
+func mutating(req *http.Request) bool {
+	if req.Method == http.MethodGet || req.Method == http.MethodHead {
+		return false
+	}
+
+	action := path.Base(req.URL.Path)
+	return !strings.HasPrefix(action, "get-") && action != "enforce" && action != "batch-enforce"
+}
+
+// authenticateWithAccessKey replaces the basic authentication the SDK sets
+// with the accessKey and accessSecret query parameters.
+func authenticateWithAccessKey(req *http.Request, accessKey, accessSecret string) {
+	req.Header.Del(accessKeyHeader)
+	req.Header.Del(accessSecretHeader)
+	req.Header.Del("Authorization")
+
+	query := req.URL.Query()
+	query.Set("accessKey", accessKey)
+	query.Set("accessSecret", accessSecret)
+	req.URL.RawQuery = query.Encode()
+}
+
+// redactedError hides secret, as sent in a query parameter, in the message
+// of err, such as the URL a *url.Error reports, so that it does not end up in
+// diagnostics or logs.
+type redactedError struct {
+	err    error
+	secret string
+}
+
+func (e *redactedError) Error() string {
+	return strings.NewReplacer(
+		url.QueryEscape(e.secret), "REDACTED",
+		e.secret, "REDACTED",
+	).Replace(e.err.Error())
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
+
+// startOperation bounds ctx by the timeout the operation is configured with,
+// or by fallback if timeout is nil. Cancelling the operation, or running out
+// of time, aborts the request in flight. The returned function must be
+// deferred; errors are recorded in diags.
+func startOperation(ctx context.Context, timeout func(context.Context, time.Duration) (time.Duration, diag.Diagnostics), fallback time.Duration, diags *diag.Diagnostics) (context.Context, context.CancelFunc) {
+	d := fallback
+	if timeout != nil {
+		var timeoutDiags diag.Diagnostics
+		d, timeoutDiags = timeout(ctx, fallback)
+		diags.Append(timeoutDiags...)
+	}
+
+	return context.WithTimeout(ctx, d)
+}
@@ -0,0 +1,46 @@
+// Copyright (c) HashiCorp, Inc.
+
+package provider
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestMutating(t *testing.T) {
+	tests := []struct {
+		method string
+		path   string
+		want   bool
+	}{
+		// Reads.
+		{method: http.MethodGet, path: "/api/get-user"},
+		{method: http.MethodGet, path: "/api/get-organizations"},
+		{method: http.MethodGet, path: "/api/health"},
+		{method: http.MethodHead, path: "/api/get-user"},
+		{method: http.MethodPost, path: "/api/get-users"},
+		{method: http.MethodPost, path: "/api/get-sorted-users"},
+		{method: http.MethodPost, path: "/api/enforce"},
+		{method: http.MethodPost, path: "/api/batch-enforce"},
+
+		// Writes.
+		{method: http.MethodPost, path: "/api/add-user", want: true},
+		{method: http.MethodPost, path: "/api/update-user", want: true},
+		{method: http.MethodPost, path: "/api/delete-user", want: true},
+		{method: http.MethodPost, path: "/api/add-transaction", want: true},
+		{method: http.MethodPost, path: "/api/upload-resource", want: true},
+		{method: http.MethodPost, path: "/api/login", want: true},
+		{method: http.MethodPost, path: "/api/set-password", want: true},
+		{method: http.MethodPost, path: "/api/update-permission", want: true},
+	}
+
+	for _, tt := range tests {
+		req, err := http.NewRequest(tt.method, "https://casdoor.example.com"+tt.path, nil)
+		if err != nil {
+			t.Fatalf("Failed to create request: %v", err)
+		}
+		if got := mutating(req); got != tt.want {
+			t.Errorf("Expected mutating(%s %s) to be %v, got %v", tt.method, tt.path, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,285 @@
+// Copyright (c) HashiCorp, Inc.
+
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+const (
+	// endpointsHeader tags the requests of a client with failover endpoints,
+	// so that contextHTTPClient can find its endpointPool. It is removed
+	// before the request is sent.
+	endpointsHeader = "X-Casdoor-Provider-Endpoints"
+
+	// endpointRecheckInterval is how long an endpoint that failed is skipped
+	// before a health check may bring it back.
+	endpointRecheckInterval = 30 * time.Second
+)
+
+// endpointPool spreads the requests of a client over several endpoints of
+// the same Casdoor deployment. Reads go to the first healthy endpoint, the
+// primary first, and fail over to the next one when an endpoint cannot be
+// reached or answers with a server error. Writes go to the primary, and
+// fall back to the write fallback endpoints, in order, only when the primary
+// cannot be reached, so that a write is never sent twice. An endpoint that
+// fails is skipped by reads until a health check succeeds again.
+type endpointPool struct {
+	primary       string
+	failover      []string
+	writeFallback []string
+
+	mu sync.Mutex
+	// down maps the endpoints that failed to when they were last checked.
+	down map[string]time.Time
+}
+
+func newEndpointPool(primary string, failover, writeFallback []string) *endpointPool {
+	trim := func(endpoints []string) []string {
+		trimmed := make([]string, len(endpoints))
+		for i, endpoint := range endpoints {
+			trimmed[i] = strings.TrimSuffix(endpoint, "/")
+		}
+		return trimmed
+	}
+
+	return &endpointPool{
+		primary:       strings.TrimSuffix(primary, "/"),
+		failover:      trim(failover),
+		writeFallback: trim(writeFallback),
+		down:          make(map[string]time.Time),
+	}
+}
+
+// candidates returns the endpoints to try for a read or a write, in order.
+// Endpoints that failed come last, unless a health check shows they are
+// back. Writes always try the primary first: whether it failed a read says
+// nothing about whether the write can reach it.
+func (p *endpointPool) candidates(ctx context.Context, write bool) []string {
+	if write {
+		return append([]string{p.primary}, p.ordered(ctx, p.writeFallback)...)
+	}
+
+	return p.ordered(ctx, append([]string{p.primary}, p.failover...))
+}
+
+// ordered returns endpoints with those that are down moved to the end.
+func (p *endpointPool) ordered(ctx context.Context, endpoints []string) []string {
+	var up, down []string
+	for _, endpoint := range endpoints {
+		if p.available(ctx, endpoint) {
+			up = append(up, endpoint)
+		} else {
+			down = append(down, endpoint)
+		}
+	}
+
+	return append(up, down...)
+}
+
+// available reports whether endpoint has not failed, or has passed a health
+// check since.
+func (p *endpointPool) available(ctx context.Context, endpoint string) bool {
+	p.mu.Lock()
+	checked, isDown := p.down[endpoint]
+	if isDown && time.Since(checked) < endpointRecheckInterval {
+		p.mu.Unlock()
+		return false
+	}
+	if isDown {
+		// Let concurrent requests skip the endpoint while it is checked.
+		p.down[endpoint] = time.Now()
+	}
+	p.mu.Unlock()
+
+	if !isDown {
+		return true
+	}
+	if err := checkHealth(ctx, endpoint); err != nil {
+		return false
+	}
+
+	p.mu.Lock()
+	delete(p.down, endpoint)
+	p.mu.Unlock()
+	tflog.Info(ctx, "Casdoor endpoint is healthy again", map[string]any{"endpoint": endpoint})
+
+	return true
+}
+
+func (p *endpointPool) markDown(endpoint string) {
+	p.mu.Lock()
+	p.down[endpoint] = time.Now()
+	p.mu.Unlock()
+}
+
+// healthy returns the first endpoint, the primary first, that passes a health
+// check, or the primary if none does.
+func (p *endpointPool) healthy(ctx context.Context) string {
+	for _, endpoint := range append([]string{p.primary}, p.failover...) {
+		if err := checkHealth(ctx, endpoint); err == nil {
+			return endpoint
+		}
+		p.markDown(endpoint)
+	}
+
+	return p.primary
+}
+
+// do sends req, which the SDK addressed to the primary, to the endpoints
+// returned by candidates until one of them serves it. Errors returned by
+// Casdoor name the endpoint that served the request.
+func (p *endpointPool) do(client *http.Client, req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	write := mutating(req)
+	candidates := p.candidates(ctx, write)
+
+	var errs []error
+	for i, endpoint := range candidates {
+		attempt, err := p.rebase(req, endpoint, i > 0)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := client.Do(attempt)
+		if ctx.Err() != nil || !p.failed(resp, err, write) || i == len(candidates)-1 {
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", endpoint, err))
+				return nil, errors.Join(errs...)
+			}
+			annotateServedBy(resp, endpoint)
+			return resp, nil
+		}
+
+		if err == nil {
+			err = fmt.Errorf("status %d", resp.StatusCode)
+			_ = resp.Body.Close()
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", endpoint, err))
+		p.markDown(endpoint)
+
+		tflog.Warn(ctx, "Casdoor endpoint failed, failing over", map[string]any{
+			"endpoint": endpoint,
+			"next":     candidates[i+1],
+			"path":     req.URL.Path,
+			"error":    err.Error(),
+		})
+	}
+
+	return nil, errors.Join(errs...)
+}
+
+// failed reports whether a request should be sent to the next endpoint.
+// Reads fail over on any transport or server error. Writes fail over only if
+// the endpoint could not be connected to or refused them as unavailable, as
+// they may have been applied otherwise; a gateway timeout does not tell.
+func (p *endpointPool) failed(resp *http.Response, err error, write bool) bool {
+	if err != nil {
+		if !write {
+			return true
+		}
+		var opErr *net.OpError
+		return errors.As(err, &opErr) && opErr.Op == "dial"
+	}
+
+	if write {
+		switch resp.StatusCode {
+		case http.StatusBadGateway, http.StatusServiceUnavailable:
+			return true
+		}
+		return false
+	}
+	return resp.StatusCode >= http.StatusInternalServerError
+}
+
+// rebase returns req addressed to endpoint instead of the primary. A resent
+// request gets a fresh copy of the body.
+func (p *endpointPool) rebase(req *http.Request, endpoint string, resend bool) (*http.Request, error) {
+	attempt := req
+	if endpoint != p.primary {
+		rest, ok := strings.CutPrefix(req.URL.String(), p.primary)
+		if !ok {
+			return req, nil
+		}
+		u, err := url.Parse(endpoint + rest)
+		if err != nil {
+			return nil, fmt.Errorf("invalid endpoint %q: %w", endpoint, err)
+		}
+		attempt = req.Clone(req.Context())
+		attempt.URL = u
+		attempt.Host = ""
+	}
+
+	if resend && req.Body != nil {
+		if req.GetBody == nil {
+			return nil, fmt.Errorf("cannot resend %s request to %s", req.URL.Path, endpoint)
+		}
+		if attempt == req {
+			attempt = req.Clone(req.Context())
+		}
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		attempt.Body = body
+	}
+
+	return attempt, nil
+}
+
+// annotateServedBy appends the endpoint to the message of a Casdoor error
+// response, so that the diagnostics built from it show which endpoint
+// served the request.
+func annotateServedBy(resp *http.Response, endpoint string) {
+	content, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(content))
+	if err != nil {
+		return
+	}
+
+	var result map[string]any
+	if err := json.Unmarshal(content, &result); err != nil || result["status"] != "error" {
+		return
+	}
+	msg, _ := result["msg"].(string)
+	result["msg"] = fmt.Sprintf("%s (served by %s)", msg, endpoint)
+
+	annotated, err := json.Marshal(result)
+	if err != nil {
+		return
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(annotated))
+	resp.ContentLength = int64(len(annotated))
+	resp.Header.Set("Content-Length", strconv.Itoa(len(annotated)))
+}
+
+// withEndpoints makes client send its requests to the endpoints of pool
+// instead of only to its own endpoint, which must be the pool's primary.
+func (h *contextHTTPClient) withEndpoints(client *casdoorsdk.Client, pool *endpointPool) {
+	id := strconv.FormatUint(h.lastID.Add(1), 10)
+
+	h.mu.Lock()
+	h.pools[id] = pool
+	h.mu.Unlock()
+
+	if client.CustomHeaders == nil {
+		client.CustomHeaders = make(map[string]string, 1)
+	}
+	client.CustomHeaders[endpointsHeader] = id
+}
@@ -0,0 +1,164 @@
+// Copyright (c) HashiCorp, Inc.
+
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+const (
+	// loginModeCredentials logs in only to fetch the application's client ID,
+	// secret and certificate, which are then used for all calls.
+	loginModeCredentials = "credentials"
+	// loginModeSession makes all calls with the session of the login.
+	loginModeSession = "session"
+
+	// sessionHeader tags the requests of a client that authenticates with a
+	// login session, so that contextHTTPClient can find the session. It is
+	// removed before the request is sent.
+	sessionHeader = "X-Casdoor-Provider-Session"
+)
+
+// loginSession is a Casdoor login session, kept in a cookie jar. Requests
+// made with it carry the session cookie instead of application credentials,
+// and Casdoor logging the session out, e.g. because it expired, logs in again.
+type loginSession struct {
+	login  *passwordLogin
+	client *http.Client
+
+	// mu serializes logins.
+	mu sync.Mutex
+	// generation counts the logins, so that requests that failed with the
+	// same expired session log in again only once.
+	generation uint64
+}
+
+// newLoginSession logs in and returns the session.
+func newLoginSession(ctx context.Context, login *passwordLogin) (*loginSession, error) {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cookie jar: %w", err)
+	}
+
+	s := &loginSession{
+		login: login,
+		client: &http.Client{
+			Jar:     jar,
+			Timeout: 30 * time.Second,
+		},
+	}
+	if err := s.relogin(ctx, 0); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// relogin logs in again, unless another request already did since the
+// login with the given generation.
+func (s *loginSession) relogin(ctx context.Context, generation uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.generation != generation {
+		return nil
+	}
+	if err := s.login.login(ctx, s.client); err != nil {
+		return err
+	}
+	s.generation++
+
+	return nil
+}
+
+// do sends req with the session cookie instead of the credentials the SDK
+// set. If Casdoor no longer accepts the session, it logs in again and
+// resends req once.
+func (s *loginSession) do(req *http.Request) (*http.Response, error) {
+	req.Header.Del("Authorization")
+
+	s.mu.Lock()
+	generation := s.generation
+	s.mu.Unlock()
+
+	resp, err := s.client.Do(req)
+	if err != nil || !sessionRejected(resp) {
+		return resp, err
+	}
+	_ = resp.Body.Close()
+
+	tflog.Debug(req.Context(), "Casdoor rejected the login session, logging in again", map[string]any{
+		"endpoint": s.login.endpoint,
+		"username": s.login.organization + "/" + s.login.username,
+	})
+
+	if err := s.relogin(req.Context(), generation); err != nil {
+		return nil, fmt.Errorf("logging in again after the session expired: %w", err)
+	}
+
+	// The cookie jar added the rejected session cookie to req.
+	retry := req.Clone(req.Context())
+	retry.Header.Del("Cookie")
+	if req.Body != nil {
+		if req.GetBody == nil {
+			return nil, fmt.Errorf("cannot resend %s request after logging in again", req.URL.Path)
+		}
+		if retry.Body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.client.Do(retry)
+}
+
+// sessionRejected reports whether Casdoor answered as it does to a request
+// without a valid session. The response body is kept readable.
+func sessionRejected(resp *http.Response) bool {
+	if resp.StatusCode == http.StatusUnauthorized {
+		return true
+	}
+
+	content, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(content))
+	if err != nil {
+		return false
+	}
+
+	var result struct {
+		Status string `json:"status"`
+		Msg    string `json:"msg"`
+	}
+	if err := json.Unmarshal(content, &result); err != nil || result.Status != "error" {
+		return false
+	}
+
+	return result.Msg == "Please login first" || result.Msg == "Unauthorized operation"
+}
+
+// withSession makes client authenticate with session instead of with its
+// client ID and secret.
+func (h *contextHTTPClient) withSession(client *casdoorsdk.Client, session *loginSession) {
+	id := strconv.FormatUint(h.lastID.Add(1), 10)
+
+	h.mu.Lock()
+	h.sessions[id] = session
+	h.mu.Unlock()
+
+	if client.CustomHeaders == nil {
+		client.CustomHeaders = make(map[string]string, 1)
+	}
+	client.CustomHeaders[sessionHeader] = id
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
 	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
@@ -24,24 +25,28 @@ var (
 	_ resource.Resource                = &EnforcerResource{}
 	_ resource.ResourceWithConfigure   = &EnforcerResource{}
 	_ resource.ResourceWithImportState = &EnforcerResource{}
+	_ resource.ResourceWithModifyPlan  = &EnforcerResource{}
 )
 
 type EnforcerResource struct {
-	client *casdoorsdk.Client
+	client       casdoorClient
+	providerData *CasdoorProviderData
 }
 
 type EnforcerResourceModel struct {
-	ID          types.String `tfsdk:"id"`
-	Owner       types.String `tfsdk:"owner"`
-	Name        types.String `tfsdk:"name"`
-	CreatedTime types.String `tfsdk:"created_time"`
-	UpdatedTime types.String `tfsdk:"updated_time"`
-	ModelCfg    types.Map    `tfsdk:"model_cfg"`
-	DisplayName types.String `tfsdk:"display_name"`
-	Description types.String `tfsdk:"description"`
-	Model       types.String `tfsdk:"model"`
-	Adapter     types.String `tfsdk:"adapter"`
-	IsEnabled   types.Bool   `tfsdk:"is_enabled"`
+	ID            types.String   `tfsdk:"id"`
+	Owner         types.String   `tfsdk:"owner"`
+	Name          types.String   `tfsdk:"name"`
+	CreatedTime   types.String   `tfsdk:"created_time"`
+	UpdatedTime   types.String   `tfsdk:"updated_time"`
+	ModelCfg      types.Map      `tfsdk:"model_cfg"`
+	DisplayName   types.String   `tfsdk:"display_name"`
+	Description   types.String   `tfsdk:"description"`
+	Model         types.String   `tfsdk:"model"`
+	Adapter       types.String   `tfsdk:"adapter"`
+	IsEnabled     types.Bool     `tfsdk:"is_enabled"`
+	AdoptExisting types.Bool     `tfsdk:"adopt_existing"`
+	Timeouts      timeouts.Value `tfsdk:"timeouts"`
 }
 
 func NewEnforcerResource() resource.Resource {
@@ -52,7 +57,7 @@ func (r *EnforcerResource) Metadata(_ context.Context, req resource.MetadataRequ
 	resp.TypeName = req.ProviderTypeName + "_enforcer"
 }
 
-func (r *EnforcerResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+func (r *EnforcerResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		Description: "Manages a Casdoor Casbin enforcer.",
 		Attributes: map[string]schema.Attribute{
@@ -63,13 +68,7 @@ func (r *EnforcerResource) Schema(_ context.Context, _ resource.SchemaRequest, r
 					stringplanmodifier.UseStateForUnknown(),
 				},
 			},
-			"owner": schema.StringAttribute{
-				Description: "The organization that owns this enforcer.",
-				Required:    true,
-				PlanModifiers: []planmodifier.String{
-					stringplanmodifier.RequiresReplace(),
-				},
-			},
+			"owner": ownerAttribute("enforcer"),
 			"name": schema.StringAttribute{
 				Description: "The unique name of the enforcer.",
 				Required:    true,
@@ -126,6 +125,15 @@ func (r *EnforcerResource) Schema(_ context.Context, _ resource.SchemaRequest, r
 				Computed:    true,
 				Default:     booldefault.StaticBool(false),
 			},
+			"adopt_existing": adoptExistingAttribute("enforcer"),
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Read:   true,
+				Update: true,
+				Delete: true,
+			}),
 		},
 	}
 }
@@ -135,16 +143,26 @@ func (r *EnforcerResource) Configure(_ context.Context, req resource.ConfigureRe
 		return
 	}
 
-	client, ok := req.ProviderData.(*casdoorsdk.Client)
+	data, ok := req.ProviderData.(*CasdoorProviderData)
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Resource Configure Type",
-			fmt.Sprintf("Expected *casdoorsdk.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected *CasdoorProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 		return
 	}
 
-	r.client = client
+	r.client = data.Client
+	r.providerData = data
+}
+
+func (r *EnforcerResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	planDefaultOwner(ctx, req, resp, r.providerData)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	planAdoption(ctx, req, resp, r.providerData, "enforcer", r.Read)
 }
 
 func enforcerPlanToSDK(ctx context.Context, plan EnforcerResourceModel, createdTime, updatedTime string) (*casdoorsdk.Enforcer, diag.Diagnostics) {
@@ -174,6 +192,10 @@ func enforcerPlanToSDK(ctx context.Context, plan EnforcerResourceModel, createdT
 }
 
 func (r *EnforcerResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	if checkUnconfigured(r.client, &resp.Diagnostics) {
+		return
+	}
+
 	var plan EnforcerResourceModel
 
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
@@ -181,6 +203,12 @@ func (r *EnforcerResource) Create(ctx context.Context, req resource.CreateReques
 		return
 	}
 
+	ctx, done := startOperation(ctx, plan.Timeouts.Create, defaultOperationTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	createdTime := plan.CreatedTime.ValueString()
 	if createdTime == "" {
 		createdTime = time.Now().UTC().Format(time.RFC3339)
@@ -192,13 +220,20 @@ func (r *EnforcerResource) Create(ctx context.Context, req resource.CreateReques
 		return
 	}
 
-	ok, err := r.client.AddEnforcer(enforcer)
-	if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("creating enforcer %q", plan.Name.ValueString())) {
+	adopted := adoptOnCreate(ctx, r.providerData, req.Plan, req.Config, &resp.Diagnostics, "enforcer", plan.Owner.ValueString()+"/"+plan.Name.ValueString(), enforcer, r.client.Enforcers())
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
+	if !adopted {
+		ok, err := r.client.Enforcers().Add(ctx, enforcer)
+		if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("creating enforcer %q", plan.Name.ValueString())) {
+			return
+		}
+	}
+
 	// Read back the enforcer to get server-generated values.
-	createdEnforcer, err := r.client.GetEnforcer(plan.Owner.ValueString() + "/" + plan.Name.ValueString())
+	createdEnforcer, err := r.client.Enforcers().Get(ctx, plan.Owner.ValueString()+"/"+plan.Name.ValueString())
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Reading Enforcer",
@@ -230,6 +265,10 @@ func (r *EnforcerResource) Create(ctx context.Context, req resource.CreateReques
 }
 
 func (r *EnforcerResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	if r.client == nil {
+		return
+	}
+
 	var state EnforcerResourceModel
 
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
@@ -237,7 +276,13 @@ func (r *EnforcerResource) Read(ctx context.Context, req resource.ReadRequest, r
 		return
 	}
 
-	enforcer, err := r.client.GetEnforcer(state.Owner.ValueString() + "/" + state.Name.ValueString())
+	ctx, done := startOperation(ctx, state.Timeouts.Read, defaultReadTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	enforcer, err := r.client.Enforcers().Get(ctx, state.Owner.ValueString()+"/"+state.Name.ValueString())
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Reading Enforcer",
@@ -275,30 +320,46 @@ func (r *EnforcerResource) Read(ctx context.Context, req resource.ReadRequest, r
 		return
 	}
 
+	resp.Diagnostics.Append(setObjectFingerprint(ctx, resp.Private, enforcer)...)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
 }
 
 func (r *EnforcerResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	var plan EnforcerResourceModel
+	if checkUnconfigured(r.client, &resp.Diagnostics) {
+		return
+	}
+
+	var plan, state EnforcerResourceModel
 
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, done := startOperation(ctx, plan.Timeouts.Update, defaultOperationTimeout, &resp.Diagnostics)
+	defer done()
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
+	if checkUpdateConflict(ctx, r.providerData, &resp.Diagnostics, req.Private, "enforcer", state.Owner.ValueString()+"/"+state.Name.ValueString(), r.client.Enforcers().Get) {
+		return
+	}
+
 	enforcer, diags := enforcerPlanToSDK(ctx, plan, plan.CreatedTime.ValueString(), plan.UpdatedTime.ValueString())
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	ok, err := r.client.UpdateEnforcer(enforcer)
+	ok, err := r.client.Enforcers().Update(ctx, enforcer)
 	if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("updating enforcer %q", plan.Name.ValueString())) {
 		return
 	}
 
 	// Read back to get server-updated fields.
-	updatedEnforcer, err := r.client.GetEnforcer(plan.Owner.ValueString() + "/" + plan.Name.ValueString())
+	updatedEnforcer, err := r.client.Enforcers().Get(ctx, plan.Owner.ValueString()+"/"+plan.Name.ValueString())
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Reading Enforcer",
@@ -315,10 +376,15 @@ func (r *EnforcerResource) Update(ctx context.Context, req resource.UpdateReques
 	}
 
 	plan.ID = types.StringValue(plan.Owner.ValueString() + "/" + plan.Name.ValueString())
+	resp.Diagnostics.Append(clearObjectFingerprint(ctx, resp.Private)...)
 	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
 }
 
 func (r *EnforcerResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	if checkUnconfigured(r.client, &resp.Diagnostics) {
+		return
+	}
+
 	var state EnforcerResourceModel
 
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
@@ -326,12 +392,18 @@ func (r *EnforcerResource) Delete(ctx context.Context, req resource.DeleteReques
 		return
 	}
 
+	ctx, done := startOperation(ctx, state.Timeouts.Delete, defaultOperationTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	enforcer := &casdoorsdk.Enforcer{
 		Owner: state.Owner.ValueString(),
 		Name:  state.Name.ValueString(),
 	}
 
-	ok, err := r.client.DeleteEnforcer(enforcer)
+	ok, err := r.client.Enforcers().Delete(ctx, enforcer)
 	if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("deleting enforcer %q", state.Name.ValueString())) {
 		return
 	}
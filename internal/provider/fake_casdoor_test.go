@@ -0,0 +1,685 @@
+// Copyright (c) HashiCorp, Inc.
+
+package provider
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+)
+
+const (
+	// Credentials of the application seeded into the fake Casdoor server.
+	fakeClientID     = "5d2b6f0a8c4e1f3a7b9d"
+	fakeClientSecret = "0c6e8a2f4b1d3e5f7a9c0b2d4e6f8a1c3e5b7d9f"
+
+	fakeSessionCookie = "casdoor_session_id"
+)
+
+// fakeCasdoor is an in-memory stand-in for the Casdoor API, served by an
+// httptest server. It implements the generic get-, add-, update- and delete-
+// endpoints for every object kind, login, health, resource upload and the
+// JSON Web Key Set, with
+// the quirks of the real server that the provider depends on, so that the
+// acceptance tests can run offline in short mode. Objects are kept as the
+// JSON the client sent, so unknown fields survive a round trip.
+type fakeCasdoor struct {
+	server *httptest.Server
+
+	mu sync.Mutex
+	// objects maps a kind, e.g. "user", to its objects by "owner/name".
+	objects map[string]map[string]map[string]any
+	// sessions maps a session cookie to the id of the user it belongs to.
+	sessions map[string]string
+	// mfaSessions maps a session cookie to the id of a user whose login is
+	// waiting for the second factor.
+	mfaSessions map[string]string
+}
+
+// newFakeCasdoor starts a fake Casdoor server seeded with the objects a
+// fresh Casdoor installation starts with.
+func newFakeCasdoor() *fakeCasdoor {
+	f := &fakeCasdoor{
+		objects:     make(map[string]map[string]map[string]any),
+		sessions:    make(map[string]string),
+		mfaSessions: make(map[string]string),
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	f.seed("organization", map[string]any{
+		"owner": "admin", "name": "built-in", "createdTime": now,
+		"displayName": "Built-in Organization", "passwordType": "plain",
+	})
+	f.seed("application", map[string]any{
+		"owner": "admin", "name": "app-built-in", "createdTime": now,
+		"displayName": "Casdoor", "organization": "built-in", "cert": "cert-built-in",
+		"enablePassword": true, "clientId": fakeClientID, "clientSecret": fakeClientSecret,
+	})
+	f.seed("cert", map[string]any{
+		"owner": "admin", "name": "cert-built-in", "createdTime": now,
+		"displayName": "Built-in Cert", "scope": "JWT", "type": "x509",
+		"cryptoAlgorithm": "RS256", "bitSize": 4096, "expireInYears": 20,
+		"certificate": testJwtPublicKey,
+	})
+	f.seed("model", map[string]any{
+		"owner": "built-in", "name": "user-model-built-in", "createdTime": now,
+		"displayName": "Built-in Model",
+		"modelText": "[request_definition]\nr = sub, obj, act\n\n[policy_definition]\np = sub, obj, act\n\n" +
+			"[role_definition]\ng = _, _\n\n[policy_effect]\ne = some(where (p.eft == allow))\n\n" +
+			"[matchers]\nm = g(r.sub, p.sub) && r.obj == p.obj && r.act == p.act",
+	})
+	f.seed("user", map[string]any{
+		"owner": "built-in", "name": "admin", "createdTime": now, "id": fakeID(),
+		"type": "normal-user", "password": defaultAdminPassword, "displayName": "Admin",
+		"isAdmin": true, "signupApplication": "app-built-in",
+	})
+
+	f.server = httptest.NewServer(f)
+	return f
+}
+
+// config returns the test configuration for the seeded application.
+func (f *fakeCasdoor) config() CasdoorTestConfig {
+	return CasdoorTestConfig{
+		Endpoint:         f.server.URL,
+		ClientID:         fakeClientID,
+		ClientSecret:     fakeClientSecret,
+		Certificate:      testJwtPublicKey,
+		OrganizationName: "built-in",
+		ApplicationName:  "app-built-in",
+	}
+}
+
+// expireSessions logs every login session out, as Casdoor does once they
+// expire.
+func (f *fakeCasdoor) expireSessions() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	clear(f.sessions)
+}
+
+// Close shuts the server down.
+func (f *fakeCasdoor) Close() {
+	f.server.Close()
+}
+
+// object returns a copy of the stored object of kind with id, or nil.
+func (f *fakeCasdoor) object(kind, id string) map[string]any {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return maps.Clone(f.store(kind)[id])
+}
+
+// set sets field of the stored object of kind with id, as a change made
+// outside Terraform.
+func (f *fakeCasdoor) set(kind, id, field string, value any) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.store(kind)[id][field] = value
+}
+
+func (f *fakeCasdoor) seed(kind string, obj map[string]any) {
+	f.store(kind)[objectKey(obj)] = obj
+}
+
+func (f *fakeCasdoor) store(kind string) map[string]map[string]any {
+	if f.objects[kind] == nil {
+		f.objects[kind] = make(map[string]map[string]any)
+	}
+	return f.objects[kind]
+}
+
+// fakeResponse is the envelope every Casdoor API response is wrapped in.
+type fakeResponse struct {
+	Status string `json:"status"`
+	Msg    string `json:"msg"`
+	Data   any    `json:"data"`
+	Data2  any    `json:"data2"`
+}
+
+func (f *fakeCasdoor) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if document, ok := strings.CutPrefix(r.URL.Path, "/.well-known/"); ok {
+		f.wellKnown(w, r, document)
+		return
+	}
+
+	action, ok := strings.CutPrefix(r.URL.Path, "/api/")
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var resp fakeResponse
+	var err error
+
+	switch {
+	case action == "health":
+		resp = fakeResponse{Status: "ok"}
+	case action == "login":
+		resp, err = f.login(w, r)
+	case !f.authorized(r):
+		err = fmt.Errorf("Unauthorized operation")
+	case action == "get-organization-applications":
+		resp, err = f.organizationApplications(r)
+	case action == "upload-resource":
+		resp, err = f.uploadResource(r)
+	case action == "add-transaction":
+		resp, err = f.addTransaction(r)
+	case strings.HasPrefix(action, "get-") && r.URL.Query().Has("id"):
+		resp, err = f.get(strings.TrimPrefix(action, "get-"), r.URL.Query().Get("id"))
+	case strings.HasPrefix(action, "get-") && strings.HasSuffix(action, "s"):
+		resp, err = f.list(strings.TrimSuffix(strings.TrimPrefix(action, "get-"), "s"), r)
+	case strings.HasPrefix(action, "add-"):
+		resp, err = f.add(strings.TrimPrefix(action, "add-"), r)
+	case strings.HasPrefix(action, "update-"):
+		resp, err = f.update(strings.TrimPrefix(action, "update-"), r)
+	case strings.HasPrefix(action, "delete-"):
+		resp, err = f.delete(strings.TrimPrefix(action, "delete-"), r)
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		resp = fakeResponse{Status: "error", Msg: err.Error()}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// wellKnown serves the OpenID discovery document and the JSON Web Key Set,
+// which holds a key for every cert with the JWT scope, its name as key ID.
+func (f *fakeCasdoor) wellKnown(w http.ResponseWriter, r *http.Request, document string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var resp any
+	switch document {
+	case "openid-configuration":
+		resp = map[string]any{
+			"issuer":   f.server.URL,
+			"jwks_uri": f.server.URL + "/.well-known/jwks",
+		}
+	case "jwks":
+		keys := []map[string]any{}
+		certs := make([]map[string]any, 0, len(f.objects["cert"]))
+		for _, cert := range f.objects["cert"] {
+			certs = append(certs, cert)
+		}
+		sortObjects(certs)
+		for _, cert := range certs {
+			certificate, _ := cert["certificate"].(string)
+			block, _ := pem.Decode([]byte(certificate))
+			if cert["scope"] != "JWT" || block == nil {
+				continue
+			}
+			keys = append(keys, map[string]any{
+				"kid": cert["name"],
+				"kty": "RSA",
+				"use": "sig",
+				"x5c": []string{base64.StdEncoding.EncodeToString(block.Bytes)},
+			})
+		}
+		resp = map[string]any{"keys": keys}
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// authorized accepts the client credentials of any application, as the SDK
+// sends them, the access key and secret of any user, or the session cookie
+// set by login.
+func (f *fakeCasdoor) authorized(r *http.Request) bool {
+	if accessKey := r.URL.Query().Get("accessKey"); accessKey != "" {
+		for _, user := range f.store("user") {
+			if user["accessKey"] == accessKey && user["accessSecret"] == r.URL.Query().Get("accessSecret") {
+				return true
+			}
+		}
+		return false
+	}
+
+	if cookie, err := r.Cookie(fakeSessionCookie); err == nil {
+		if _, ok := f.sessions[cookie.Value]; ok {
+			return true
+		}
+	}
+
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		return false
+	}
+	for _, app := range f.store("application") {
+		if app["clientId"] == clientID && app["clientSecret"] == clientSecret {
+			return true
+		}
+	}
+
+	return false
+}
+
+// login logs a user in with a password. Like Casdoor, a user with a TOTP
+// secret is asked for the second factor with mfaRequired first, and the
+// passcode is then posted again with the same session.
+func (f *fakeCasdoor) login(w http.ResponseWriter, r *http.Request) (fakeResponse, error) {
+	var form struct {
+		Organization string `json:"organization"`
+		Username     string `json:"username"`
+		Password     string `json:"password"`
+		Passcode     string `json:"passcode"`
+		MfaType      string `json:"mfaType"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&form); err != nil {
+		return fakeResponse{}, err
+	}
+
+	id := form.Organization + "/" + form.Username
+	user := f.store("user")[id]
+	if user == nil || user["password"] != form.Password {
+		return fakeResponse{}, fmt.Errorf("password or code is incorrect")
+	}
+
+	if secret, _ := user["totpSecret"].(string); secret != "" {
+		if form.Passcode == "" {
+			session := fakeID()
+			f.mfaSessions[session] = id
+			http.SetCookie(w, &http.Cookie{Name: fakeSessionCookie, Value: session, Path: "/"})
+			return fakeResponse{Status: "ok", Data: mfaRequired}, nil
+		}
+
+		cookie, err := r.Cookie(fakeSessionCookie)
+		if err != nil || f.mfaSessions[cookie.Value] != id {
+			return fakeResponse{}, fmt.Errorf("the MFA session has expired, please log in again")
+		}
+		if form.MfaType != "app" || !validTOTP(secret, form.Passcode) {
+			return fakeResponse{}, fmt.Errorf("Invalid multi-factor authentication code")
+		}
+		delete(f.mfaSessions, cookie.Value)
+	}
+
+	session := fakeID()
+	f.sessions[session] = id
+	http.SetCookie(w, &http.Cookie{Name: fakeSessionCookie, Value: session, Path: "/"})
+
+	return fakeResponse{Status: "ok", Data: id}, nil
+}
+
+// validTOTP accepts the code of the current period and of the ones next to
+// it, allowing for clock skew.
+func validTOTP(secret, code string) bool {
+	now := time.Now()
+	for _, skew := range []time.Duration{-totpPeriod, 0, totpPeriod} {
+		if expected, err := totpCode(secret, now.Add(skew)); err == nil && expected == code {
+			return true
+		}
+	}
+	return false
+}
+
+// lookup finds the object with id. Like Casdoor, a cert that an organization
+// does not have is looked up among the global certs owned by admin, and a
+// model among the built-in models.
+func (f *fakeCasdoor) lookup(kind, id string) (map[string]any, error) {
+	owner, name, ok := strings.Cut(id, "/")
+	if !ok {
+		return nil, fmt.Errorf("GetOwnerAndNameFromId() error, wrong token count for ID: %s", id)
+	}
+
+	objects := f.store(kind)
+	if obj := objects[id]; obj != nil {
+		return obj, nil
+	}
+
+	switch {
+	case kind == "cert" && owner != "admin":
+		return objects["admin/"+name], nil
+	case kind == "model" && owner != "built-in":
+		return objects["built-in/"+name], nil
+	}
+
+	return nil, nil
+}
+
+func (f *fakeCasdoor) get(kind, id string) (fakeResponse, error) {
+	obj, err := f.lookup(kind, id)
+	if err != nil {
+		return fakeResponse{}, err
+	}
+	if obj == nil {
+		// Casdoor answers a missing object with null data, not an error.
+		return fakeResponse{Status: "ok"}, nil
+	}
+
+	return fakeResponse{Status: "ok", Data: obj}, nil
+}
+
+func (f *fakeCasdoor) list(kind string, r *http.Request) (fakeResponse, error) {
+	query := r.URL.Query()
+	owner := query.Get("owner")
+	field, value := query.Get("field"), query.Get("value")
+
+	objects := []map[string]any{}
+	for _, obj := range f.store(kind) {
+		// get-certs answers with the certificates shared by admin as well.
+		if owner != "" && obj["owner"] != owner && (kind != "cert" || obj["owner"] != "admin") {
+			continue
+		}
+		if field != "" && !strings.Contains(fmt.Sprint(obj[field]), value) {
+			continue
+		}
+		objects = append(objects, obj)
+	}
+	sortObjects(objects)
+
+	// Pages are numbered from 1, as in Casdoor, and come with the total.
+	if query.Has("p") && query.Has("pageSize") {
+		page, _ := strconv.Atoi(query.Get("p"))
+		pageSize, _ := strconv.Atoi(query.Get("pageSize"))
+		start := min(max(page-1, 0)*pageSize, len(objects))
+		end := min(start+pageSize, len(objects))
+		return fakeResponse{Status: "ok", Data: objects[start:end], Data2: len(objects)}, nil
+	}
+
+	return fakeResponse{Status: "ok", Data: objects}, nil
+}
+
+func (f *fakeCasdoor) organizationApplications(r *http.Request) (fakeResponse, error) {
+	organization := r.URL.Query().Get("organization")
+
+	apps := []map[string]any{}
+	for _, app := range f.store("application") {
+		if app["organization"] == organization {
+			apps = append(apps, app)
+		}
+	}
+	sortObjects(apps)
+
+	return fakeResponse{Status: "ok", Data: apps}, nil
+}
+
+func (f *fakeCasdoor) add(kind string, r *http.Request) (fakeResponse, error) {
+	obj, err := decodeObject(r.Body)
+	if err != nil {
+		return fakeResponse{}, err
+	}
+
+	id := objectKey(obj)
+	objects := f.store(kind)
+	if objects[id] != nil {
+		return fakeResponse{}, fmt.Errorf("UNIQUE constraint failed: %s.owner, %s.name", kind, kind)
+	}
+
+	if obj["createdTime"] == nil || obj["createdTime"] == "" {
+		obj["createdTime"] = time.Now().UTC().Format(time.RFC3339)
+	}
+	switch kind {
+	case "application":
+		if obj["clientId"] == nil || obj["clientId"] == "" {
+			obj["clientId"] = randomHex(10)
+		}
+		if obj["clientSecret"] == nil || obj["clientSecret"] == "" {
+			obj["clientSecret"] = randomHex(20)
+		}
+	case "user":
+		if obj["id"] == nil || obj["id"] == "" {
+			obj["id"] = fakeID()
+		}
+	}
+
+	objects[id] = obj
+	return fakeResponse{Status: "ok", Data: "Affected"}, nil
+}
+
+// fakeUpdatedTimeKinds are the kinds whose updatedTime Casdoor sets on every
+// update.
+var fakeUpdatedTimeKinds = map[string]bool{
+	"enforcer":   true,
+	"group":      true,
+	"invitation": true,
+	"model":      true,
+	"user":       true,
+}
+
+// update replaces the object with id by the posted one, which may have been
+// renamed. If the columns parameter is set, only the listed columns, given
+// as database column names, are written.
+func (f *fakeCasdoor) update(kind string, r *http.Request) (fakeResponse, error) {
+	obj, err := decodeObject(r.Body)
+	if err != nil {
+		return fakeResponse{}, err
+	}
+
+	id := r.URL.Query().Get("id")
+	objects := f.store(kind)
+	existing := objects[id]
+	if existing == nil {
+		return fakeResponse{Status: "ok", Data: "Unaffected"}, nil
+	}
+
+	if columns := r.URL.Query().Get("columns"); columns != "" {
+		updated := make(map[string]any, len(existing))
+		for k, v := range existing {
+			updated[k] = v
+		}
+		for _, column := range strings.Split(columns, ",") {
+			key := attributeJSONKey(column)
+			updated[key] = obj[key]
+		}
+		obj = updated
+	}
+
+	newID := objectKey(obj)
+	if newID != id && objects[newID] != nil {
+		return fakeResponse{}, fmt.Errorf("UNIQUE constraint failed: %s.owner, %s.name", kind, kind)
+	}
+	if fakeUpdatedTimeKinds[kind] {
+		obj["updatedTime"] = time.Now().UTC().Format(time.RFC3339)
+	}
+
+	delete(objects, id)
+	objects[newID] = obj
+	return fakeResponse{Status: "ok", Data: "Affected"}, nil
+}
+
+func (f *fakeCasdoor) delete(kind string, r *http.Request) (fakeResponse, error) {
+	obj, err := decodeObject(r.Body)
+	if err != nil {
+		return fakeResponse{}, err
+	}
+
+	id := objectKey(obj)
+	objects := f.store(kind)
+	if objects[id] == nil {
+		return fakeResponse{Status: "ok", Data: "Unaffected"}, nil
+	}
+
+	delete(objects, id)
+	return fakeResponse{Status: "ok", Data: "Affected"}, nil
+}
+
+// uploadResource stores the uploaded file as a resource named after its
+// object key and answers with the file URL and the name, like Casdoor with a
+// local storage provider.
+func (f *fakeCasdoor) uploadResource(r *http.Request) (fakeResponse, error) {
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		return fakeResponse{}, err
+	}
+	defer func() { _ = file.Close() }()
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return fakeResponse{}, err
+	}
+
+	query := r.URL.Query()
+	owner, user, fullFilePath := query.Get("owner"), query.Get("user"), query.Get("fullFilePath")
+	name := path.Join("resource", owner, user, fullFilePath)
+	fileURL := f.server.URL + "/files/" + name
+
+	createdTime := query.Get("createdTime")
+	if createdTime == "" {
+		createdTime = time.Now().UTC().Format(time.RFC3339)
+	}
+
+	f.store("resource")[owner+"/"+name] = map[string]any{
+		"owner":       owner,
+		"name":        name,
+		"createdTime": createdTime,
+		"user":        user,
+		"provider":    "provider-storage-built-in",
+		"application": query.Get("application"),
+		"tag":         query.Get("tag"),
+		"parent":      query.Get("parent"),
+		"fileName":    path.Base(fullFilePath),
+		"fileType":    strings.TrimPrefix(path.Ext(header.Filename), "."),
+		"fileFormat":  path.Ext(fullFilePath),
+		"fileSize":    len(content),
+		"url":         fileURL,
+		"description": query.Get("description"),
+	}
+
+	return fakeResponse{Status: "ok", Data: fileURL, Data2: name}, nil
+}
+
+// addTransaction records a transaction, naming it if it has no name, and adds
+// the amount of a user transaction to the user's balance. Casdoor returns the
+// name of the transaction.
+func (f *fakeCasdoor) addTransaction(r *http.Request) (fakeResponse, error) {
+	obj, err := decodeObject(r.Body)
+	if err != nil {
+		return fakeResponse{}, err
+	}
+
+	owner, _ := obj["owner"].(string)
+	name, _ := obj["name"].(string)
+	if name == "" {
+		name = "transaction_" + randomHex(3)
+		obj["name"] = name
+	}
+	if f.store("transaction")[owner+"/"+name] != nil {
+		return fakeResponse{}, fmt.Errorf("UNIQUE constraint failed: transaction.owner, transaction.name")
+	}
+
+	if obj["tag"] == "User" {
+		user, err := f.lookup("user", owner+"/"+fmt.Sprint(obj["user"]))
+		if err != nil {
+			return fakeResponse{}, err
+		}
+		if user == nil {
+			return fakeResponse{}, fmt.Errorf("the user: %s/%v does not exist", owner, obj["user"])
+		}
+		// Objects are decoded with json.Number.
+		balance, _ := strconv.ParseFloat(fmt.Sprint(user["balance"]), 64)
+		amount, _ := strconv.ParseFloat(fmt.Sprint(obj["amount"]), 64)
+		user["balance"] = json.Number(strconv.FormatFloat(balance+amount, 'f', -1, 64))
+	}
+
+	if obj["createdTime"] == nil || obj["createdTime"] == "" {
+		obj["createdTime"] = time.Now().UTC().Format(time.RFC3339)
+	}
+	f.store("transaction")[owner+"/"+name] = obj
+
+	return fakeResponse{Status: "ok", Data: name}, nil
+}
+
+func decodeObject(body io.Reader) (map[string]any, error) {
+	content, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+
+	var obj map[string]any
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+	if err := decoder.Decode(&obj); err != nil {
+		return nil, err
+	}
+
+	return obj, nil
+}
+
+func objectKey(obj map[string]any) string {
+	return fmt.Sprintf("%v/%v", obj["owner"], obj["name"])
+}
+
+func sortObjects(objects []map[string]any) {
+	sort.Slice(objects, func(i, j int) bool {
+		return objectKey(objects[i]) < objectKey(objects[j])
+	})
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// fakeID returns a random UUID-formatted id.
+func fakeID() string {
+	s := randomHex(16)
+	return s[:8] + "-" + s[8:12] + "-" + s[12:16] + "-" + s[16:20] + "-" + s[20:]
+}
+
+// newFakeSDKClient starts a fake Casdoor server for the test and returns the
+// provider's SDK client on it. Resources configured with the client can be
+// unit-tested against the fake without Terraform, and so without TF_ACC.
+func newFakeSDKClient(t *testing.T) (casdoorClient, *fakeCasdoor) {
+	t.Helper()
+
+	fake := newFakeCasdoor()
+	t.Cleanup(fake.Close)
+
+	config := fake.config()
+	client := casdoorsdk.NewClient(
+		config.Endpoint,
+		config.ClientID,
+		config.ClientSecret,
+		config.Certificate,
+		config.OrganizationName,
+		config.ApplicationName,
+	)
+
+	return newSDKClient(client), fake
+}
+
+// setupFakeCasdoor starts a fake Casdoor server for the test and returns the
+// test environment.
+func setupFakeCasdoor(t *testing.T) *TestEnv {
+	t.Helper()
+
+	fake := newFakeCasdoor()
+	t.Logf("Using fake Casdoor at %s", fake.server.URL)
+
+	return &TestEnv{
+		Config: fake.config(),
+		Fake:   fake,
+	}
+}
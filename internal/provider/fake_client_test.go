@@ -0,0 +1,580 @@
+// Copyright (c) HashiCorp, Inc.
+
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math/big"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// fakeClient implements casdoorClient on objects kept in memory, without
+// going through HTTP or the SDK. Resources configured with it can be
+// unit-tested by calling their methods directly. Every write is recorded in
+// calls. Unlike the fake Casdoor server, it has none of Casdoor's quirks.
+type fakeClient struct {
+	organization string
+	readOnly     bool
+
+	// objects holds the objects as their JSON, by kind and ID.
+	objects map[string]map[string]map[string]any
+	calls   []fakeClientCall
+}
+
+// fakeClientCall is a write made through a fakeClient.
+type fakeClientCall struct {
+	// Action is the Casdoor endpoint the call stands for, e.g. "update-user".
+	Action string
+	// ID is the ID of the object written.
+	ID string
+	// Columns are the columns written by UpdateUserColumns.
+	Columns []string
+}
+
+var _ casdoorClient = &fakeClient{}
+
+// newFakeClient returns an empty fakeClient configured with the built-in
+// organization.
+func newFakeClient() *fakeClient {
+	return &fakeClient{organization: "built-in", objects: map[string]map[string]map[string]any{}}
+}
+
+// object returns the stored object of kind with id, or nil.
+func (c *fakeClient) object(kind, id string) map[string]any {
+	return c.objects[kind][id]
+}
+
+// put stores obj as the object of kind with its own ID.
+func (c *fakeClient) put(kind string, obj map[string]any) {
+	if c.objects[kind] == nil {
+		c.objects[kind] = map[string]map[string]any{}
+	}
+	c.objects[kind][fakeObjectID(obj)] = obj
+}
+
+// find returns the objects of kind owned by owner, or all of them if owner
+// is empty, whose field has value, sorted by ID.
+func (c *fakeClient) find(kind, owner, field, value string) []map[string]any {
+	var found []map[string]any
+	for _, id := range slices.Sorted(maps.Keys(c.objects[kind])) {
+		obj := c.objects[kind][id]
+		if owner != "" && obj["owner"] != owner {
+			continue
+		}
+		if field != "" && obj[field] != value {
+			continue
+		}
+		found = append(found, obj)
+	}
+
+	return found
+}
+
+func (c *fakeClient) record(action, id string, columns []string) {
+	c.calls = append(c.calls, fakeClientCall{Action: action, ID: id, Columns: columns})
+}
+
+// fakeObjects implements objectAPI for one kind of object of a fakeClient.
+type fakeObjects[T any] struct {
+	client *fakeClient
+	kind   string
+}
+
+func (o fakeObjects[T]) Get(_ context.Context, id string) (*T, error) {
+	if _, _, ok := strings.Cut(id, "/"); !ok {
+		return nil, fmt.Errorf("wrong token count for ID: %s", id)
+	}
+
+	return fromFakeObject[T](o.client.object(o.kind, id))
+}
+
+func (o fakeObjects[T]) List(_ context.Context, owner string) ([]*T, error) {
+	return fromFakeObjects[T](o.client.find(o.kind, owner, "", ""))
+}
+
+func (o fakeObjects[T]) Add(_ context.Context, obj *T) (bool, error) {
+	m, err := toFakeObject(obj)
+	if err != nil {
+		return false, err
+	}
+
+	id := fakeObjectID(m)
+	o.client.record("add-"+o.kind, id, nil)
+	if o.client.object(o.kind, id) != nil {
+		return false, fmt.Errorf("UNIQUE constraint failed: %s.owner, %s.name", o.kind, o.kind)
+	}
+	o.client.put(o.kind, m)
+
+	return true, nil
+}
+
+func (o fakeObjects[T]) Update(ctx context.Context, obj *T) (bool, error) {
+	m, err := toFakeObject(obj)
+	if err != nil {
+		return false, err
+	}
+
+	return o.client.UpdateByID(ctx, o.kind, fakeObjectID(m), obj)
+}
+
+func (o fakeObjects[T]) Delete(_ context.Context, obj *T) (bool, error) {
+	m, err := toFakeObject(obj)
+	if err != nil {
+		return false, err
+	}
+
+	id := fakeObjectID(m)
+	o.client.record("delete-"+o.kind, id, nil)
+	if o.client.object(o.kind, id) == nil {
+		return false, nil
+	}
+	delete(o.client.objects[o.kind], id)
+
+	return true, nil
+}
+
+func (c *fakeClient) Adapters() objectAPI[casdoorsdk.Adapter] {
+	return fakeObjects[casdoorsdk.Adapter]{c, "adapter"}
+}
+
+func (c *fakeClient) Applications() objectAPI[casdoorsdk.Application] {
+	return fakeObjects[casdoorsdk.Application]{c, "application"}
+}
+
+func (c *fakeClient) Certs() objectAPI[casdoorsdk.Cert] {
+	return fakeObjects[casdoorsdk.Cert]{c, "cert"}
+}
+
+func (c *fakeClient) Enforcers() objectAPI[casdoorsdk.Enforcer] {
+	return fakeObjects[casdoorsdk.Enforcer]{c, "enforcer"}
+}
+
+func (c *fakeClient) Groups() objectAPI[casdoorsdk.Group] {
+	return fakeObjects[casdoorsdk.Group]{c, "group"}
+}
+
+func (c *fakeClient) Invitations() objectAPI[casdoorsdk.Invitation] {
+	return fakeObjects[casdoorsdk.Invitation]{c, "invitation"}
+}
+
+func (c *fakeClient) Ldaps() objectAPI[casdoorsdk.Ldap] {
+	return fakeObjects[casdoorsdk.Ldap]{c, "ldap"}
+}
+
+func (c *fakeClient) Models() objectAPI[casdoorsdk.Model] {
+	return fakeObjects[casdoorsdk.Model]{c, "model"}
+}
+
+func (c *fakeClient) Organizations() objectAPI[casdoorsdk.Organization] {
+	return fakeObjects[casdoorsdk.Organization]{c, "organization"}
+}
+
+func (c *fakeClient) Permissions() objectAPI[casdoorsdk.Permission] {
+	return fakeObjects[casdoorsdk.Permission]{c, "permission"}
+}
+
+func (c *fakeClient) Plans() objectAPI[casdoorsdk.Plan] {
+	return fakeObjects[casdoorsdk.Plan]{c, "plan"}
+}
+
+func (c *fakeClient) Pricings() objectAPI[casdoorsdk.Pricing] {
+	return fakeObjects[casdoorsdk.Pricing]{c, "pricing"}
+}
+
+func (c *fakeClient) Products() objectAPI[casdoorsdk.Product] {
+	return fakeObjects[casdoorsdk.Product]{c, "product"}
+}
+
+func (c *fakeClient) Providers() objectAPI[casdoorsdk.Provider] {
+	return fakeObjects[casdoorsdk.Provider]{c, "provider"}
+}
+
+func (c *fakeClient) Resources() objectAPI[casdoorsdk.Resource] {
+	return fakeObjects[casdoorsdk.Resource]{c, "resource"}
+}
+
+func (c *fakeClient) Roles() objectAPI[casdoorsdk.Role] {
+	return fakeObjects[casdoorsdk.Role]{c, "role"}
+}
+
+func (c *fakeClient) Subscriptions() objectAPI[casdoorsdk.Subscription] {
+	return fakeObjects[casdoorsdk.Subscription]{c, "subscription"}
+}
+
+func (c *fakeClient) Syncers() objectAPI[casdoorsdk.Syncer] {
+	return fakeObjects[casdoorsdk.Syncer]{c, "syncer"}
+}
+
+func (c *fakeClient) Tokens() objectAPI[casdoorsdk.Token] {
+	return fakeObjects[casdoorsdk.Token]{c, "token"}
+}
+
+func (c *fakeClient) Transactions() objectAPI[casdoorsdk.Transaction] {
+	return fakeObjects[casdoorsdk.Transaction]{c, "transaction"}
+}
+
+func (c *fakeClient) Users() objectAPI[casdoorsdk.User] {
+	return fakeObjects[casdoorsdk.User]{c, "user"}
+}
+
+func (c *fakeClient) Webhooks() objectAPI[casdoorsdk.Webhook] {
+	return fakeObjects[casdoorsdk.Webhook]{c, "webhook"}
+}
+
+func (c *fakeClient) OrganizationApplications(_ context.Context, organization string) ([]*casdoorsdk.Application, error) {
+	return fromFakeObjects[casdoorsdk.Application](c.find("application", "", "organization", organization))
+}
+
+func (c *fakeClient) ListPayments(_ context.Context, owner, user string, _ int) ([]*casdoorsdk.Payment, error) {
+	return fakeUserRecords[casdoorsdk.Payment](c, "payment", owner, user)
+}
+
+func (c *fakeClient) ListOrders(_ context.Context, owner, user string, _ int) ([]*casdoorsdk.Order, error) {
+	return fakeUserRecords[casdoorsdk.Order](c, "order", owner, user)
+}
+
+func (c *fakeClient) ListTransactions(_ context.Context, owner, user string, _ int) ([]*casdoorsdk.Transaction, error) {
+	return fakeUserRecords[casdoorsdk.Transaction](c, "transaction", owner, user)
+}
+
+// fakeUserRecords lists the records of kind owned by owner, only those of
+// user if it is set.
+func fakeUserRecords[T any](c *fakeClient, kind, owner, user string) ([]*T, error) {
+	field := ""
+	if user != "" {
+		field = "user"
+	}
+
+	return fromFakeObjects[T](c.find(kind, owner, field, user))
+}
+
+func (c *fakeClient) AddTransaction(ctx context.Context, transaction *casdoorsdk.Transaction) (string, error) {
+	if transaction.Name == "" {
+		transaction.Name = fmt.Sprintf("transaction_%d", len(c.objects["transaction"])+1)
+	}
+	if _, err := c.Transactions().Add(ctx, transaction); err != nil {
+		return "", err
+	}
+
+	return transaction.Name, nil
+}
+
+func (c *fakeClient) UpdateUserColumns(_ context.Context, id string, user *casdoorsdk.User, columns []string) (bool, error) {
+	m, err := toFakeObject(user)
+	if err != nil {
+		return false, err
+	}
+
+	c.record("update-user", id, columns)
+	existing := c.object("user", id)
+	if existing == nil {
+		return false, nil
+	}
+
+	updated := maps.Clone(existing)
+	for _, column := range columns {
+		key := attributeJSONKey(column)
+		updated[key] = m[key]
+	}
+	delete(c.objects["user"], id)
+	c.put("user", updated)
+
+	return true, nil
+}
+
+func (c *fakeClient) UpdateByID(_ context.Context, kind, id string, obj any) (bool, error) {
+	m, err := toFakeObject(obj)
+	if err != nil {
+		return false, err
+	}
+
+	c.record("update-"+kind, id, nil)
+	if c.object(kind, id) == nil {
+		return false, nil
+	}
+	delete(c.objects[kind], id)
+	c.put(kind, m)
+
+	return true, nil
+}
+
+func (c *fakeClient) ObjectExists(_ context.Context, kind, id string) (bool, error) {
+	return c.object(kind, id) != nil, nil
+}
+
+func (c *fakeClient) UploadResource(_ context.Context, owner, user, tag, parent, fullFilePath string, fileBytes []byte, createdTime, description string) (string, string, error) {
+	fileURL := "https://cdn.example.com/" + fullFilePath
+	c.record("upload-resource", owner+"/"+fullFilePath, nil)
+	c.put("resource", map[string]any{
+		"owner":       owner,
+		"name":        fullFilePath,
+		"user":        user,
+		"tag":         tag,
+		"parent":      parent,
+		"fileSize":    float64(len(fileBytes)),
+		"url":         fileURL,
+		"createdTime": createdTime,
+		"description": description,
+	})
+
+	return fileURL, fullFilePath, nil
+}
+
+// ParseJwtToken returns the claims of token without verifying its
+// signature.
+func (c *fakeClient) ParseJwtToken(_ context.Context, token string) (*casdoorsdk.Claims, error) {
+	claims := &casdoorsdk.Claims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(token, claims); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+func (c *fakeClient) OrganizationName() string {
+	return c.organization
+}
+
+func (c *fakeClient) ReadOnly() bool {
+	return c.readOnly
+}
+
+// fakeObjectID returns the ID of a stored object: 'owner/name', or
+// 'owner/id' for kinds without a name.
+func fakeObjectID(obj map[string]any) string {
+	name, _ := obj["name"].(string)
+	if name == "" {
+		name, _ = obj["id"].(string)
+	}
+	owner, _ := obj["owner"].(string)
+
+	return owner + "/" + name
+}
+
+// toFakeObject converts obj to the JSON object the fakeClient stores.
+func toFakeObject(obj any) (map[string]any, error) {
+	content, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	var m map[string]any
+	if err := json.Unmarshal(content, &m); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// fromFakeObject converts a stored object back; nil stays nil.
+func fromFakeObject[T any](obj map[string]any) (*T, error) {
+	if obj == nil {
+		return nil, nil
+	}
+
+	content, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	var t T
+	if err := json.Unmarshal(content, &t); err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", fakeObjectID(obj), err)
+	}
+
+	return &t, nil
+}
+
+func fromFakeObjects[T any](objects []map[string]any) ([]*T, error) {
+	result := make([]*T, 0, len(objects))
+	for _, obj := range objects {
+		t, err := fromFakeObject[T](obj)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, t)
+	}
+
+	return result, nil
+}
+
+// testResource returns r configured with client and the given provider
+// settings, and its schema.
+func testResource(t *testing.T, r resource.Resource, client casdoorClient, data CasdoorProviderData) (resource.Resource, schema.Schema) {
+	t.Helper()
+	ctx := context.Background()
+
+	data.Client = client
+	if configurable, ok := r.(resource.ResourceWithConfigure); ok {
+		var resp resource.ConfigureResponse
+		configurable.Configure(ctx, resource.ConfigureRequest{ProviderData: &data}, &resp)
+		if resp.Diagnostics.HasError() {
+			t.Fatalf("Failed to configure resource: %v", resp.Diagnostics)
+		}
+	}
+
+	var resp resource.SchemaResponse
+	r.Schema(ctx, resource.SchemaRequest{}, &resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("Failed to get schema: %v", resp.Diagnostics)
+	}
+
+	return r, resp.Schema
+}
+
+// testObjectValue returns an object of the resource or data source schema's
+// type with the given attribute values; the other attributes are null.
+func testObjectValue(t *testing.T, s interface{ Type() attr.Type }, values map[string]tftypes.Value) tftypes.Value {
+	t.Helper()
+
+	typ := s.Type().TerraformType(context.Background()).(tftypes.Object)
+	attrs := make(map[string]tftypes.Value, len(typ.AttributeTypes))
+	for name, attrType := range typ.AttributeTypes {
+		attrs[name] = tftypes.NewValue(attrType, nil)
+	}
+	for name, value := range values {
+		if _, ok := attrs[name]; !ok {
+			t.Fatalf("Unknown attribute %q", name)
+		}
+		attrs[name] = value
+	}
+
+	return tftypes.NewValue(typ, attrs)
+}
+
+// testWithAttributes returns a copy of the object value with the given
+// attributes replaced.
+func testWithAttributes(t *testing.T, value tftypes.Value, values map[string]tftypes.Value) tftypes.Value {
+	t.Helper()
+
+	var current map[string]tftypes.Value
+	if err := value.As(&current); err != nil {
+		t.Fatalf("Failed to read object: %v", err)
+	}
+	// The map shares its storage with value, which must not change.
+	attrs := maps.Clone(current)
+	for name, v := range values {
+		if _, ok := attrs[name]; !ok {
+			t.Fatalf("Unknown attribute %q", name)
+		}
+		attrs[name] = v
+	}
+
+	return tftypes.NewValue(value.Type(), attrs)
+}
+
+// withTestPrivate initializes the Private field of the resource response
+// resp points to, which the framework normally does.
+func withTestPrivate[T any](resp *T) *T {
+	private := reflect.ValueOf(resp).Elem().FieldByName("Private")
+	private.Set(reflect.New(private.Type().Elem()))
+	return resp
+}
+
+// testCreate runs Create with config as the plan and returns the new state.
+func testCreate(t *testing.T, r resource.Resource, s schema.Schema, config tftypes.Value) tfsdk.State {
+	t.Helper()
+
+	resp := withTestPrivate(&resource.CreateResponse{State: tfsdk.State{Schema: s, Raw: tftypes.NewValue(config.Type(), nil)}})
+	r.Create(context.Background(), resource.CreateRequest{
+		Config: tfsdk.Config{Schema: s, Raw: config},
+		Plan:   tfsdk.Plan{Schema: s, Raw: config},
+	}, resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("Create failed: %v", resp.Diagnostics)
+	}
+
+	return resp.State
+}
+
+// testRead runs Read on state and returns the refreshed state.
+func testRead(t *testing.T, r resource.Resource, state tfsdk.State) tfsdk.State {
+	t.Helper()
+
+	resp := withTestPrivate(&resource.ReadResponse{State: state})
+	r.Read(context.Background(), resource.ReadRequest{State: state}, resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("Read failed: %v", resp.Diagnostics)
+	}
+
+	return resp.State
+}
+
+// testUpdate runs Update from state to plan, with config as the
+// configuration, and returns the new state.
+func testUpdate(t *testing.T, r resource.Resource, state tfsdk.State, config, plan tftypes.Value) tfsdk.State {
+	t.Helper()
+
+	resp := withTestPrivate(&resource.UpdateResponse{State: tfsdk.State{Schema: state.Schema, Raw: plan}})
+	r.Update(context.Background(), resource.UpdateRequest{
+		Config: tfsdk.Config{Schema: state.Schema, Raw: config},
+		Plan:   tfsdk.Plan{Schema: state.Schema, Raw: plan},
+		State:  state,
+	}, resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("Update failed: %v", resp.Diagnostics)
+	}
+
+	return resp.State
+}
+
+// testDelete runs Delete on state.
+func testDelete(t *testing.T, r resource.Resource, state tfsdk.State) {
+	t.Helper()
+
+	resp := &resource.DeleteResponse{State: state}
+	r.Delete(context.Background(), resource.DeleteRequest{State: state}, resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("Delete failed: %v", resp.Diagnostics)
+	}
+}
+
+// testAttribute returns the string form of the attribute name in state.
+func testAttribute(t *testing.T, state tfsdk.State, name string) string {
+	t.Helper()
+
+	var values map[string]tftypes.Value
+	if err := state.Raw.As(&values); err != nil {
+		t.Fatalf("Failed to read state: %v", err)
+	}
+	value, ok := values[name]
+	if !ok {
+		t.Fatalf("Unknown attribute %q", name)
+	}
+
+	switch {
+	case value.IsNull():
+		return "<null>"
+	case !value.IsKnown():
+		return "<unknown>"
+	case value.Type().Is(tftypes.String):
+		var s string
+		_ = value.As(&s)
+		return s
+	case value.Type().Is(tftypes.Bool):
+		var b bool
+		_ = value.As(&b)
+		return strconv.FormatBool(b)
+	case value.Type().Is(tftypes.Number):
+		var f big.Float
+		_ = value.As(&f)
+		return f.Text('f', -1)
+	default:
+		return value.String()
+	}
+}
@@ -8,7 +8,9 @@ import (
 	"time"
 
 	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
@@ -24,30 +26,34 @@ var (
 	_ resource.Resource                = &GroupResource{}
 	_ resource.ResourceWithConfigure   = &GroupResource{}
 	_ resource.ResourceWithImportState = &GroupResource{}
+	_ resource.ResourceWithModifyPlan  = &GroupResource{}
 )
 
 type GroupResource struct {
-	client *casdoorsdk.Client
+	client       casdoorClient
+	providerData *CasdoorProviderData
 }
 
 type GroupResourceModel struct {
-	ID           types.String `tfsdk:"id"`
-	Owner        types.String `tfsdk:"owner"`
-	Name         types.String `tfsdk:"name"`
-	CreatedTime  types.String `tfsdk:"created_time"`
-	UpdatedTime  types.String `tfsdk:"updated_time"`
-	DisplayName  types.String `tfsdk:"display_name"`
-	Manager      types.String `tfsdk:"manager"`
-	ContactEmail types.String `tfsdk:"contact_email"`
-	Type         types.String `tfsdk:"type"`
-	ParentId     types.String `tfsdk:"parent_id"`
-	ParentName   types.String `tfsdk:"parent_name"`
-	Title        types.String `tfsdk:"title"`
-	Key          types.String `tfsdk:"key"`
-	HaveChildren types.Bool   `tfsdk:"have_children"`
-	IsTopGroup   types.Bool   `tfsdk:"is_top_group"`
-	Users        types.List   `tfsdk:"users"`
-	IsEnabled    types.Bool   `tfsdk:"is_enabled"`
+	ID            types.String   `tfsdk:"id"`
+	Owner         types.String   `tfsdk:"owner"`
+	Name          types.String   `tfsdk:"name"`
+	CreatedTime   types.String   `tfsdk:"created_time"`
+	UpdatedTime   types.String   `tfsdk:"updated_time"`
+	DisplayName   types.String   `tfsdk:"display_name"`
+	Manager       types.String   `tfsdk:"manager"`
+	ContactEmail  types.String   `tfsdk:"contact_email"`
+	Type          types.String   `tfsdk:"type"`
+	ParentId      types.String   `tfsdk:"parent_id"`
+	ParentName    types.String   `tfsdk:"parent_name"`
+	Title         types.String   `tfsdk:"title"`
+	Key           types.String   `tfsdk:"key"`
+	HaveChildren  types.Bool     `tfsdk:"have_children"`
+	IsTopGroup    types.Bool     `tfsdk:"is_top_group"`
+	Users         types.List     `tfsdk:"users"`
+	IsEnabled     types.Bool     `tfsdk:"is_enabled"`
+	AdoptExisting types.Bool     `tfsdk:"adopt_existing"`
+	Timeouts      timeouts.Value `tfsdk:"timeouts"`
 }
 
 func NewGroupResource() resource.Resource {
@@ -58,7 +64,7 @@ func (r *GroupResource) Metadata(_ context.Context, req resource.MetadataRequest
 	resp.TypeName = req.ProviderTypeName + "_group"
 }
 
-func (r *GroupResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+func (r *GroupResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		Description: "Manages a Casdoor user group.",
 		Attributes: map[string]schema.Attribute{
@@ -66,22 +72,13 @@ func (r *GroupResource) Schema(_ context.Context, _ resource.SchemaRequest, resp
 				Description: "The ID of the group in the format 'owner/name'.",
 				Computed:    true,
 				PlanModifiers: []planmodifier.String{
-					stringplanmodifier.UseStateForUnknown(),
-				},
-			},
-			"owner": schema.StringAttribute{
-				Description: "The organization that owns this group.",
-				Required:    true,
-				PlanModifiers: []planmodifier.String{
-					stringplanmodifier.RequiresReplace(),
+					useStateUnlessChanged(path.Root("name")),
 				},
 			},
+			"owner": ownerAttribute("group"),
 			"name": schema.StringAttribute{
-				Description: "The unique name of the group.",
+				Description: "The unique name of the group. Changing it renames the group in place.",
 				Required:    true,
-				PlanModifiers: []planmodifier.String{
-					stringplanmodifier.RequiresReplace(),
-				},
 			},
 			"created_time": schema.StringAttribute{
 				Description: "The time when the group was created.",
@@ -122,16 +119,20 @@ func (r *GroupResource) Schema(_ context.Context, _ resource.SchemaRequest, resp
 				Default:     stringdefault.StaticString(""),
 			},
 			"parent_id": schema.StringAttribute{
-				Description: "The parent group ID for hierarchical groups.",
+				Description: "The parent group ID for hierarchical groups. Set to the owning organization for a top-level group. Changing it re-parents the group in place.",
 				Optional:    true,
 				Computed:    true,
 				Default:     stringdefault.StaticString(""),
 			},
 			"parent_name": schema.StringAttribute{
-				Description: "The parent group name.",
-				Optional:    true,
-				Computed:    true,
-				Default:     stringdefault.StaticString(""),
+				Description: "The display name of the parent group. Computed from the group hierarchy.",
+				DeprecationMessage: "parent_name is computed from parent_id and a configured value has no effect. " +
+					"Remove it from the configuration.",
+				Optional: true,
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					useStateUnlessChanged(path.Root("parent_id")),
+				},
 			},
 			"title": schema.StringAttribute{
 				Description: "The title of the group.",
@@ -140,10 +141,14 @@ func (r *GroupResource) Schema(_ context.Context, _ resource.SchemaRequest, resp
 				Default:     stringdefault.StaticString(""),
 			},
 			"key": schema.StringAttribute{
-				Description: "The key identifier of the group.",
-				Optional:    true,
-				Computed:    true,
-				Default:     stringdefault.StaticString(""),
+				Description: "The key of the group in the organization's group tree.",
+				DeprecationMessage: "key is computed by Casdoor and a configured value has no effect. " +
+					"Remove it from the configuration.",
+				Optional: true,
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					useStateUnlessChanged(path.Root("name")),
+				},
 			},
 			"have_children": schema.BoolAttribute{
 				Description: "Whether this group has child groups.",
@@ -153,10 +158,12 @@ func (r *GroupResource) Schema(_ context.Context, _ resource.SchemaRequest, resp
 				},
 			},
 			"is_top_group": schema.BoolAttribute{
-				Description: "Whether this is a top-level group.",
+				Description: "Whether this is a top-level group. Defaults to whether parent_id is the owning organization.",
 				Optional:    true,
 				Computed:    true,
-				Default:     booldefault.StaticBool(false),
+				PlanModifiers: []planmodifier.Bool{
+					groupIsTopGroupDefault(),
+				},
 			},
 			"users": schema.ListAttribute{
 				Description: "List of users in this group.",
@@ -173,6 +180,15 @@ func (r *GroupResource) Schema(_ context.Context, _ resource.SchemaRequest, resp
 				Computed:    true,
 				Default:     booldefault.StaticBool(true),
 			},
+			"adopt_existing": adoptExistingAttribute("group"),
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Read:   true,
+				Update: true,
+				Delete: true,
+			}),
 		},
 	}
 }
@@ -182,16 +198,26 @@ func (r *GroupResource) Configure(_ context.Context, req resource.ConfigureReque
 		return
 	}
 
-	client, ok := req.ProviderData.(*casdoorsdk.Client)
+	data, ok := req.ProviderData.(*CasdoorProviderData)
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Resource Configure Type",
-			fmt.Sprintf("Expected *casdoorsdk.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected *CasdoorProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 		return
 	}
 
-	r.client = client
+	r.client = data.Client
+	r.providerData = data
+}
+
+func (r *GroupResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	planDefaultOwner(ctx, req, resp, r.providerData)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	planAdoption(ctx, req, resp, r.providerData, "group", r.Read)
 }
 
 func groupPlanToSDK(ctx context.Context, plan GroupResourceModel, createdTime, updatedTime string) (*casdoorsdk.Group, diag.Diagnostics) {
@@ -213,16 +239,132 @@ func groupPlanToSDK(ctx context.Context, plan GroupResourceModel, createdTime, u
 		ContactEmail: plan.ContactEmail.ValueString(),
 		Type:         plan.Type.ValueString(),
 		ParentId:     plan.ParentId.ValueString(),
-		ParentName:   plan.ParentName.ValueString(),
 		Title:        plan.Title.ValueString(),
-		Key:          plan.Key.ValueString(),
 		IsTopGroup:   plan.IsTopGroup.ValueBool(),
 		Users:        users,
 		IsEnabled:    plan.IsEnabled.ValueBool(),
 	}, diags
 }
 
+// isTopGroupParent reports whether parentID places a group at the top of the
+// owner's group tree, under no other group. Casdoor uses the organization
+// name as the parent of top-level groups; an empty parent has the same place
+// in the tree.
+func isTopGroupParent(owner, parentID string) bool {
+	return parentID == "" || parentID == owner
+}
+
+// setGroupTreeAttributes fills the attributes that Casdoor only derives when
+// listing groups (parent_name, have_children) and the tree key.
+func (r *GroupResource) setGroupTreeAttributes(ctx context.Context, model *GroupResourceModel, group *casdoorsdk.Group) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	groups, err := r.client.Groups().List(ctx, group.Owner)
+	if err != nil {
+		diags.AddError(
+			"Error Reading Groups",
+			fmt.Sprintf("Could not list groups of organization %q: %s", group.Owner, err),
+		)
+		return diags
+	}
+
+	parentName := ""
+	haveChildren := false
+	for _, g := range groups {
+		if g.Name == group.ParentId && !isTopGroupParent(group.Owner, group.ParentId) {
+			parentName = g.DisplayName
+		}
+		if g.ParentId == group.Name {
+			haveChildren = true
+		}
+	}
+
+	key := group.Key
+	if key == "" {
+		key = group.Name
+	}
+
+	model.ParentName = types.StringValue(parentName)
+	model.HaveChildren = types.BoolValue(haveChildren)
+	model.Key = types.StringValue(key)
+
+	return diags
+}
+
+// keepPlannedTreeAttributes puts back the planned values of the deprecated
+// parent_name and key attributes over the derived ones when they are known,
+// as they are when set in configuration or kept from state.
+func keepPlannedTreeAttributes(planned GroupResourceModel, model *GroupResourceModel) {
+	if !planned.ParentName.IsUnknown() {
+		model.ParentName = planned.ParentName
+	}
+	if !planned.Key.IsUnknown() {
+		model.Key = planned.Key
+	}
+}
+
+// validateGroupParent checks that parentID names an existing group of the
+// same organization and that moving the group under it does not create a
+// cycle.
+func (r *GroupResource) validateGroupParent(ctx context.Context, owner, name, parentID string) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if isTopGroupParent(owner, parentID) {
+		return diags
+	}
+
+	if parentID == name {
+		diags.AddError(
+			"Invalid Group Parent",
+			fmt.Sprintf("Group %q cannot be its own parent.", name),
+		)
+		return diags
+	}
+
+	groups, err := r.client.Groups().List(ctx, owner)
+	if err != nil {
+		diags.AddError(
+			"Error Reading Groups",
+			fmt.Sprintf("Could not list groups of organization %q: %s", owner, err),
+		)
+		return diags
+	}
+
+	parents := make(map[string]string, len(groups))
+	for _, g := range groups {
+		parents[g.Name] = g.ParentId
+	}
+
+	if _, ok := parents[parentID]; !ok {
+		diags.AddError(
+			"Invalid Group Parent",
+			fmt.Sprintf("Parent group %q does not exist in organization %q.", parentID, owner),
+		)
+		return diags
+	}
+
+	// Walk up from the new parent; reaching the group itself means the move
+	// would put the group below one of its own descendants.
+	seen := map[string]bool{}
+	for id := parentID; !isTopGroupParent(owner, id) && !seen[id]; id = parents[id] {
+		if id == name {
+			diags.AddError(
+				"Invalid Group Parent",
+				fmt.Sprintf("Cannot move group %q under %q: %q is a descendant of %q.", name, parentID, parentID, name),
+			)
+			return diags
+		}
+		seen[id] = true
+	}
+
+	return diags
+}
+
 func (r *GroupResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	if checkUnconfigured(r.client, &resp.Diagnostics) {
+		return
+	}
+
 	var plan GroupResourceModel
 
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
@@ -230,24 +372,42 @@ func (r *GroupResource) Create(ctx context.Context, req resource.CreateRequest,
 		return
 	}
 
+	ctx, done := startOperation(ctx, plan.Timeouts.Create, defaultOperationTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	createdTime := plan.CreatedTime.ValueString()
 	if createdTime == "" {
 		createdTime = time.Now().UTC().Format(time.RFC3339)
 	}
 
+	resp.Diagnostics.Append(r.validateGroupParent(ctx, plan.Owner.ValueString(), plan.Name.ValueString(), plan.ParentId.ValueString())...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	group, diags := groupPlanToSDK(ctx, plan, createdTime, createdTime)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	ok, err := r.client.AddGroup(group)
-	if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("creating group %q", plan.Name.ValueString())) {
+	adopted := adoptOnCreate(ctx, r.providerData, req.Plan, req.Config, &resp.Diagnostics, "group", plan.Owner.ValueString()+"/"+plan.Name.ValueString(), group, r.client.Groups())
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
+	if !adopted {
+		ok, err := r.client.Groups().Add(ctx, group)
+		if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("creating group %q", plan.Name.ValueString())) {
+			return
+		}
+	}
+
 	// Read back the group to get server-generated values like CreatedTime.
-	createdGroup, err := r.client.GetGroup(plan.Owner.ValueString() + "/" + plan.Name.ValueString())
+	createdGroup, err := r.client.Groups().Get(ctx, plan.Owner.ValueString()+"/"+plan.Name.ValueString())
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Reading Group",
@@ -264,12 +424,16 @@ func (r *GroupResource) Create(ctx context.Context, req resource.CreateRequest,
 		return
 	}
 
+	planned := plan
 	plan.CreatedTime = types.StringValue(createdGroup.CreatedTime)
 	plan.UpdatedTime = types.StringValue(createdGroup.UpdatedTime)
-	plan.ParentName = types.StringValue(createdGroup.ParentName)
 	plan.Title = types.StringValue(createdGroup.Title)
-	plan.Key = types.StringValue(createdGroup.Key)
-	plan.HaveChildren = types.BoolValue(createdGroup.HaveChildren)
+	plan.IsTopGroup = types.BoolValue(createdGroup.IsTopGroup)
+	resp.Diagnostics.Append(r.setGroupTreeAttributes(ctx, &plan, createdGroup)...)
+	keepPlannedTreeAttributes(planned, &plan)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 	usersList, diags := types.ListValueFrom(ctx, types.StringType, createdGroup.Users)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
@@ -282,6 +446,10 @@ func (r *GroupResource) Create(ctx context.Context, req resource.CreateRequest,
 }
 
 func (r *GroupResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	if r.client == nil {
+		return
+	}
+
 	var state GroupResourceModel
 
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
@@ -289,7 +457,13 @@ func (r *GroupResource) Read(ctx context.Context, req resource.ReadRequest, resp
 		return
 	}
 
-	group, err := r.client.GetGroup(state.Owner.ValueString() + "/" + state.Name.ValueString())
+	ctx, done := startOperation(ctx, state.Timeouts.Read, defaultReadTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	group, err := r.client.Groups().Get(ctx, state.Owner.ValueString()+"/"+state.Name.ValueString())
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Reading Group",
@@ -313,13 +487,15 @@ func (r *GroupResource) Read(ctx context.Context, req resource.ReadRequest, resp
 	state.ContactEmail = types.StringValue(group.ContactEmail)
 	state.Type = types.StringValue(group.Type)
 	state.ParentId = types.StringValue(group.ParentId)
-	state.ParentName = types.StringValue(group.ParentName)
 	state.Title = types.StringValue(group.Title)
-	state.Key = types.StringValue(group.Key)
-	state.HaveChildren = types.BoolValue(group.HaveChildren)
 	state.IsTopGroup = types.BoolValue(group.IsTopGroup)
 	state.IsEnabled = types.BoolValue(group.IsEnabled)
 
+	resp.Diagnostics.Append(r.setGroupTreeAttributes(ctx, &state, group)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	usersList, diags := types.ListValueFrom(ctx, types.StringType, group.Users)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
@@ -327,17 +503,42 @@ func (r *GroupResource) Read(ctx context.Context, req resource.ReadRequest, resp
 	}
 	state.Users = usersList
 
+	resp.Diagnostics.Append(setObjectFingerprint(ctx, resp.Private, group)...)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
 }
 
 func (r *GroupResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	var plan GroupResourceModel
+	if checkUnconfigured(r.client, &resp.Diagnostics) {
+		return
+	}
+
+	var plan, state GroupResourceModel
 
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, done := startOperation(ctx, plan.Timeouts.Update, defaultOperationTimeout, &resp.Diagnostics)
+	defer done()
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
+	if checkUpdateConflict(ctx, r.providerData, &resp.Diagnostics, req.Private, "group", state.Owner.ValueString()+"/"+state.Name.ValueString(), r.client.Groups().Get) {
+		return
+	}
+
+	// Re-parenting is an in-place update, but Casdoor does not stop a group
+	// from becoming its own ancestor, so check the new parent first.
+	if !plan.ParentId.Equal(state.ParentId) {
+		resp.Diagnostics.Append(r.validateGroupParent(ctx, plan.Owner.ValueString(), plan.Name.ValueString(), plan.ParentId.ValueString())...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
 	users, diags := stringListToSDK(ctx, plan.Users)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
@@ -359,13 +560,12 @@ func (r *GroupResource) Update(ctx context.Context, req resource.UpdateRequest,
 		IsEnabled:    plan.IsEnabled.ValueBool(),
 	}
 
-	ok, err := r.client.UpdateGroup(group)
-	if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("updating group %q", plan.Name.ValueString())) {
+	if renameUpdate(ctx, resp, r.client, "group", plan.Owner.ValueString(), state.Name.ValueString(), plan.Name.ValueString(), group) {
 		return
 	}
 
 	// Read back to get updated fields.
-	updatedGroup, err := r.client.GetGroup(plan.Owner.ValueString() + "/" + plan.Name.ValueString())
+	updatedGroup, err := r.client.Groups().Get(ctx, plan.Owner.ValueString()+"/"+plan.Name.ValueString())
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Reading Group",
@@ -381,10 +581,14 @@ func (r *GroupResource) Update(ctx context.Context, req resource.UpdateRequest,
 		return
 	}
 
-	plan.ParentName = types.StringValue(updatedGroup.ParentName)
+	planned := plan
 	plan.Title = types.StringValue(updatedGroup.Title)
-	plan.Key = types.StringValue(updatedGroup.Key)
-	plan.HaveChildren = types.BoolValue(updatedGroup.HaveChildren)
+	plan.IsTopGroup = types.BoolValue(updatedGroup.IsTopGroup)
+	resp.Diagnostics.Append(r.setGroupTreeAttributes(ctx, &plan, updatedGroup)...)
+	keepPlannedTreeAttributes(planned, &plan)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 	usersList, diags := types.ListValueFrom(ctx, types.StringType, updatedGroup.Users)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
@@ -393,10 +597,15 @@ func (r *GroupResource) Update(ctx context.Context, req resource.UpdateRequest,
 	plan.Users = usersList
 
 	plan.ID = types.StringValue(plan.Owner.ValueString() + "/" + plan.Name.ValueString())
+	resp.Diagnostics.Append(clearObjectFingerprint(ctx, resp.Private)...)
 	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
 }
 
 func (r *GroupResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	if checkUnconfigured(r.client, &resp.Diagnostics) {
+		return
+	}
+
 	var state GroupResourceModel
 
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
@@ -404,12 +613,18 @@ func (r *GroupResource) Delete(ctx context.Context, req resource.DeleteRequest,
 		return
 	}
 
+	ctx, done := startOperation(ctx, state.Timeouts.Delete, defaultOperationTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	group := &casdoorsdk.Group{
 		Owner: state.Owner.ValueString(),
 		Name:  state.Name.ValueString(),
 	}
 
-	ok, err := r.client.DeleteGroup(group)
+	ok, err := r.client.Groups().Delete(ctx, group)
 	if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("deleting group %q", state.Name.ValueString())) {
 		return
 	}
@@ -418,3 +633,51 @@ func (r *GroupResource) Delete(ctx context.Context, req resource.DeleteRequest,
 func (r *GroupResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
 	importStateOwnerName(ctx, req, resp)
 }
+
+// groupIsTopGroupDefault derives is_top_group from parent_id when it is not
+// configured, so that re-parenting a group keeps the two consistent.
+func groupIsTopGroupDefault() planmodifier.Bool {
+	return groupIsTopGroupDefaultModifier{}
+}
+
+type groupIsTopGroupDefaultModifier struct{}
+
+func (m groupIsTopGroupDefaultModifier) Description(_ context.Context) string {
+	return "Defaults to whether parent_id is the owning organization."
+}
+
+func (m groupIsTopGroupDefaultModifier) MarkdownDescription(ctx context.Context) string {
+	return m.Description(ctx)
+}
+
+func (m groupIsTopGroupDefaultModifier) PlanModifyBool(ctx context.Context, req planmodifier.BoolRequest, resp *planmodifier.BoolResponse) {
+	if !req.ConfigValue.IsNull() || req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var owner, planParent types.String
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("owner"), &owner)...)
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("parent_id"), &planParent)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !req.StateValue.IsNull() {
+		var stateParent types.String
+		resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("parent_id"), &stateParent)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		if planParent.Equal(stateParent) {
+			resp.PlanValue = req.StateValue
+			return
+		}
+	}
+
+	if owner.IsUnknown() || planParent.IsUnknown() {
+		resp.PlanValue = types.BoolUnknown()
+		return
+	}
+
+	resp.PlanValue = types.BoolValue(planParent.ValueString() == owner.ValueString())
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
 )
 
 func TestAccGroupResource_basic(t *testing.T) {
@@ -41,6 +42,57 @@ func TestAccGroupResource_basic(t *testing.T) {
 	})
 }
 
+func TestAccGroupResource_reparent(t *testing.T) {
+	config := setupTestConfig(t)
+	rName := "tf-test-" + acctest.RandStringFromCharSet(8, acctest.CharSetAlphaNum)
+	resourceName := "casdoor_group.child"
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories(config),
+		Steps: []resource.TestStep{
+			// Create the child under the first parent
+			{
+				Config: testAccProviderConfig(config) + testAccGroupResourceReparentConfig(rName, "casdoor_group.parent_a.name"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "parent_id", rName+"-a"),
+					resource.TestCheckResourceAttr(resourceName, "parent_name", "Parent A"),
+					resource.TestCheckResourceAttr(resourceName, "is_top_group", "false"),
+					resource.TestCheckResourceAttr(resourceName, "key", rName+"-child"),
+					resource.TestCheckResourceAttr("casdoor_group.parent_a", "have_children", "true"),
+				),
+			},
+			// Move the child under the second parent without replacing it
+			{
+				Config: testAccProviderConfig(config) + testAccGroupResourceReparentConfig(rName, "casdoor_group.parent_b.name"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction(resourceName, plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "parent_id", rName+"-b"),
+					resource.TestCheckResourceAttr(resourceName, "parent_name", "Parent B"),
+					resource.TestCheckResourceAttr(resourceName, "is_top_group", "false"),
+				),
+			},
+			// Move the child to the top of the tree
+			{
+				Config: testAccProviderConfig(config) + testAccGroupResourceReparentConfig(rName, `"built-in"`),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction(resourceName, plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "parent_id", "built-in"),
+					resource.TestCheckResourceAttr(resourceName, "parent_name", ""),
+					resource.TestCheckResourceAttr(resourceName, "is_top_group", "true"),
+				),
+			},
+		},
+	})
+}
+
 func TestAccGroupResource_import(t *testing.T) {
 	config := setupTestConfig(t)
 	rName := "tf-test-" + acctest.RandStringFromCharSet(8, acctest.CharSetAlphaNum)
@@ -75,3 +127,28 @@ resource "casdoor_group" "test" {
 }
 `, name, displayName)
 }
+
+func testAccGroupResourceReparentConfig(name, parentID string) string {
+	return fmt.Sprintf(`
+resource "casdoor_group" "parent_a" {
+  owner        = "built-in"
+  name         = "%[1]s-a"
+  display_name = "Parent A"
+  parent_id    = "built-in"
+}
+
+resource "casdoor_group" "parent_b" {
+  owner        = "built-in"
+  name         = "%[1]s-b"
+  display_name = "Parent B"
+  parent_id    = "built-in"
+}
+
+resource "casdoor_group" "child" {
+  owner        = "built-in"
+  name         = "%[1]s-child"
+  display_name = "Child"
+  parent_id    = %[2]s
+}
+`, name, parentID)
+}
@@ -0,0 +1,341 @@
+// Copyright (c) HashiCorp, Inc.
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ datasource.DataSource              = &GroupTreeDataSource{}
+	_ datasource.DataSourceWithConfigure = &GroupTreeDataSource{}
+)
+
+type GroupTreeDataSource struct {
+	client       casdoorClient
+	providerData *CasdoorProviderData
+}
+
+type GroupTreeDataSourceModel struct {
+	ID     types.String         `tfsdk:"id"`
+	Owner  types.String         `tfsdk:"owner"`
+	Roots  types.List           `tfsdk:"roots"`
+	Groups []GroupTreeNodeModel `tfsdk:"groups"`
+}
+
+type GroupTreeNodeModel struct {
+	Name             types.String `tfsdk:"name"`
+	DisplayName      types.String `tfsdk:"display_name"`
+	Type             types.String `tfsdk:"type"`
+	ParentId         types.String `tfsdk:"parent_id"`
+	ParentName       types.String `tfsdk:"parent_name"`
+	Key              types.String `tfsdk:"key"`
+	IsTopGroup       types.Bool   `tfsdk:"is_top_group"`
+	IsEnabled        types.Bool   `tfsdk:"is_enabled"`
+	HaveChildren     types.Bool   `tfsdk:"have_children"`
+	Depth            types.Int64  `tfsdk:"depth"`
+	Path             types.List   `tfsdk:"path"`
+	Children         types.List   `tfsdk:"children"`
+	Users            types.List   `tfsdk:"users"`
+	MemberCount      types.Int64  `tfsdk:"member_count"`
+	TotalMemberCount types.Int64  `tfsdk:"total_member_count"`
+}
+
+func NewGroupTreeDataSource() datasource.DataSource {
+	return &GroupTreeDataSource{}
+}
+
+func (d *GroupTreeDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_group_tree"
+}
+
+func (d *GroupTreeDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Reads the group hierarchy of a Casdoor organization. Groups are returned depth-first, " +
+			"with every parent listed before its children and siblings ordered by name.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "The organization name.",
+				Computed:    true,
+			},
+			"owner": schema.StringAttribute{
+				Description: "The organization whose groups are read.",
+				Required:    true,
+			},
+			"roots": schema.ListAttribute{
+				Description: "Names of the top-level groups.",
+				Computed:    true,
+				ElementType: types.StringType,
+			},
+			"groups": schema.ListNestedAttribute{
+				Description: "All groups of the organization in depth-first order.",
+				Computed:    true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							Description: "The name of the group.",
+							Computed:    true,
+						},
+						"display_name": schema.StringAttribute{
+							Description: "The display name of the group.",
+							Computed:    true,
+						},
+						"type": schema.StringAttribute{
+							Description: "The type of the group.",
+							Computed:    true,
+						},
+						"parent_id": schema.StringAttribute{
+							Description: "The parent group ID, or the organization name for a top-level group.",
+							Computed:    true,
+						},
+						"parent_name": schema.StringAttribute{
+							Description: "The display name of the parent group.",
+							Computed:    true,
+						},
+						"key": schema.StringAttribute{
+							Description: "The key of the group in the group tree.",
+							Computed:    true,
+						},
+						"is_top_group": schema.BoolAttribute{
+							Description: "Whether this is a top-level group.",
+							Computed:    true,
+						},
+						"is_enabled": schema.BoolAttribute{
+							Description: "Whether the group is enabled.",
+							Computed:    true,
+						},
+						"have_children": schema.BoolAttribute{
+							Description: "Whether this group has child groups.",
+							Computed:    true,
+						},
+						"depth": schema.Int64Attribute{
+							Description: "The depth of the group in the tree. Top-level groups have depth 0.",
+							Computed:    true,
+						},
+						"path": schema.ListAttribute{
+							Description: "Names of the groups from the top-level group down to this group.",
+							Computed:    true,
+							ElementType: types.StringType,
+						},
+						"children": schema.ListAttribute{
+							Description: "Names of the direct child groups.",
+							Computed:    true,
+							ElementType: types.StringType,
+						},
+						"users": schema.ListAttribute{
+							Description: "Users that are direct members of the group.",
+							Computed:    true,
+							ElementType: types.StringType,
+						},
+						"member_count": schema.Int64Attribute{
+							Description: "The number of direct members of the group.",
+							Computed:    true,
+						},
+						"total_member_count": schema.Int64Attribute{
+							Description: "The number of distinct members of the group and all of its descendants.",
+							Computed:    true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *GroupTreeDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*CasdoorProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *CasdoorProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	d.client = data.Client
+	d.providerData = data
+}
+
+func (d *GroupTreeDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	if checkUnconfigured(d.client, &resp.Diagnostics) {
+		return
+	}
+
+	var state GroupTreeDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, done := startOperation(ctx, nil, defaultReadTimeout, &resp.Diagnostics)
+	defer done()
+
+	owner := state.Owner.ValueString()
+
+	groups, err := d.client.Groups().List(ctx, owner)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading Groups",
+			fmt.Sprintf("Could not list groups of organization %q: %s", owner, err),
+		)
+		return
+	}
+
+	tree := newGroupTree(owner, groups)
+
+	roots, diags := types.ListValueFrom(ctx, types.StringType, tree.roots)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	state.ID = types.StringValue(owner)
+	state.Roots = roots
+	state.Groups = []GroupTreeNodeModel{}
+	for _, name := range tree.roots {
+		resp.Diagnostics.Append(tree.walk(ctx, name, nil, &state.Groups)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// groupTree indexes an organization's groups by name and by parent.
+type groupTree struct {
+	groups   map[string]*casdoorsdk.Group
+	children map[string][]string
+	roots    []string
+}
+
+// newGroupTree builds the hierarchy of an organization's groups. Groups whose
+// parent is the organization, empty, or missing are treated as roots.
+func newGroupTree(owner string, groups []*casdoorsdk.Group) *groupTree {
+	tree := &groupTree{
+		groups:   make(map[string]*casdoorsdk.Group, len(groups)),
+		children: map[string][]string{},
+		roots:    []string{},
+	}
+
+	for _, g := range groups {
+		tree.groups[g.Name] = g
+	}
+
+	for _, g := range groups {
+		_, parentExists := tree.groups[g.ParentId]
+		if isTopGroupParent(owner, g.ParentId) || !parentExists || g.ParentId == g.Name {
+			tree.roots = append(tree.roots, g.Name)
+			continue
+		}
+		tree.children[g.ParentId] = append(tree.children[g.ParentId], g.Name)
+	}
+
+	sort.Strings(tree.roots)
+	for _, names := range tree.children {
+		sort.Strings(names)
+	}
+
+	return tree
+}
+
+// members returns the distinct users of a group and all of its descendants.
+func (t *groupTree) members(name string, seen map[string]bool, users map[string]bool) {
+	if seen[name] {
+		return
+	}
+	seen[name] = true
+
+	for _, u := range t.groups[name].Users {
+		users[u] = true
+	}
+	for _, child := range t.children[name] {
+		t.members(child, seen, users)
+	}
+}
+
+// walk appends the group and its descendants to nodes in depth-first order.
+func (t *groupTree) walk(ctx context.Context, name string, parentPath []string, nodes *[]GroupTreeNodeModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	for _, p := range parentPath {
+		if p == name {
+			return diags
+		}
+	}
+
+	g := t.groups[name]
+	groupPath := append(append([]string{}, parentPath...), name)
+
+	parentName := ""
+	if parent, ok := t.groups[g.ParentId]; ok && len(parentPath) > 0 {
+		parentName = parent.DisplayName
+	}
+
+	key := g.Key
+	if key == "" {
+		key = g.Name
+	}
+
+	children := t.children[name]
+	if children == nil {
+		children = []string{}
+	}
+	users := g.Users
+	if users == nil {
+		users = []string{}
+	}
+
+	total := map[string]bool{}
+	t.members(name, map[string]bool{}, total)
+
+	pathList, d := types.ListValueFrom(ctx, types.StringType, groupPath)
+	diags.Append(d...)
+	childrenList, d := types.ListValueFrom(ctx, types.StringType, children)
+	diags.Append(d...)
+	usersList, d := types.ListValueFrom(ctx, types.StringType, users)
+	diags.Append(d...)
+	if diags.HasError() {
+		return diags
+	}
+
+	*nodes = append(*nodes, GroupTreeNodeModel{
+		Name:             types.StringValue(g.Name),
+		DisplayName:      types.StringValue(g.DisplayName),
+		Type:             types.StringValue(g.Type),
+		ParentId:         types.StringValue(g.ParentId),
+		ParentName:       types.StringValue(parentName),
+		Key:              types.StringValue(key),
+		IsTopGroup:       types.BoolValue(g.IsTopGroup),
+		IsEnabled:        types.BoolValue(g.IsEnabled),
+		HaveChildren:     types.BoolValue(len(children) > 0),
+		Depth:            types.Int64Value(int64(len(parentPath))),
+		Path:             pathList,
+		Children:         childrenList,
+		Users:            usersList,
+		MemberCount:      types.Int64Value(int64(len(users))),
+		TotalMemberCount: types.Int64Value(int64(len(total))),
+	})
+
+	for _, child := range children {
+		diags.Append(t.walk(ctx, child, groupPath, nodes)...)
+		if diags.HasError() {
+			return diags
+		}
+	}
+
+	return diags
+}
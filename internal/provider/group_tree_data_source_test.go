@@ -0,0 +1,73 @@
+// Copyright (c) HashiCorp, Inc.
+
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccGroupTreeDataSource_basic(t *testing.T) {
+	config := setupTestConfig(t)
+	rName := "tf-test-" + acctest.RandStringFromCharSet(8, acctest.CharSetAlphaNum)
+	dataSourceName := "data.casdoor_group_tree.test"
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories(config),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(config) + testAccGroupTreeDataSourceConfig(rName),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(dataSourceName, "id", "built-in"),
+					resource.TestCheckTypeSetElemAttr(dataSourceName, "roots.*", rName+"-parent"),
+					resource.TestCheckTypeSetElemNestedAttrs(dataSourceName, "groups.*", map[string]string{
+						"name":               rName + "-parent",
+						"depth":              "0",
+						"have_children":      "true",
+						"children.#":         "1",
+						"children.0":         rName + "-child",
+						"member_count":       "0",
+						"total_member_count": "0",
+					}),
+					resource.TestCheckTypeSetElemNestedAttrs(dataSourceName, "groups.*", map[string]string{
+						"name":          rName + "-child",
+						"parent_id":     rName + "-parent",
+						"parent_name":   "Parent Group",
+						"depth":         "1",
+						"path.#":        "2",
+						"path.0":        rName + "-parent",
+						"path.1":        rName + "-child",
+						"have_children": "false",
+					}),
+				),
+			},
+		},
+	})
+}
+
+func testAccGroupTreeDataSourceConfig(name string) string {
+	return fmt.Sprintf(`
+resource "casdoor_group" "parent" {
+  owner        = "built-in"
+  name         = "%[1]s-parent"
+  display_name = "Parent Group"
+  parent_id    = "built-in"
+}
+
+resource "casdoor_group" "child" {
+  owner        = "built-in"
+  name         = "%[1]s-child"
+  display_name = "Child Group"
+  parent_id    = casdoor_group.parent.name
+}
+
+data "casdoor_group_tree" "test" {
+  owner = "built-in"
+
+  depends_on = [casdoor_group.child]
+}
+`, name)
+}
@@ -0,0 +1,87 @@
+// Copyright (c) HashiCorp, Inc.
+
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+const (
+	// healthPollInterval is how long waitForReady waits between health checks.
+	healthPollInterval = 2 * time.Second
+	// healthCheckTimeout bounds a single health check.
+	healthCheckTimeout = 10 * time.Second
+)
+
+// checkHealth calls the /api/health endpoint of the Casdoor server at
+// endpoint and returns an error unless it reports itself healthy.
+func checkHealth(ctx context.Context, endpoint string) error {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+"/api/health", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create health request: %w", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("health request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("health check failed with status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Status string `json:"status"`
+		Msg    string `json:"msg"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("failed to decode health response: %w", err)
+	}
+	if result.Status != "ok" {
+		return fmt.Errorf("health check failed: %s", result.Msg)
+	}
+
+	return nil
+}
+
+// waitForReady polls the health endpoints of the Casdoor servers at
+// endpoints until any of them reports itself healthy, for example while
+// Casdoor is still being deployed, and gives up after timeout.
+func waitForReady(ctx context.Context, endpoints []string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	for attempt := 1; ; attempt++ {
+		var errs []error
+		for _, endpoint := range endpoints {
+			err := checkHealth(ctx, endpoint)
+			if err == nil {
+				return nil
+			}
+
+			tflog.Debug(ctx, "Casdoor is not ready yet", map[string]any{
+				"endpoint": endpoint,
+				"attempt":  attempt,
+				"error":    err.Error(),
+			})
+			errs = append(errs, fmt.Errorf("%s: %w", endpoint, err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("Casdoor was not ready within %s: %w", timeout, errors.Join(errs...))
+		case <-time.After(healthPollInterval):
+		}
+	}
+}
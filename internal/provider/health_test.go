@@ -0,0 +1,56 @@
+// Copyright (c) HashiCorp, Inc.
+
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newHealthServer starts a server answering /api/health as a Casdoor server
+// that is healthy or not.
+func newHealthServer(t *testing.T, healthy bool) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !healthy {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"ok","msg":""}`))
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestWaitForReady(t *testing.T) {
+	down := newHealthServer(t, false)
+	up := newHealthServer(t, true)
+
+	tests := []struct {
+		name      string
+		endpoints []string
+		wantErr   bool
+	}{
+		{name: "healthy", endpoints: []string{up.URL}},
+		{name: "any healthy endpoint", endpoints: []string{down.URL, up.URL}},
+		{name: "none healthy", endpoints: []string{down.URL, down.URL}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := waitForReady(context.Background(), tt.endpoints, 500*time.Millisecond)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if err != nil && !strings.Contains(err.Error(), down.URL) {
+				t.Errorf("Expected the error to name the endpoint, got %v", err)
+			}
+		})
+	}
+}
@@ -8,8 +8,10 @@ import (
 	"time"
 
 	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
 	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
@@ -25,58 +27,62 @@ var (
 	_ resource.Resource                = &IdpResource{}
 	_ resource.ResourceWithConfigure   = &IdpResource{}
 	_ resource.ResourceWithImportState = &IdpResource{}
+	_ resource.ResourceWithModifyPlan  = &IdpResource{}
 )
 
 type IdpResource struct {
-	client *casdoorsdk.Client
+	client       casdoorClient
+	providerData *CasdoorProviderData
 }
 
 type IdpResourceModel struct {
-	ID                     types.String `tfsdk:"id"`
-	Owner                  types.String `tfsdk:"owner"`
-	Name                   types.String `tfsdk:"name"`
-	DisplayName            types.String `tfsdk:"display_name"`
-	Category               types.String `tfsdk:"category"`
-	Type                   types.String `tfsdk:"type"`
-	SubType                types.String `tfsdk:"sub_type"`
-	Method                 types.String `tfsdk:"method"`
-	ClientID               types.String `tfsdk:"client_id"`
-	ClientSecret           types.String `tfsdk:"client_secret"`
-	ClientID2              types.String `tfsdk:"client_id_2"`
-	ClientSecret2          types.String `tfsdk:"client_secret_2"`
-	Cert                   types.String `tfsdk:"cert"`
-	CustomAuthURL          types.String `tfsdk:"custom_auth_url"`
-	CustomTokenURL         types.String `tfsdk:"custom_token_url"`
-	CustomUserInfoURL      types.String `tfsdk:"custom_user_info_url"`
-	CustomLogo             types.String `tfsdk:"custom_logo"`
-	Scopes                 types.String `tfsdk:"scopes"`
-	UserMapping            types.Map    `tfsdk:"user_mapping"`
-	Host                   types.String `tfsdk:"host"`
-	Port                   types.Int64  `tfsdk:"port"`
-	DisableSSL             types.Bool   `tfsdk:"disable_ssl"`
-	Title                  types.String `tfsdk:"title"`
-	Content                types.String `tfsdk:"content"`
-	Receiver               types.String `tfsdk:"receiver"`
-	RegionID               types.String `tfsdk:"region_id"`
-	SignName               types.String `tfsdk:"sign_name"`
-	TemplateCode           types.String `tfsdk:"template_code"`
-	AppID                  types.String `tfsdk:"app_id"`
-	Endpoint               types.String `tfsdk:"endpoint"`
-	IntranetEndpoint       types.String `tfsdk:"intranet_endpoint"`
-	Domain                 types.String `tfsdk:"domain"`
-	Bucket                 types.String `tfsdk:"bucket"`
-	PathPrefix             types.String `tfsdk:"path_prefix"`
-	Metadata               types.String `tfsdk:"metadata"`
-	IdP                    types.String `tfsdk:"idp"`
-	IssuerURL              types.String `tfsdk:"issuer_url"`
-	EnableSignAuthnRequest types.Bool   `tfsdk:"enable_sign_authn_request"`
-	ProviderURL            types.String `tfsdk:"provider_url"`
-	CreatedTime            types.String `tfsdk:"created_time"`
-	HttpHeaders            types.Map    `tfsdk:"http_headers"`
-	EmailRegex             types.String `tfsdk:"email_regex"`
-	EnableProxy            types.Bool   `tfsdk:"enable_proxy"`
-	EnablePkce             types.Bool   `tfsdk:"enable_pkce"`
-	SslMode                types.String `tfsdk:"ssl_mode"`
+	ID                     types.String   `tfsdk:"id"`
+	Owner                  types.String   `tfsdk:"owner"`
+	Name                   types.String   `tfsdk:"name"`
+	DisplayName            types.String   `tfsdk:"display_name"`
+	Category               types.String   `tfsdk:"category"`
+	Type                   types.String   `tfsdk:"type"`
+	SubType                types.String   `tfsdk:"sub_type"`
+	Method                 types.String   `tfsdk:"method"`
+	ClientID               types.String   `tfsdk:"client_id"`
+	ClientSecret           types.String   `tfsdk:"client_secret"`
+	ClientID2              types.String   `tfsdk:"client_id_2"`
+	ClientSecret2          types.String   `tfsdk:"client_secret_2"`
+	Cert                   types.String   `tfsdk:"cert"`
+	CustomAuthURL          types.String   `tfsdk:"custom_auth_url"`
+	CustomTokenURL         types.String   `tfsdk:"custom_token_url"`
+	CustomUserInfoURL      types.String   `tfsdk:"custom_user_info_url"`
+	CustomLogo             types.String   `tfsdk:"custom_logo"`
+	Scopes                 types.String   `tfsdk:"scopes"`
+	UserMapping            types.Map      `tfsdk:"user_mapping"`
+	Host                   types.String   `tfsdk:"host"`
+	Port                   types.Int64    `tfsdk:"port"`
+	DisableSSL             types.Bool     `tfsdk:"disable_ssl"`
+	Title                  types.String   `tfsdk:"title"`
+	Content                types.String   `tfsdk:"content"`
+	Receiver               types.String   `tfsdk:"receiver"`
+	RegionID               types.String   `tfsdk:"region_id"`
+	SignName               types.String   `tfsdk:"sign_name"`
+	TemplateCode           types.String   `tfsdk:"template_code"`
+	AppID                  types.String   `tfsdk:"app_id"`
+	Endpoint               types.String   `tfsdk:"endpoint"`
+	IntranetEndpoint       types.String   `tfsdk:"intranet_endpoint"`
+	Domain                 types.String   `tfsdk:"domain"`
+	Bucket                 types.String   `tfsdk:"bucket"`
+	PathPrefix             types.String   `tfsdk:"path_prefix"`
+	Metadata               types.String   `tfsdk:"metadata"`
+	IdP                    types.String   `tfsdk:"idp"`
+	IssuerURL              types.String   `tfsdk:"issuer_url"`
+	EnableSignAuthnRequest types.Bool     `tfsdk:"enable_sign_authn_request"`
+	ProviderURL            types.String   `tfsdk:"provider_url"`
+	CreatedTime            types.String   `tfsdk:"created_time"`
+	HttpHeaders            types.Map      `tfsdk:"http_headers"`
+	EmailRegex             types.String   `tfsdk:"email_regex"`
+	EnableProxy            types.Bool     `tfsdk:"enable_proxy"`
+	EnablePkce             types.Bool     `tfsdk:"enable_pkce"`
+	SslMode                types.String   `tfsdk:"ssl_mode"`
+	AdoptExisting          types.Bool     `tfsdk:"adopt_existing"`
+	Timeouts               timeouts.Value `tfsdk:"timeouts"`
 }
 
 func NewIdpResource() resource.Resource {
@@ -87,7 +93,7 @@ func (r *IdpResource) Metadata(_ context.Context, req resource.MetadataRequest,
 	resp.TypeName = req.ProviderTypeName + "_provider"
 }
 
-func (r *IdpResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+func (r *IdpResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		Description: "Manages a Casdoor identity provider (OAuth, SAML, etc.).",
 		Attributes: map[string]schema.Attribute{
@@ -95,22 +101,13 @@ func (r *IdpResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *
 				Description: "The ID of the identity provider in the format 'owner/name'.",
 				Computed:    true,
 				PlanModifiers: []planmodifier.String{
-					stringplanmodifier.UseStateForUnknown(),
-				},
-			},
-			"owner": schema.StringAttribute{
-				Description: "The organization that owns this provider.",
-				Required:    true,
-				PlanModifiers: []planmodifier.String{
-					stringplanmodifier.RequiresReplace(),
+					useStateUnlessChanged(path.Root("name")),
 				},
 			},
+			"owner": ownerAttribute("provider"),
 			"name": schema.StringAttribute{
-				Description: "The unique name of the provider.",
+				Description: "The unique name of the provider. Changing it renames the provider in place.",
 				Required:    true,
-				PlanModifiers: []planmodifier.String{
-					stringplanmodifier.RequiresReplace(),
-				},
 			},
 			"created_time": schema.StringAttribute{
 				Description: "The time when the provider was created.",
@@ -365,6 +362,15 @@ func (r *IdpResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *
 				Computed:    true,
 				Default:     stringdefault.StaticString(""),
 			},
+			"adopt_existing": adoptExistingAttribute("provider"),
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Read:   true,
+				Update: true,
+				Delete: true,
+			}),
 		},
 	}
 }
@@ -374,16 +380,26 @@ func (r *IdpResource) Configure(_ context.Context, req resource.ConfigureRequest
 		return
 	}
 
-	client, ok := req.ProviderData.(*casdoorsdk.Client)
+	data, ok := req.ProviderData.(*CasdoorProviderData)
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Resource Configure Type",
-			fmt.Sprintf("Expected *casdoorsdk.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected *CasdoorProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 		return
 	}
 
-	r.client = client
+	r.client = data.Client
+	r.providerData = data
+}
+
+func (r *IdpResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	planDefaultOwner(ctx, req, resp, r.providerData)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	planAdoption(ctx, req, resp, r.providerData, "provider", r.Read)
 }
 
 func idpPlanToSDK(ctx context.Context, plan IdpResourceModel, createdTime string) (*casdoorsdk.Provider, diag.Diagnostics) {
@@ -456,6 +472,10 @@ func idpPlanToSDK(ctx context.Context, plan IdpResourceModel, createdTime string
 }
 
 func (r *IdpResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	if checkUnconfigured(r.client, &resp.Diagnostics) {
+		return
+	}
+
 	var plan IdpResourceModel
 
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
@@ -463,6 +483,12 @@ func (r *IdpResource) Create(ctx context.Context, req resource.CreateRequest, re
 		return
 	}
 
+	ctx, done := startOperation(ctx, plan.Timeouts.Create, defaultOperationTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	createdTime := plan.CreatedTime.ValueString()
 	if createdTime == "" {
 		createdTime = time.Now().UTC().Format(time.RFC3339)
@@ -474,13 +500,20 @@ func (r *IdpResource) Create(ctx context.Context, req resource.CreateRequest, re
 		return
 	}
 
-	ok, err := r.client.AddProvider(provider)
-	if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("creating provider %q", plan.Name.ValueString())) {
+	adopted := adoptOnCreate(ctx, r.providerData, req.Plan, req.Config, &resp.Diagnostics, "provider", plan.Owner.ValueString()+"/"+plan.Name.ValueString(), provider, r.client.Providers())
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
+	if !adopted {
+		ok, err := r.client.Providers().Add(ctx, provider)
+		if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("creating provider %q", plan.Name.ValueString())) {
+			return
+		}
+	}
+
 	// Read back the provider to get server-generated values.
-	createdProvider, err := r.client.GetProvider(plan.Owner.ValueString() + "/" + plan.Name.ValueString())
+	createdProvider, err := r.client.Providers().Get(ctx, plan.Owner.ValueString()+"/"+plan.Name.ValueString())
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Reading Provider",
@@ -498,6 +531,10 @@ func (r *IdpResource) Create(ctx context.Context, req resource.CreateRequest, re
 }
 
 func (r *IdpResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	if r.client == nil {
+		return
+	}
+
 	var state IdpResourceModel
 
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
@@ -505,7 +542,13 @@ func (r *IdpResource) Read(ctx context.Context, req resource.ReadRequest, resp *
 		return
 	}
 
-	provider, err := r.client.GetProvider(state.Owner.ValueString() + "/" + state.Name.ValueString())
+	ctx, done := startOperation(ctx, state.Timeouts.Read, defaultReadTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	provider, err := r.client.Providers().Get(ctx, state.Owner.ValueString()+"/"+state.Name.ValueString())
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Reading Provider",
@@ -589,17 +632,33 @@ func (r *IdpResource) Read(ctx context.Context, req resource.ReadRequest, resp *
 		return
 	}
 
+	resp.Diagnostics.Append(setObjectFingerprint(ctx, resp.Private, provider)...)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
 }
 
 func (r *IdpResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	var plan IdpResourceModel
+	if checkUnconfigured(r.client, &resp.Diagnostics) {
+		return
+	}
+
+	var plan, state IdpResourceModel
 
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, done := startOperation(ctx, plan.Timeouts.Update, defaultOperationTimeout, &resp.Diagnostics)
+	defer done()
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
+	if checkUpdateConflict(ctx, r.providerData, &resp.Diagnostics, req.Private, "provider", state.Owner.ValueString()+"/"+state.Name.ValueString(), r.client.Providers().Get) {
+		return
+	}
+
 	var userMapping map[string]string
 	if !plan.UserMapping.IsNull() {
 		userMapping = make(map[string]string)
@@ -664,16 +723,20 @@ func (r *IdpResource) Update(ctx context.Context, req resource.UpdateRequest, re
 		SslMode:                plan.SslMode.ValueString(),
 	}
 
-	ok, err := r.client.UpdateProvider(provider)
-	if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("updating provider %q", plan.Name.ValueString())) {
+	if renameUpdate(ctx, resp, r.client, "provider", plan.Owner.ValueString(), state.Name.ValueString(), plan.Name.ValueString(), provider) {
 		return
 	}
 
 	plan.ID = types.StringValue(plan.Owner.ValueString() + "/" + plan.Name.ValueString())
+	resp.Diagnostics.Append(clearObjectFingerprint(ctx, resp.Private)...)
 	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
 }
 
 func (r *IdpResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	if checkUnconfigured(r.client, &resp.Diagnostics) {
+		return
+	}
+
 	var state IdpResourceModel
 
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
@@ -681,12 +744,18 @@ func (r *IdpResource) Delete(ctx context.Context, req resource.DeleteRequest, re
 		return
 	}
 
+	ctx, done := startOperation(ctx, state.Timeouts.Delete, defaultOperationTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	provider := &casdoorsdk.Provider{
 		Owner: state.Owner.ValueString(),
 		Name:  state.Name.ValueString(),
 	}
 
-	ok, err := r.client.DeleteProvider(provider)
+	ok, err := r.client.Providers().Delete(ctx, provider)
 	if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("deleting provider %q", state.Name.ValueString())) {
 		return
 	}
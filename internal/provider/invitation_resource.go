@@ -0,0 +1,472 @@
+// Copyright (c) HashiCorp, Inc.
+
+package provider
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"time"
+
+	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64default"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ resource.Resource                = &InvitationResource{}
+	_ resource.ResourceWithConfigure   = &InvitationResource{}
+	_ resource.ResourceWithImportState = &InvitationResource{}
+	_ resource.ResourceWithModifyPlan  = &InvitationResource{}
+)
+
+type InvitationResource struct {
+	client       casdoorClient
+	providerData *CasdoorProviderData
+}
+
+type InvitationResourceModel struct {
+	ID            types.String   `tfsdk:"id"`
+	Owner         types.String   `tfsdk:"owner"`
+	Name          types.String   `tfsdk:"name"`
+	CreatedTime   types.String   `tfsdk:"created_time"`
+	UpdatedTime   types.String   `tfsdk:"updated_time"`
+	DisplayName   types.String   `tfsdk:"display_name"`
+	Code          types.String   `tfsdk:"code"`
+	IsRegexp      types.Bool     `tfsdk:"is_regexp"`
+	DefaultCode   types.String   `tfsdk:"default_code"`
+	Quota         types.Int64    `tfsdk:"quota"`
+	UsedCount     types.Int64    `tfsdk:"used_count"`
+	Application   types.String   `tfsdk:"application"`
+	SignupGroup   types.String   `tfsdk:"signup_group"`
+	Username      types.String   `tfsdk:"username"`
+	Email         types.String   `tfsdk:"email"`
+	Phone         types.String   `tfsdk:"phone"`
+	State         types.String   `tfsdk:"state"`
+	AdoptExisting types.Bool     `tfsdk:"adopt_existing"`
+	Timeouts      timeouts.Value `tfsdk:"timeouts"`
+}
+
+func NewInvitationResource() resource.Resource {
+	return &InvitationResource{}
+}
+
+func (r *InvitationResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_invitation"
+}
+
+func (r *InvitationResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Manages a Casdoor invitation, a code that lets users sign up to an organization.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "The ID of the invitation in the format 'owner/name'.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"owner": ownerAttribute("invitation"),
+			"name": schema.StringAttribute{
+				Description: "The unique name of the invitation.",
+				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"created_time": schema.StringAttribute{
+				Description: "The time when the invitation was created.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"updated_time": schema.StringAttribute{
+				Description: "The time when the invitation was last updated.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"display_name": schema.StringAttribute{
+				Description: "The display name of the invitation.",
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString(""),
+			},
+			"code": schema.StringAttribute{
+				Description: "The invitation code users sign up with, or a regular expression matching the codes if is_regexp is set. A random code is generated if not set.",
+				Optional:    true,
+				Computed:    true,
+				Sensitive:   true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"is_regexp": schema.BoolAttribute{
+				Description: "Whether code is a regular expression rather than a single code.",
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+			},
+			"default_code": schema.StringAttribute{
+				Description: "The code filled in on the signup page opened from the invitation link. Defaults to code.",
+				Optional:    true,
+				Computed:    true,
+				Sensitive:   true,
+				PlanModifiers: []planmodifier.String{
+					useStateUnlessChanged(path.Root("code")),
+				},
+			},
+			"quota": schema.Int64Attribute{
+				Description: "How many users can sign up with the invitation.",
+				Optional:    true,
+				Computed:    true,
+				Default:     int64default.StaticInt64(1),
+			},
+			"used_count": schema.Int64Attribute{
+				Description: "How many users have signed up with the invitation. Maintained by Casdoor.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.UseStateForUnknown(),
+				},
+			},
+			"application": schema.StringAttribute{
+				Description: "The application users can sign up to with the invitation, or 'All' for any application.",
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString("All"),
+			},
+			"signup_group": schema.StringAttribute{
+				Description: "The group users who sign up with the invitation are added to.",
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString(""),
+			},
+			"username": schema.StringAttribute{
+				Description: "The username users must sign up with, if set.",
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString(""),
+			},
+			"email": schema.StringAttribute{
+				Description: "The email address users must sign up with, if set.",
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString(""),
+			},
+			"phone": schema.StringAttribute{
+				Description: "The phone number users must sign up with, if set.",
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString(""),
+			},
+			"state": schema.StringAttribute{
+				Description: "The state of the invitation, 'Active' or 'Suspended'. Suspended invitations cannot be used to sign up.",
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString("Active"),
+			},
+			"adopt_existing": adoptExistingAttribute("invitation"),
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Read:   true,
+				Update: true,
+				Delete: true,
+			}),
+		},
+	}
+}
+
+func (r *InvitationResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*CasdoorProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *CasdoorProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.client = data.Client
+	r.providerData = data
+}
+
+func (r *InvitationResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	planDefaultOwner(ctx, req, resp, r.providerData)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	planAdoption(ctx, req, resp, r.providerData, "invitation", r.Read)
+}
+
+func invitationPlanToSDK(plan InvitationResourceModel, createdTime, updatedTime string, usedCount int) *casdoorsdk.Invitation {
+	return &casdoorsdk.Invitation{
+		Owner:       plan.Owner.ValueString(),
+		Name:        plan.Name.ValueString(),
+		CreatedTime: createdTime,
+		UpdatedTime: updatedTime,
+		DisplayName: plan.DisplayName.ValueString(),
+		Code:        plan.Code.ValueString(),
+		IsRegexp:    plan.IsRegexp.ValueBool(),
+		DefaultCode: plan.DefaultCode.ValueString(),
+		Quota:       int(plan.Quota.ValueInt64()),
+		UsedCount:   usedCount,
+		Application: plan.Application.ValueString(),
+		SignupGroup: plan.SignupGroup.ValueString(),
+		Username:    plan.Username.ValueString(),
+		Email:       plan.Email.ValueString(),
+		Phone:       plan.Phone.ValueString(),
+		State:       plan.State.ValueString(),
+	}
+}
+
+// invitationWithoutUsage returns a copy of invitation without its used
+// count. Users signing up change the used count, which is no reason to
+// refuse an update, so update conflicts are detected on the rest. Signing up
+// also bumps updatedTime, which is left out too.
+func invitationWithoutUsage(invitation *casdoorsdk.Invitation) *casdoorsdk.Invitation {
+	if invitation == nil {
+		return nil
+	}
+
+	withoutUsage := *invitation
+	withoutUsage.UsedCount = 0
+	withoutUsage.UpdatedTime = ""
+	return &withoutUsage
+}
+
+func (r *InvitationResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	if checkUnconfigured(r.client, &resp.Diagnostics) {
+		return
+	}
+
+	var plan InvitationResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, done := startOperation(ctx, plan.Timeouts.Create, defaultOperationTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	createdTime := plan.CreatedTime.ValueString()
+	if createdTime == "" {
+		createdTime = time.Now().UTC().Format(time.RFC3339)
+	}
+
+	invitation := invitationPlanToSDK(plan, createdTime, "", 0)
+	// Casdoor does not generate codes itself; its web UI does.
+	if plan.Code.IsUnknown() {
+		invitation.Code = rand.Text()
+	}
+	if plan.DefaultCode.IsUnknown() {
+		invitation.DefaultCode = invitation.Code
+	}
+
+	adopted := adoptOnCreate(ctx, r.providerData, req.Plan, req.Config, &resp.Diagnostics, "invitation", plan.Owner.ValueString()+"/"+plan.Name.ValueString(), invitation, r.client.Invitations())
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !adopted {
+		ok, err := r.client.Invitations().Add(ctx, invitation)
+		if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("creating invitation %q", plan.Name.ValueString())) {
+			return
+		}
+	}
+
+	// Read back the invitation to get the code and server-generated values.
+	createdInvitation, err := r.client.Invitations().Get(ctx, plan.Owner.ValueString()+"/"+plan.Name.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading Invitation",
+			fmt.Sprintf("Could not read invitation %q after creation: %s", plan.Name.ValueString(), err),
+		)
+		return
+	}
+
+	if createdInvitation == nil {
+		resp.Diagnostics.AddError(
+			"Error Reading Invitation",
+			fmt.Sprintf("Invitation %q not found after creation", plan.Name.ValueString()),
+		)
+		return
+	}
+
+	plan.CreatedTime = types.StringValue(createdInvitation.CreatedTime)
+	plan.UpdatedTime = types.StringValue(createdInvitation.UpdatedTime)
+	plan.Code = types.StringValue(createdInvitation.Code)
+	plan.DefaultCode = types.StringValue(createdInvitation.DefaultCode)
+	plan.UsedCount = types.Int64Value(int64(createdInvitation.UsedCount))
+
+	plan.ID = types.StringValue(plan.Owner.ValueString() + "/" + plan.Name.ValueString())
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+}
+
+func (r *InvitationResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	if r.client == nil {
+		return
+	}
+
+	var state InvitationResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, done := startOperation(ctx, state.Timeouts.Read, defaultReadTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	invitation, err := r.client.Invitations().Get(ctx, state.Owner.ValueString()+"/"+state.Name.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading Invitation",
+			fmt.Sprintf("Could not read invitation %q: %s", state.Name.ValueString(), err),
+		)
+		return
+	}
+
+	if invitation == nil {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	state.ID = types.StringValue(invitation.Owner + "/" + invitation.Name)
+	state.Owner = types.StringValue(invitation.Owner)
+	state.Name = types.StringValue(invitation.Name)
+	state.CreatedTime = types.StringValue(invitation.CreatedTime)
+	state.UpdatedTime = types.StringValue(invitation.UpdatedTime)
+	state.DisplayName = types.StringValue(invitation.DisplayName)
+	state.Code = types.StringValue(invitation.Code)
+	state.IsRegexp = types.BoolValue(invitation.IsRegexp)
+	state.DefaultCode = types.StringValue(invitation.DefaultCode)
+	state.Quota = types.Int64Value(int64(invitation.Quota))
+	state.UsedCount = types.Int64Value(int64(invitation.UsedCount))
+	state.Application = types.StringValue(invitation.Application)
+	state.SignupGroup = types.StringValue(invitation.SignupGroup)
+	state.Username = types.StringValue(invitation.Username)
+	state.Email = types.StringValue(invitation.Email)
+	state.Phone = types.StringValue(invitation.Phone)
+	state.State = types.StringValue(invitation.State)
+
+	resp.Diagnostics.Append(setObjectFingerprint(ctx, resp.Private, invitationWithoutUsage(invitation))...)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+func (r *InvitationResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	if checkUnconfigured(r.client, &resp.Diagnostics) {
+		return
+	}
+
+	var plan, state InvitationResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, done := startOperation(ctx, plan.Timeouts.Update, defaultOperationTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	id := state.Owner.ValueString() + "/" + state.Name.ValueString()
+	getWithoutUsage := func(ctx context.Context, id string) (*casdoorsdk.Invitation, error) {
+		invitation, err := r.client.Invitations().Get(ctx, id)
+		return invitationWithoutUsage(invitation), err
+	}
+	if checkUpdateConflict(ctx, r.providerData, &resp.Diagnostics, req.Private, "invitation", id, getWithoutUsage) {
+		return
+	}
+
+	// Keep the used count as Casdoor has it now, so that users who signed up
+	// since the last refresh are not forgotten.
+	current, err := r.client.Invitations().Get(ctx, id)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading Invitation",
+			fmt.Sprintf("Could not read invitation %q: %s", plan.Name.ValueString(), err),
+		)
+		return
+	}
+	usedCount := int(state.UsedCount.ValueInt64())
+	if current != nil {
+		usedCount = current.UsedCount
+	}
+
+	invitation := invitationPlanToSDK(plan, plan.CreatedTime.ValueString(), plan.UpdatedTime.ValueString(), usedCount)
+	if plan.DefaultCode.IsUnknown() {
+		invitation.DefaultCode = invitation.Code
+		plan.DefaultCode = types.StringValue(invitation.Code)
+	}
+
+	ok, err := r.client.Invitations().Update(ctx, invitation)
+	if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("updating invitation %q", plan.Name.ValueString())) {
+		return
+	}
+
+	plan.UsedCount = types.Int64Value(int64(usedCount))
+	plan.ID = types.StringValue(plan.Owner.ValueString() + "/" + plan.Name.ValueString())
+	resp.Diagnostics.Append(clearObjectFingerprint(ctx, resp.Private)...)
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+}
+
+func (r *InvitationResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	if checkUnconfigured(r.client, &resp.Diagnostics) {
+		return
+	}
+
+	var state InvitationResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, done := startOperation(ctx, state.Timeouts.Delete, defaultOperationTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	invitation := &casdoorsdk.Invitation{
+		Owner: state.Owner.ValueString(),
+		Name:  state.Name.ValueString(),
+	}
+
+	ok, err := r.client.Invitations().Delete(ctx, invitation)
+	if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("deleting invitation %q", state.Name.ValueString())) {
+		return
+	}
+}
+
+func (r *InvitationResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	importStateOwnerName(ctx, req, resp)
+}
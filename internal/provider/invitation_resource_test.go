@@ -0,0 +1,160 @@
+// Copyright (c) HashiCorp, Inc.
+
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccInvitationResource_basic(t *testing.T) {
+	config := setupTestConfig(t)
+	rName := "tf-test-" + acctest.RandStringFromCharSet(8, acctest.CharSetAlphaNum)
+	resourceName := "casdoor_invitation.test"
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories(config),
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: testAccProviderConfig(config) + testAccInvitationResourceConfig(rName, 5, "Active"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "name", rName),
+					resource.TestCheckResourceAttr(resourceName, "owner", "built-in"),
+					resource.TestCheckResourceAttr(resourceName, "display_name", "Partner Invitation"),
+					resource.TestCheckResourceAttr(resourceName, "quota", "5"),
+					resource.TestCheckResourceAttr(resourceName, "used_count", "0"),
+					resource.TestCheckResourceAttr(resourceName, "application", "All"),
+					resource.TestCheckResourceAttr(resourceName, "email", "partner@example.com"),
+					resource.TestCheckResourceAttr(resourceName, "is_regexp", "false"),
+					resource.TestCheckResourceAttr(resourceName, "state", "Active"),
+					resource.TestCheckResourceAttrSet(resourceName, "code"),
+					resource.TestCheckResourceAttrPair(resourceName, "default_code", resourceName, "code"),
+					resource.TestCheckResourceAttrSet(resourceName, "created_time"),
+				),
+			},
+			// Update and Read testing
+			{
+				Config: testAccProviderConfig(config) + testAccInvitationResourceConfig(rName, 10, "Suspended"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "quota", "10"),
+					resource.TestCheckResourceAttr(resourceName, "state", "Suspended"),
+					resource.TestCheckResourceAttr(resourceName, "used_count", "0"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccInvitationResource_code(t *testing.T) {
+	config := setupTestConfig(t)
+	rName := "tf-test-" + acctest.RandStringFromCharSet(8, acctest.CharSetAlphaNum)
+	resourceName := "casdoor_invitation.test"
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories(config),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(config) + testAccInvitationResourceCodeConfig(rName, "partner-[0-9]+", "partner-1"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "code", "partner-[0-9]+"),
+					resource.TestCheckResourceAttr(resourceName, "is_regexp", "true"),
+					resource.TestCheckResourceAttr(resourceName, "default_code", "partner-1"),
+				),
+			},
+			// Left out of configuration, default_code follows a changed code.
+			{
+				Config: testAccProviderConfig(config) + fmt.Sprintf(`
+resource "casdoor_invitation" "test" {
+  owner = "built-in"
+  name  = %q
+  code  = "partner-2"
+}
+`, rName),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "code", "partner-2"),
+					resource.TestCheckResourceAttr(resourceName, "default_code", "partner-2"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccInvitationResource_import(t *testing.T) {
+	config := setupTestConfig(t)
+	rName := "tf-test-" + acctest.RandStringFromCharSet(8, acctest.CharSetAlphaNum)
+	resourceName := "casdoor_invitation.test"
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories(config),
+		Steps: []resource.TestStep{
+			// Create the resource first
+			{
+				Config: testAccProviderConfig(config) + testAccInvitationResourceConfig(rName, 5, "Active"),
+			},
+			// ImportState testing
+			{
+				Config:            testAccProviderConfig(config) + testAccInvitationResourceConfig(rName, 5, "Active"),
+				ResourceName:      resourceName,
+				ImportState:       true,
+				ImportStateId:     "built-in/" + rName,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func testAccInvitationResourceConfig(name string, quota int, state string) string {
+	return fmt.Sprintf(`
+resource "casdoor_invitation" "test" {
+  owner        = "built-in"
+  name         = %q
+  display_name = "Partner Invitation"
+  quota        = %d
+  email        = "partner@example.com"
+  state        = %q
+}
+`, name, quota, state)
+}
+
+func testAccInvitationResourceCodeConfig(name, code, defaultCode string) string {
+	return fmt.Sprintf(`
+resource "casdoor_invitation" "test" {
+  owner        = "built-in"
+  name         = %q
+  code         = %q
+  is_regexp    = true
+  default_code = %q
+}
+`, name, code, defaultCode)
+}
+
+func TestInvitationWithoutUsage(t *testing.T) {
+	before := &casdoorsdk.Invitation{Owner: "built-in", Name: "invite", Quota: 10, UsedCount: 1, UpdatedTime: "2026-01-01T00:00:00Z"}
+	after := *before
+	after.UsedCount = 2
+	after.UpdatedTime = "2026-01-02T00:00:00Z"
+
+	fingerprint := func(invitation *casdoorsdk.Invitation) string {
+		t.Helper()
+		got, err := objectFingerprint(invitationWithoutUsage(invitation))
+		if err != nil {
+			t.Fatalf("Failed to fingerprint invitation: %v", err)
+		}
+		return got
+	}
+
+	// A sign-up counts the use and bumps updatedTime.
+	if a, b := fingerprint(before), fingerprint(&after); a != b {
+		t.Errorf("Expected a sign-up to keep the fingerprint, got %q and %q", a, b)
+	}
+
+	after.Quota = 20
+	if a, b := fingerprint(before), fingerprint(&after); a == b {
+		t.Errorf("Expected a changed quota to change the fingerprint, got %q for both", a)
+	}
+}
@@ -0,0 +1,214 @@
+// Copyright (c) HashiCorp, Inc.
+
+package provider
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// jwksMinRefreshInterval limits how often an unknown key ID makes
+// jwksKeySet fetch the key set again.
+const jwksMinRefreshInterval = 10 * time.Second
+
+// jwksKeySet holds the keys Casdoor signs JWTs with, discovered from its
+// JSON Web Key Set when no certificate is configured. Keys are cached by key
+// ID; looking up a key ID that is not cached fetches the key set again, so
+// that keys added by a cert rotation are picked up.
+type jwksKeySet struct {
+	endpoint    string
+	application string
+	client      *http.Client
+
+	mu          sync.Mutex
+	jwksURL     string
+	keys        map[string]crypto.PublicKey
+	lastRefresh time.Time
+}
+
+func newJWKSKeySet(endpoint, application string) *jwksKeySet {
+	return &jwksKeySet{
+		endpoint:    endpoint,
+		application: application,
+		client:      &http.Client{Timeout: 30 * time.Second},
+		keys:        make(map[string]crypto.PublicKey),
+	}
+}
+
+// Key returns the public key with the given key ID.
+func (k *jwksKeySet) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if key, ok := k.keys[kid]; ok {
+		return key, nil
+	}
+
+	if time.Since(k.lastRefresh) >= jwksMinRefreshInterval {
+		if err := k.refresh(ctx); err != nil {
+			return nil, err
+		}
+		if key, ok := k.keys[kid]; ok {
+			return key, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no key with ID %q in the JSON Web Key Set of %s", kid, k.endpoint)
+}
+
+// refresh fetches the key set, discovering where it is first. k.mu must be
+// held.
+func (k *jwksKeySet) refresh(ctx context.Context) error {
+	if k.jwksURL == "" {
+		k.jwksURL = k.discover(ctx)
+	}
+
+	var jwks struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := getJSON(ctx, k.client, k.jwksURL, &jwks); err != nil {
+		return fmt.Errorf("failed to fetch JSON Web Key Set: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(jwks.Keys))
+	for _, jwk := range jwks.Keys {
+		key, err := jwk.publicKey()
+		if err != nil {
+			tflog.Warn(ctx, "Skipping unusable JSON Web Key", map[string]any{"kid": jwk.Kid, "error": err.Error()})
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+
+	k.keys = keys
+	k.lastRefresh = time.Now()
+	tflog.Debug(ctx, "Fetched JSON Web Key Set", map[string]any{"url": k.jwksURL, "keys": len(keys)})
+
+	return nil
+}
+
+// discover returns the jwks_uri of the application's OpenID discovery
+// document, or else of the global one, or else Casdoor's well-known JWKS
+// endpoint.
+func (k *jwksKeySet) discover(ctx context.Context) string {
+	documents := []string{k.endpoint + "/.well-known/openid-configuration"}
+	if k.application != "" {
+		documents = append([]string{k.endpoint + "/.well-known/" + k.application + "/openid-configuration"}, documents...)
+	}
+
+	for _, document := range documents {
+		var discovery struct {
+			JwksURI string `json:"jwks_uri"`
+		}
+		if err := getJSON(ctx, k.client, document, &discovery); err == nil && discovery.JwksURI != "" {
+			return discovery.JwksURI
+		}
+	}
+
+	return k.endpoint + "/.well-known/jwks"
+}
+
+// jsonWebKey is a key of a JSON Web Key Set, as Casdoor publishes them.
+type jsonWebKey struct {
+	Kid string   `json:"kid"`
+	Kty string   `json:"kty"`
+	Crv string   `json:"crv"`
+	N   string   `json:"n"`
+	E   string   `json:"e"`
+	X   string   `json:"x"`
+	Y   string   `json:"y"`
+	X5c []string `json:"x5c"`
+}
+
+// publicKey returns the key from its certificate chain if it has one, and
+// from its parameters otherwise.
+func (jwk jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	if len(jwk.X5c) > 0 {
+		der, err := base64.StdEncoding.DecodeString(jwk.X5c[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid x5c: %w", err)
+		}
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return nil, fmt.Errorf("invalid x5c: %w", err)
+		}
+		return cert.PublicKey, nil
+	}
+
+	switch jwk.Kty {
+	case "RSA":
+		n, err := base64URLInt(jwk.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid n: %w", err)
+		}
+		e, err := base64URLInt(jwk.E)
+		if err != nil {
+			return nil, fmt.Errorf("invalid e: %w", err)
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", jwk.Crv)
+		}
+		x, err := base64URLInt(jwk.X)
+		if err != nil {
+			return nil, fmt.Errorf("invalid x: %w", err)
+		}
+		y, err := base64URLInt(jwk.Y)
+		if err != nil {
+			return nil, fmt.Errorf("invalid y: %w", err)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", jwk.Kty)
+	}
+}
+
+func base64URLInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// getJSON fetches url and decodes the JSON response into v.
+func getJSON(ctx context.Context, client *http.Client, url string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: status %d", url, resp.StatusCode)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -0,0 +1,238 @@
+// Copyright (c) HashiCorp, Inc.
+
+package provider
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// testJWKSServer serves a JSON Web Key Set with the RSA keys in keys, by key
+// ID, and counts how often it is fetched.
+type testJWKSServer struct {
+	server *httptest.Server
+
+	mu      sync.Mutex
+	keys    map[string]*rsa.PublicKey
+	fetches int
+	// documents are the OpenID discovery documents served, by path.
+	documents map[string]string
+}
+
+func newTestJWKSServer(t *testing.T) *testJWKSServer {
+	t.Helper()
+
+	s := &testJWKSServer{keys: map[string]*rsa.PublicKey{}, documents: map[string]string{}}
+	s.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		if jwksURI, ok := s.documents[r.URL.Path]; ok {
+			_ = json.NewEncoder(w).Encode(map[string]string{"jwks_uri": s.server.URL + jwksURI})
+			return
+		}
+		if r.URL.Path != "/.well-known/jwks" && r.URL.Path != "/app/jwks" {
+			http.NotFound(w, r)
+			return
+		}
+
+		s.fetches++
+		keys := []map[string]string{}
+		for kid, key := range s.keys {
+			keys = append(keys, map[string]string{
+				"kid": kid,
+				"kty": "RSA",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": keys})
+	}))
+	t.Cleanup(s.server.Close)
+
+	return s
+}
+
+func (s *testJWKSServer) addKey(t *testing.T, kid string) *rsa.PrivateKey {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys[kid] = &key.PublicKey
+
+	return key
+}
+
+func (s *testJWKSServer) fetchCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.fetches
+}
+
+func TestJWKSKeySet_cache(t *testing.T) {
+	ctx := context.Background()
+	server := newTestJWKSServer(t)
+	first := server.addKey(t, "first")
+	keys := newJWKSKeySet(server.server.URL, "")
+
+	key, err := keys.Key(ctx, "first")
+	if err != nil {
+		t.Fatalf("Failed to get key: %v", err)
+	}
+	if !first.PublicKey.Equal(key) {
+		t.Errorf("Expected the served key, got %v", key)
+	}
+
+	// A cached key is not fetched again.
+	if _, err := keys.Key(ctx, "first"); err != nil {
+		t.Fatalf("Failed to get cached key: %v", err)
+	}
+	if got := server.fetchCount(); got != 1 {
+		t.Errorf("Expected 1 fetch, got %d", got)
+	}
+
+	// An unknown key ID refetches the key set, but not more often than
+	// jwksMinRefreshInterval.
+	rotated := server.addKey(t, "rotated")
+	if _, err := keys.Key(ctx, "rotated"); err == nil {
+		t.Errorf("Expected no refresh within %s of the last one", jwksMinRefreshInterval)
+	}
+	if got := server.fetchCount(); got != 1 {
+		t.Errorf("Expected 1 fetch, got %d", got)
+	}
+
+	keys.lastRefresh = time.Now().Add(-jwksMinRefreshInterval)
+	key, err = keys.Key(ctx, "rotated")
+	if err != nil {
+		t.Fatalf("Failed to get rotated key: %v", err)
+	}
+	if !rotated.PublicKey.Equal(key) {
+		t.Errorf("Expected the rotated key, got %v", key)
+	}
+	if got := server.fetchCount(); got != 2 {
+		t.Errorf("Expected 2 fetches, got %d", got)
+	}
+}
+
+func TestJWKSKeySet_discover(t *testing.T) {
+	tests := []struct {
+		name        string
+		application string
+		documents   map[string]string
+		want        string
+	}{
+		{
+			name:        "application document",
+			application: "app",
+			documents: map[string]string{
+				"/.well-known/app/openid-configuration": "/app/jwks",
+				"/.well-known/openid-configuration":     "/.well-known/jwks",
+			},
+			want: "/app/jwks",
+		},
+		{
+			name:        "global document",
+			application: "app",
+			documents:   map[string]string{"/.well-known/openid-configuration": "/app/jwks"},
+			want:        "/app/jwks",
+		},
+		{
+			name: "well-known key set",
+			want: "/.well-known/jwks",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newTestJWKSServer(t)
+			server.documents = tt.documents
+
+			keys := newJWKSKeySet(server.server.URL, tt.application)
+			if got := keys.discover(context.Background()); got != server.server.URL+tt.want {
+				t.Errorf("Expected %q, got %q", server.server.URL+tt.want, got)
+			}
+		})
+	}
+}
+
+func TestJSONWebKey_publicKey(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	key, err := jsonWebKey{
+		Kty: "EC",
+		Crv: "P-256",
+		X:   base64.RawURLEncoding.EncodeToString(ecKey.X.Bytes()),
+		Y:   base64.RawURLEncoding.EncodeToString(ecKey.Y.Bytes()),
+	}.publicKey()
+	if err != nil {
+		t.Fatalf("Failed to read EC key: %v", err)
+	}
+	if !ecKey.PublicKey.Equal(key) {
+		t.Errorf("Expected the EC key, got %v", key)
+	}
+
+	if _, err := (jsonWebKey{Kty: "oct"}).publicKey(); err == nil {
+		t.Errorf("Expected an error for an unsupported key type")
+	}
+	if _, err := (jsonWebKey{Kty: "EC", Crv: "P-224"}).publicKey(); err == nil {
+		t.Errorf("Expected an error for an unsupported curve")
+	}
+}
+
+func TestSDKClient_ParseJwtToken(t *testing.T) {
+	server := newTestJWKSServer(t)
+	key := server.addKey(t, "cert-built-in")
+	client := newSDKClient(casdoorsdk.NewClient(server.server.URL, "id", "secret", "", "built-in", "app-built-in"))
+
+	sign := func(kid string) string {
+		t.Helper()
+
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, casdoorsdk.Claims{
+			User: casdoorsdk.User{Owner: "built-in", Name: "alice"},
+			RegisteredClaims: jwt.RegisteredClaims{
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+			},
+		})
+		token.Header["kid"] = kid
+		signed, err := token.SignedString(key)
+		if err != nil {
+			t.Fatalf("Failed to sign token: %v", err)
+		}
+		return signed
+	}
+
+	claims, err := client.ParseJwtToken(context.Background(), sign("cert-built-in"))
+	if err != nil {
+		t.Fatalf("Failed to verify token: %v", err)
+	}
+	if claims.Name != "alice" {
+		t.Errorf("Expected name %q, got %q", "alice", claims.Name)
+	}
+
+	if _, err := client.ParseJwtToken(context.Background(), sign("unknown")); err == nil {
+		t.Errorf("Expected a token signed with an unknown key ID to be refused")
+	}
+}
@@ -5,9 +5,11 @@ package provider
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
 	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
@@ -25,31 +27,35 @@ var (
 	_ resource.Resource                = &LdapResource{}
 	_ resource.ResourceWithConfigure   = &LdapResource{}
 	_ resource.ResourceWithImportState = &LdapResource{}
+	_ resource.ResourceWithModifyPlan  = &LdapResource{}
 )
 
 type LdapResource struct {
-	client *casdoorsdk.Client
+	client       casdoorClient
+	providerData *CasdoorProviderData
 }
 
 type LdapResourceModel struct {
-	Id                  types.String `tfsdk:"id"`
-	Owner               types.String `tfsdk:"owner"`
-	CreatedTime         types.String `tfsdk:"created_time"`
-	ServerName          types.String `tfsdk:"server_name"`
-	Host                types.String `tfsdk:"host"`
-	Port                types.Int64  `tfsdk:"port"`
-	EnableSsl           types.Bool   `tfsdk:"enable_ssl"`
-	AllowSelfSignedCert types.Bool   `tfsdk:"allow_self_signed_cert"`
-	Username            types.String `tfsdk:"username"`
-	Password            types.String `tfsdk:"password"`
-	BaseDn              types.String `tfsdk:"base_dn"`
-	Filter              types.String `tfsdk:"filter"`
-	FilterFields        types.List   `tfsdk:"filter_fields"`
-	DefaultGroup        types.String `tfsdk:"default_group"`
-	PasswordType        types.String `tfsdk:"password_type"`
-	CustomAttributes    types.Map    `tfsdk:"custom_attributes"`
-	AutoSync            types.Int64  `tfsdk:"auto_sync"`
-	LastSync            types.String `tfsdk:"last_sync"`
+	Id                  types.String   `tfsdk:"id"`
+	Owner               types.String   `tfsdk:"owner"`
+	CreatedTime         types.String   `tfsdk:"created_time"`
+	ServerName          types.String   `tfsdk:"server_name"`
+	Host                types.String   `tfsdk:"host"`
+	Port                types.Int64    `tfsdk:"port"`
+	EnableSsl           types.Bool     `tfsdk:"enable_ssl"`
+	AllowSelfSignedCert types.Bool     `tfsdk:"allow_self_signed_cert"`
+	Username            types.String   `tfsdk:"username"`
+	Password            types.String   `tfsdk:"password"`
+	BaseDn              types.String   `tfsdk:"base_dn"`
+	Filter              types.String   `tfsdk:"filter"`
+	FilterFields        types.List     `tfsdk:"filter_fields"`
+	DefaultGroup        types.String   `tfsdk:"default_group"`
+	PasswordType        types.String   `tfsdk:"password_type"`
+	CustomAttributes    types.Map      `tfsdk:"custom_attributes"`
+	AutoSync            types.Int64    `tfsdk:"auto_sync"`
+	LastSync            types.String   `tfsdk:"last_sync"`
+	AdoptExisting       types.Bool     `tfsdk:"adopt_existing"`
+	Timeouts            timeouts.Value `tfsdk:"timeouts"`
 }
 
 func NewLdapResource() resource.Resource {
@@ -60,7 +66,7 @@ func (r *LdapResource) Metadata(_ context.Context, req resource.MetadataRequest,
 	resp.TypeName = req.ProviderTypeName + "_ldap"
 }
 
-func (r *LdapResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+func (r *LdapResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		Description: "Manages a Casdoor LDAP configuration for user synchronization.",
 		Attributes: map[string]schema.Attribute{
@@ -71,13 +77,7 @@ func (r *LdapResource) Schema(_ context.Context, _ resource.SchemaRequest, resp
 					stringplanmodifier.RequiresReplace(),
 				},
 			},
-			"owner": schema.StringAttribute{
-				Description: "The organization that owns this LDAP configuration.",
-				Required:    true,
-				PlanModifiers: []planmodifier.String{
-					stringplanmodifier.RequiresReplace(),
-				},
-			},
+			"owner": adminOwnerAttribute("LDAP configuration"),
 			"created_time": schema.StringAttribute{
 				Description: "The time when the LDAP configuration was created.",
 				Computed:    true,
@@ -165,6 +165,15 @@ func (r *LdapResource) Schema(_ context.Context, _ resource.SchemaRequest, resp
 					stringplanmodifier.UseStateForUnknown(),
 				},
 			},
+			"adopt_existing": adoptExistingAttribute("LDAP server"),
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Read:   true,
+				Update: true,
+				Delete: true,
+			}),
 		},
 	}
 }
@@ -174,16 +183,21 @@ func (r *LdapResource) Configure(_ context.Context, req resource.ConfigureReques
 		return
 	}
 
-	client, ok := req.ProviderData.(*casdoorsdk.Client)
+	data, ok := req.ProviderData.(*CasdoorProviderData)
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Resource Configure Type",
-			fmt.Sprintf("Expected *casdoorsdk.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected *CasdoorProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 		return
 	}
 
-	r.client = client
+	r.client = data.Client
+	r.providerData = data
+}
+
+func (r *LdapResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	planAdoption(ctx, req, resp, r.providerData, "LDAP server", r.Read)
 }
 
 func ldapPlanToSDK(ctx context.Context, plan LdapResourceModel, createdTime, lastSync string) (*casdoorsdk.Ldap, diag.Diagnostics) {
@@ -228,6 +242,10 @@ func ldapPlanToSDK(ctx context.Context, plan LdapResourceModel, createdTime, las
 }
 
 func (r *LdapResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	if checkUnconfigured(r.client, &resp.Diagnostics) {
+		return
+	}
+
 	var plan LdapResourceModel
 
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
@@ -235,6 +253,12 @@ func (r *LdapResource) Create(ctx context.Context, req resource.CreateRequest, r
 		return
 	}
 
+	ctx, done := startOperation(ctx, plan.Timeouts.Create, defaultOperationTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	createdTime := plan.CreatedTime.ValueString()
 	if createdTime == "" {
 		createdTime = time.Now().UTC().Format(time.RFC3339)
@@ -246,13 +270,20 @@ func (r *LdapResource) Create(ctx context.Context, req resource.CreateRequest, r
 		return
 	}
 
-	ok, err := r.client.AddLdap(ldap)
-	if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("creating LDAP %q", plan.Id.ValueString())) {
+	adopted := adoptOnCreate(ctx, r.providerData, req.Plan, req.Config, &resp.Diagnostics, "LDAP server", plan.Owner.ValueString()+"/"+plan.Id.ValueString(), ldap, r.client.Ldaps())
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
+	if !adopted {
+		ok, err := r.client.Ldaps().Add(ctx, ldap)
+		if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("creating LDAP %q", plan.Id.ValueString())) {
+			return
+		}
+	}
+
 	// Read back the LDAP to get server-generated values.
-	createdLdap, err := r.client.GetLdap(plan.Owner.ValueString() + "/" + plan.Id.ValueString())
+	createdLdap, err := r.client.Ldaps().Get(ctx, plan.Owner.ValueString()+"/"+plan.Id.ValueString())
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Reading LDAP",
@@ -277,6 +308,10 @@ func (r *LdapResource) Create(ctx context.Context, req resource.CreateRequest, r
 }
 
 func (r *LdapResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	if r.client == nil {
+		return
+	}
+
 	var state LdapResourceModel
 
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
@@ -284,7 +319,13 @@ func (r *LdapResource) Read(ctx context.Context, req resource.ReadRequest, resp
 		return
 	}
 
-	ldap, err := r.client.GetLdap(state.Owner.ValueString() + "/" + state.Id.ValueString())
+	ctx, done := startOperation(ctx, state.Timeouts.Read, defaultReadTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ldap, err := r.client.Ldaps().Get(ctx, state.Owner.ValueString()+"/"+state.Id.ValueString())
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Reading LDAP",
@@ -344,24 +385,40 @@ func (r *LdapResource) Read(ctx context.Context, req resource.ReadRequest, resp
 		return
 	}
 
+	resp.Diagnostics.Append(setObjectFingerprint(ctx, resp.Private, ldap)...)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
 }
 
 func (r *LdapResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	var plan LdapResourceModel
+	if checkUnconfigured(r.client, &resp.Diagnostics) {
+		return
+	}
+
+	var plan, state LdapResourceModel
 
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
+	ctx, done := startOperation(ctx, plan.Timeouts.Update, defaultOperationTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if checkUpdateConflict(ctx, r.providerData, &resp.Diagnostics, req.Private, "LDAP server", state.Owner.ValueString()+"/"+state.Id.ValueString(), r.client.Ldaps().Get) {
+		return
+	}
+
 	ldap, diags := ldapPlanToSDK(ctx, plan, plan.CreatedTime.ValueString(), plan.LastSync.ValueString())
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	ok, err := r.client.UpdateLdap(ldap)
+	ok, err := r.client.Ldaps().Update(ctx, ldap)
 	if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("updating LDAP %q", plan.Id.ValueString())) {
 		return
 	}
@@ -373,10 +430,15 @@ func (r *LdapResource) Update(ctx context.Context, req resource.UpdateRequest, r
 		plan.CustomAttributes = types.MapNull(types.StringType)
 	}
 
+	resp.Diagnostics.Append(clearObjectFingerprint(ctx, resp.Private)...)
 	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
 }
 
 func (r *LdapResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	if checkUnconfigured(r.client, &resp.Diagnostics) {
+		return
+	}
+
 	var state LdapResourceModel
 
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
@@ -384,17 +446,38 @@ func (r *LdapResource) Delete(ctx context.Context, req resource.DeleteRequest, r
 		return
 	}
 
+	ctx, done := startOperation(ctx, state.Timeouts.Delete, defaultOperationTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	ldap := &casdoorsdk.Ldap{
 		Id:    state.Id.ValueString(),
 		Owner: state.Owner.ValueString(),
 	}
 
-	ok, err := r.client.DeleteLdap(ldap)
+	ok, err := r.client.Ldaps().Delete(ctx, ldap)
 	if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("deleting LDAP %q", state.Id.ValueString())) {
 		return
 	}
 }
 
+// ImportState takes the LDAP configuration's ID, or "owner/id" for one not
+// owned by admin, so that Read addresses it by its own owner.
 func (r *LdapResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+	owner, id, ok := strings.Cut(req.ID, "/")
+	if !ok {
+		owner, id = "admin", req.ID
+	}
+	if owner == "" || id == "" {
+		resp.Diagnostics.AddError(
+			"Invalid Import ID",
+			fmt.Sprintf("Expected import ID in the format 'id' or 'owner/id', got: %q", req.ID),
+		)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("owner"), owner)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), id)...)
 }
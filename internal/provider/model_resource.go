@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
@@ -21,27 +22,31 @@ var (
 	_ resource.Resource                = &ModelResource{}
 	_ resource.ResourceWithConfigure   = &ModelResource{}
 	_ resource.ResourceWithImportState = &ModelResource{}
+	_ resource.ResourceWithModifyPlan  = &ModelResource{}
 )
 
 type ModelResource struct {
-	client *casdoorsdk.Client
+	client       casdoorClient
+	providerData *CasdoorProviderData
 }
 
 type ModelResourceModel struct {
-	ID           types.String `tfsdk:"id"`
-	Owner        types.String `tfsdk:"owner"`
-	Name         types.String `tfsdk:"name"`
-	CreatedTime  types.String `tfsdk:"created_time"`
-	UpdatedTime  types.String `tfsdk:"updated_time"`
-	Description  types.String `tfsdk:"description"`
-	DisplayName  types.String `tfsdk:"display_name"`
-	ModelText    types.String `tfsdk:"model_text"`
-	Manager      types.String `tfsdk:"manager"`
-	ContactEmail types.String `tfsdk:"contact_email"`
-	Type         types.String `tfsdk:"type"`
-	ParentId     types.String `tfsdk:"parent_id"`
-	IsTopModel   types.Bool   `tfsdk:"is_top_model"`
-	IsEnabled    types.Bool   `tfsdk:"is_enabled"`
+	ID            types.String   `tfsdk:"id"`
+	Owner         types.String   `tfsdk:"owner"`
+	Name          types.String   `tfsdk:"name"`
+	CreatedTime   types.String   `tfsdk:"created_time"`
+	UpdatedTime   types.String   `tfsdk:"updated_time"`
+	Description   types.String   `tfsdk:"description"`
+	DisplayName   types.String   `tfsdk:"display_name"`
+	ModelText     types.String   `tfsdk:"model_text"`
+	Manager       types.String   `tfsdk:"manager"`
+	ContactEmail  types.String   `tfsdk:"contact_email"`
+	Type          types.String   `tfsdk:"type"`
+	ParentId      types.String   `tfsdk:"parent_id"`
+	IsTopModel    types.Bool     `tfsdk:"is_top_model"`
+	IsEnabled     types.Bool     `tfsdk:"is_enabled"`
+	AdoptExisting types.Bool     `tfsdk:"adopt_existing"`
+	Timeouts      timeouts.Value `tfsdk:"timeouts"`
 }
 
 func NewModelResource() resource.Resource {
@@ -52,7 +57,7 @@ func (r *ModelResource) Metadata(_ context.Context, req resource.MetadataRequest
 	resp.TypeName = req.ProviderTypeName + "_model"
 }
 
-func (r *ModelResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+func (r *ModelResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		Description: "Manages a Casdoor Casbin model.",
 		Attributes: map[string]schema.Attribute{
@@ -63,13 +68,7 @@ func (r *ModelResource) Schema(_ context.Context, _ resource.SchemaRequest, resp
 					stringplanmodifier.UseStateForUnknown(),
 				},
 			},
-			"owner": schema.StringAttribute{
-				Description: "The organization that owns this model.",
-				Required:    true,
-				PlanModifiers: []planmodifier.String{
-					stringplanmodifier.RequiresReplace(),
-				},
-			},
+			"owner": ownerAttribute("model"),
 			"name": schema.StringAttribute{
 				Description: "The unique name of the model.",
 				Required:    true,
@@ -143,6 +142,15 @@ func (r *ModelResource) Schema(_ context.Context, _ resource.SchemaRequest, resp
 				Computed:    true,
 				Default:     booldefault.StaticBool(false),
 			},
+			"adopt_existing": adoptExistingAttribute("model"),
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Read:   true,
+				Update: true,
+				Delete: true,
+			}),
 		},
 	}
 }
@@ -152,16 +160,26 @@ func (r *ModelResource) Configure(_ context.Context, req resource.ConfigureReque
 		return
 	}
 
-	client, ok := req.ProviderData.(*casdoorsdk.Client)
+	data, ok := req.ProviderData.(*CasdoorProviderData)
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Resource Configure Type",
-			fmt.Sprintf("Expected *casdoorsdk.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected *CasdoorProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 		return
 	}
 
-	r.client = client
+	r.client = data.Client
+	r.providerData = data
+}
+
+func (r *ModelResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	planDefaultOwner(ctx, req, resp, r.providerData)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	planAdoption(ctx, req, resp, r.providerData, "model", r.Read)
 }
 
 func modelPlanToSDK(plan ModelResourceModel, createdTime string) *casdoorsdk.Model {
@@ -182,6 +200,10 @@ func modelPlanToSDK(plan ModelResourceModel, createdTime string) *casdoorsdk.Mod
 }
 
 func (r *ModelResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	if checkUnconfigured(r.client, &resp.Diagnostics) {
+		return
+	}
+
 	var plan ModelResourceModel
 
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
@@ -189,6 +211,12 @@ func (r *ModelResource) Create(ctx context.Context, req resource.CreateRequest,
 		return
 	}
 
+	ctx, done := startOperation(ctx, plan.Timeouts.Create, defaultOperationTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	createdTime := plan.CreatedTime.ValueString()
 	if createdTime == "" {
 		createdTime = time.Now().UTC().Format(time.RFC3339)
@@ -196,13 +224,20 @@ func (r *ModelResource) Create(ctx context.Context, req resource.CreateRequest,
 
 	model := modelPlanToSDK(plan, createdTime)
 
-	ok, err := r.client.AddModel(model)
-	if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("creating model %q", plan.Name.ValueString())) {
+	adopted := adoptOnCreate(ctx, r.providerData, req.Plan, req.Config, &resp.Diagnostics, "model", plan.Owner.ValueString()+"/"+plan.Name.ValueString(), model, r.client.Models())
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
+	if !adopted {
+		ok, err := r.client.Models().Add(ctx, model)
+		if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("creating model %q", plan.Name.ValueString())) {
+			return
+		}
+	}
+
 	// Read back the model to get server-generated values like CreatedTime.
-	createdModel, err := r.client.GetModel(plan.Owner.ValueString() + "/" + plan.Name.ValueString())
+	createdModel, err := r.client.Models().Get(ctx, plan.Owner.ValueString()+"/"+plan.Name.ValueString())
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Reading Model",
@@ -228,6 +263,10 @@ func (r *ModelResource) Create(ctx context.Context, req resource.CreateRequest,
 }
 
 func (r *ModelResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	if r.client == nil {
+		return
+	}
+
 	var state ModelResourceModel
 
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
@@ -235,7 +274,13 @@ func (r *ModelResource) Read(ctx context.Context, req resource.ReadRequest, resp
 		return
 	}
 
-	model, err := r.client.GetModel(state.Owner.ValueString() + "/" + state.Name.ValueString())
+	ctx, done := startOperation(ctx, state.Timeouts.Read, defaultReadTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	model, err := r.client.Models().Get(ctx, state.Owner.ValueString()+"/"+state.Name.ValueString())
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Reading Model",
@@ -264,20 +309,36 @@ func (r *ModelResource) Read(ctx context.Context, req resource.ReadRequest, resp
 	state.IsTopModel = types.BoolValue(model.IsTopModel)
 	state.IsEnabled = types.BoolValue(model.IsEnabled)
 
+	resp.Diagnostics.Append(setObjectFingerprint(ctx, resp.Private, model)...)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
 }
 
 func (r *ModelResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	var plan ModelResourceModel
+	if checkUnconfigured(r.client, &resp.Diagnostics) {
+		return
+	}
+
+	var plan, state ModelResourceModel
 
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, done := startOperation(ctx, plan.Timeouts.Update, defaultOperationTimeout, &resp.Diagnostics)
+	defer done()
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
+	if checkUpdateConflict(ctx, r.providerData, &resp.Diagnostics, req.Private, "model", state.Owner.ValueString()+"/"+state.Name.ValueString(), r.client.Models().Get) {
+		return
+	}
+
 	model := modelPlanToSDK(plan, plan.CreatedTime.ValueString())
 
-	_, err := r.client.UpdateModel(model)
+	_, err := r.client.Models().Update(ctx, model)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Updating Model",
@@ -287,7 +348,7 @@ func (r *ModelResource) Update(ctx context.Context, req resource.UpdateRequest,
 	}
 
 	// Read back to get server-normalized values.
-	updatedModel, err := r.client.GetModel(plan.Owner.ValueString() + "/" + plan.Name.ValueString())
+	updatedModel, err := r.client.Models().Get(ctx, plan.Owner.ValueString()+"/"+plan.Name.ValueString())
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Reading Model",
@@ -306,10 +367,15 @@ func (r *ModelResource) Update(ctx context.Context, req resource.UpdateRequest,
 	plan.ModelText = types.StringValue(updatedModel.ModelText)
 
 	plan.ID = types.StringValue(plan.Owner.ValueString() + "/" + plan.Name.ValueString())
+	resp.Diagnostics.Append(clearObjectFingerprint(ctx, resp.Private)...)
 	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
 }
 
 func (r *ModelResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	if checkUnconfigured(r.client, &resp.Diagnostics) {
+		return
+	}
+
 	var state ModelResourceModel
 
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
@@ -317,12 +383,18 @@ func (r *ModelResource) Delete(ctx context.Context, req resource.DeleteRequest,
 		return
 	}
 
+	ctx, done := startOperation(ctx, state.Timeouts.Delete, defaultOperationTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	model := &casdoorsdk.Model{
 		Owner: state.Owner.ValueString(),
 		Name:  state.Name.ValueString(),
 	}
 
-	ok, err := r.client.DeleteModel(model)
+	ok, err := r.client.Models().Delete(ctx, model)
 	if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("deleting model %q", state.Name.ValueString())) {
 		return
 	}
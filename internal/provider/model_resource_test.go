@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
 	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
 )
@@ -92,3 +93,29 @@ resource "casdoor_model" "test" {
 }
 `, name, displayName, description)
 }
+
+// TestModelResource_adoptFallback runs against the fake Casdoor server, which
+// returns the built-in model of the same name when getting a model that does
+// not exist, as Casdoor does.
+func TestModelResource_adoptFallback(t *testing.T) {
+	const modelText = "[request_definition]\nr = sub, obj, act\n"
+
+	client, fake := newFakeSDKClient(t)
+	fake.seed("model", map[string]any{"owner": "built-in", "name": "shared", "modelText": modelText})
+	r, s := testResource(t, NewModelResource(), client, CasdoorProviderData{AdoptExisting: true})
+
+	state := testCreate(t, r, s, testObjectValue(t, s, map[string]tftypes.Value{
+		"owner":      tftypes.NewValue(tftypes.String, "acme"),
+		"name":       tftypes.NewValue(tftypes.String, "shared"),
+		"model_text": tftypes.NewValue(tftypes.String, modelText),
+	}))
+	if got := testAttribute(t, state, "id"); got != "acme/shared" {
+		t.Errorf("Expected id %q, got %q", "acme/shared", got)
+	}
+	if fake.object("model", "acme/shared") == nil {
+		t.Errorf("Expected model acme/shared to be created rather than the built-in one adopted")
+	}
+	if owner := fake.object("model", "built-in/shared")["owner"]; owner != "built-in" {
+		t.Errorf("Expected the built-in model to stay as it is, got owner %v", owner)
+	}
+}
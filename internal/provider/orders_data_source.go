@@ -0,0 +1,137 @@
+// Copyright (c) HashiCorp, Inc.
+
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ datasource.DataSource              = &OrdersDataSource{}
+	_ datasource.DataSourceWithConfigure = &OrdersDataSource{}
+)
+
+type OrdersDataSource struct {
+	client       casdoorClient
+	providerData *CasdoorProviderData
+}
+
+type OrderModel struct {
+	Name        types.String  `tfsdk:"name"`
+	CreatedTime types.String  `tfsdk:"created_time"`
+	UpdateTime  types.String  `tfsdk:"update_time"`
+	DisplayName types.String  `tfsdk:"display_name"`
+	Products    types.List    `tfsdk:"products"`
+	User        types.String  `tfsdk:"user"`
+	Payment     types.String  `tfsdk:"payment"`
+	Price       types.Float64 `tfsdk:"price"`
+	Currency    types.String  `tfsdk:"currency"`
+	State       types.String  `tfsdk:"state"`
+	Message     types.String  `tfsdk:"message"`
+}
+
+// orderRecords are the records listed by the casdoor_orders data source.
+var orderRecords = billingRecords[casdoorsdk.Order, OrderModel]{
+	kind:        "order",
+	plural:      "orders",
+	description: "Lists the orders of a Casdoor organization, optionally filtered by user, product, state and creation time.",
+	withProduct: true,
+	attributes: map[string]schema.Attribute{
+		"update_time": schema.StringAttribute{
+			Description: "The time when the order was last updated.",
+			Computed:    true,
+		},
+		"products": schema.ListAttribute{
+			Description: "Names of the products ordered.",
+			Computed:    true,
+			ElementType: types.StringType,
+		},
+		"user": schema.StringAttribute{
+			Description: "The name of the ordering user.",
+			Computed:    true,
+		},
+		"payment": schema.StringAttribute{
+			Description: "The name of the payment for the order.",
+			Computed:    true,
+		},
+		"price": schema.Float64Attribute{
+			Description: "The total price of the order.",
+			Computed:    true,
+		},
+		"currency": schema.StringAttribute{
+			Description: "The currency of the price.",
+			Computed:    true,
+		},
+		"state": schema.StringAttribute{
+			Description: "The state of the order (e.g., 'Created', 'Paid', 'Canceled').",
+			Computed:    true,
+		},
+		"message": schema.StringAttribute{
+			Description: "The message recorded with the state of the order.",
+			Computed:    true,
+		},
+	},
+
+	list: casdoorClient.ListOrders,
+	match: func(filter billingFilter, order *casdoorsdk.Order) bool {
+		return filter.match(order.User, order.Products, order.State, order.CreatedTime)
+	},
+	flatten: func(ctx context.Context, order *casdoorsdk.Order) (OrderModel, diag.Diagnostics) {
+		products, diags := types.ListValueFrom(ctx, types.StringType, order.Products)
+
+		return OrderModel{
+			Name:        types.StringValue(order.Name),
+			CreatedTime: types.StringValue(order.CreatedTime),
+			UpdateTime:  types.StringValue(order.UpdateTime),
+			DisplayName: types.StringValue(order.DisplayName),
+			Products:    products,
+			User:        types.StringValue(order.User),
+			Payment:     types.StringValue(order.Payment),
+			Price:       types.Float64Value(order.Price),
+			Currency:    types.StringValue(order.Currency),
+			State:       types.StringValue(order.State),
+			Message:     types.StringValue(order.Message),
+		}, diags
+	},
+}
+
+func NewOrdersDataSource() datasource.DataSource {
+	return &OrdersDataSource{}
+}
+
+func (d *OrdersDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_orders"
+}
+
+func (d *OrdersDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = orderRecords.schema()
+}
+
+func (d *OrdersDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*CasdoorProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *CasdoorProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	d.client = data.Client
+	d.providerData = data
+}
+
+func (d *OrdersDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	orderRecords.read(ctx, d.client, req, resp)
+}
@@ -0,0 +1,81 @@
+// Copyright (c) HashiCorp, Inc.
+
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+// addTestOrders adds three orders of users named after name: two of
+// "<name>-alice", paid in January and canceled in March, and one of
+// "<name>-bob", paid in February.
+func addTestOrders(t *testing.T, config CasdoorTestConfig, name string) {
+	t.Helper()
+
+	orders := []*casdoorsdk.Order{
+		{Owner: "built-in", Name: name + "-1", CreatedTime: "2026-01-15T10:00:00Z", User: name + "-alice", Products: []string{name + "-basic"}, Currency: "USD", Price: 9.99, State: "Paid"},
+		{Owner: "built-in", Name: name + "-2", CreatedTime: "2026-02-15T10:00:00Z", User: name + "-bob", Products: []string{name + "-premium"}, Currency: "USD", Price: 29.99, State: "Paid"},
+		{Owner: "built-in", Name: name + "-3", CreatedTime: "2026-03-15T10:00:00Z", User: name + "-alice", Products: []string{name + "-premium"}, Currency: "USD", Price: 29.99, State: "Canceled"},
+	}
+	addTestBillingRecords(t, config, "order", orders,
+		func(client *casdoorsdk.Client, order *casdoorsdk.Order) (bool, error) { return client.AddOrder(order) },
+		func(client *casdoorsdk.Client, order *casdoorsdk.Order) (bool, error) {
+			return client.DeleteOrder(order)
+		},
+	)
+}
+
+func TestAccOrdersDataSource_basic(t *testing.T) {
+	config := setupTestConfig(t)
+	rName := "tf-test-" + acctest.RandStringFromCharSet(8, acctest.CharSetAlphaNum)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories(config),
+		Steps: []resource.TestStep{
+			{
+				PreConfig: func() { addTestOrders(t, config, rName) },
+				Config:    testAccProviderConfig(config) + testAccOrdersDataSourceConfig(rName),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.casdoor_orders.user", "id", "built-in"),
+					resource.TestCheckResourceAttr("data.casdoor_orders.user", "orders.#", "2"),
+					resource.TestCheckResourceAttr("data.casdoor_orders.user", "orders.0.name", rName+"-1"),
+					resource.TestCheckResourceAttr("data.casdoor_orders.user", "orders.0.price", "9.99"),
+					resource.TestCheckResourceAttr("data.casdoor_orders.user", "orders.0.products.0", rName+"-basic"),
+					resource.TestCheckResourceAttr("data.casdoor_orders.user", "orders.1.name", rName+"-3"),
+					resource.TestCheckResourceAttr("data.casdoor_orders.paid_premium", "orders.#", "1"),
+					resource.TestCheckResourceAttr("data.casdoor_orders.paid_premium", "orders.0.user", rName+"-bob"),
+					resource.TestCheckResourceAttr("data.casdoor_orders.february", "orders.#", "1"),
+					resource.TestCheckResourceAttr("data.casdoor_orders.february", "orders.0.name", rName+"-2"),
+				),
+			},
+		},
+	})
+}
+
+func testAccOrdersDataSourceConfig(name string) string {
+	return fmt.Sprintf(`
+data "casdoor_orders" "user" {
+  owner     = "built-in"
+  user      = "%[1]s-alice"
+  page_size = 2
+}
+
+data "casdoor_orders" "paid_premium" {
+  owner   = "built-in"
+  product = "%[1]s-premium"
+  state   = "Paid"
+}
+
+data "casdoor_orders" "february" {
+  owner          = "built-in"
+  product        = "%[1]s-premium"
+  created_after  = "2026-02-01T00:00:00Z"
+  created_before = "2026-03-01T00:00:00Z"
+}
+`, name)
+}
@@ -0,0 +1,146 @@
+// Copyright (c) HashiCorp, Inc.
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+const (
+	// deletePolicyOrphan deletes only the organization and leaves the objects
+	// that belong to it in the database.
+	deletePolicyOrphan = "orphan"
+	// deletePolicyRestrict refuses to delete an organization that still has
+	// objects belonging to it.
+	deletePolicyRestrict = "restrict"
+	// deletePolicyCascade deletes the objects belonging to the organization
+	// before the organization itself.
+	deletePolicyCascade = "cascade"
+)
+
+// organizationDependent is an object that belongs to an organization.
+type organizationDependent struct {
+	kind   string
+	id     string
+	delete func(context.Context) (bool, error)
+}
+
+// appendDependents lists the objects of one kind owned by owner and appends
+// them to deps, together with the function that deletes each of them.
+// Built-in objects are left out; they are never deleted along with an
+// organization.
+func appendDependents[T any](ctx context.Context, deps []organizationDependent, kind string, objects []*T, err error, id func(*T) string, api objectAPI[T]) ([]organizationDependent, error) {
+	if err != nil {
+		return nil, fmt.Errorf("listing %ss: %w", kind, err)
+	}
+
+	for _, obj := range objects {
+		if isBuiltInObject(kind, id(obj)) {
+			continue
+		}
+		deps = append(deps, organizationDependent{
+			kind:   kind,
+			id:     id(obj),
+			delete: func(ctx context.Context) (bool, error) { return api.Delete(ctx, obj) },
+		})
+	}
+
+	return deps, nil
+}
+
+// organizationDependents lists the objects that belong to the organization in
+// the order they can be deleted: permissions before the roles and users they
+// grant to, users before their groups, and applications before the
+// providers and certs they use.
+func organizationDependents(ctx context.Context, client casdoorClient, org string) ([]organizationDependent, error) {
+	var deps []organizationDependent
+
+	permissions, err := client.Permissions().List(ctx, org)
+	if deps, err = appendDependents(ctx, deps, "permission", permissions, err,
+		func(p *casdoorsdk.Permission) string { return p.Owner + "/" + p.Name }, client.Permissions()); err != nil {
+		return nil, err
+	}
+	roles, err := client.Roles().List(ctx, org)
+	if deps, err = appendDependents(ctx, deps, "role", roles, err,
+		func(r *casdoorsdk.Role) string { return r.Owner + "/" + r.Name }, client.Roles()); err != nil {
+		return nil, err
+	}
+	users, err := client.Users().List(ctx, org)
+	if deps, err = appendDependents(ctx, deps, "user", users, err,
+		func(u *casdoorsdk.User) string { return u.Owner + "/" + u.Name }, client.Users()); err != nil {
+		return nil, err
+	}
+	groups, err := client.Groups().List(ctx, org)
+	if deps, err = appendDependents(ctx, deps, "group", groups, err,
+		func(g *casdoorsdk.Group) string { return g.Owner + "/" + g.Name }, client.Groups()); err != nil {
+		return nil, err
+	}
+	applications, err := client.OrganizationApplications(ctx, org)
+	if deps, err = appendDependents(ctx, deps, "application", applications, err,
+		func(a *casdoorsdk.Application) string { return a.Owner + "/" + a.Name }, client.Applications()); err != nil {
+		return nil, err
+	}
+	providers, err := client.Providers().List(ctx, org)
+	if deps, err = appendDependents(ctx, deps, "provider", providers, err,
+		func(p *casdoorsdk.Provider) string { return p.Owner + "/" + p.Name }, client.Providers()); err != nil {
+		return nil, err
+	}
+	// get-certs answers with the certificates shared by admin as well.
+	certs, err := client.Certs().List(ctx, org)
+	certs = slices.DeleteFunc(certs, func(c *casdoorsdk.Cert) bool { return c.Owner != org })
+	if deps, err = appendDependents(ctx, deps, "certificate", certs, err,
+		func(c *casdoorsdk.Cert) string { return c.Owner + "/" + c.Name }, client.Certs()); err != nil {
+		return nil, err
+	}
+
+	return deps, nil
+}
+
+// describeDependents lists dependents one per line for a diagnostic, cut off
+// after limit entries.
+func describeDependents(deps []organizationDependent, limit int) string {
+	var b strings.Builder
+
+	for i, dep := range deps {
+		if i == limit {
+			fmt.Fprintf(&b, "  ... and %d more\n", len(deps)-limit)
+			break
+		}
+		fmt.Fprintf(&b, "  - %s %s\n", dep.kind, dep.id)
+	}
+
+	return b.String()
+}
+
+// deleteOrganizationDependents deletes deps in order, logging progress, and
+// stops once ctx is done.
+func deleteOrganizationDependents(ctx context.Context, org string, deps []organizationDependent) error {
+	for i, dep := range deps {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("deleted %d of %d objects of organization %q before giving up: %w", i, len(deps), org, err)
+		}
+
+		tflog.Info(ctx, "Deleting object of organization", map[string]any{
+			"organization": org,
+			"kind":         dep.kind,
+			"id":           dep.id,
+			"progress":     fmt.Sprintf("%d/%d", i+1, len(deps)),
+		})
+
+		ok, err := dep.delete(ctx)
+		if err != nil {
+			return fmt.Errorf("deleting %s %q: %w", dep.kind, dep.id, err)
+		}
+		if !ok {
+			return fmt.Errorf("Casdoor returned failure when deleting %s %q", dep.kind, dep.id)
+		}
+	}
+
+	return nil
+}
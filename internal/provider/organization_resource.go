@@ -8,8 +8,10 @@ import (
 	"time"
 
 	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
 	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
@@ -26,10 +28,12 @@ var (
 	_ resource.Resource                = &OrganizationResource{}
 	_ resource.ResourceWithConfigure   = &OrganizationResource{}
 	_ resource.ResourceWithImportState = &OrganizationResource{}
+	_ resource.ResourceWithModifyPlan  = &OrganizationResource{}
 )
 
 type OrganizationResource struct {
-	client *casdoorsdk.Client
+	client       casdoorClient
+	providerData *CasdoorProviderData
 }
 
 // AccountItemModel represents an account item configuration.
@@ -87,52 +91,56 @@ func ThemeDataAttrTypes() map[string]attr.Type {
 }
 
 type OrganizationResourceModel struct {
-	ID                     types.String  `tfsdk:"id"`
-	Owner                  types.String  `tfsdk:"owner"`
-	Name                   types.String  `tfsdk:"name"`
-	CreatedTime            types.String  `tfsdk:"created_time"`
-	DisplayName            types.String  `tfsdk:"display_name"`
-	WebsiteURL             types.String  `tfsdk:"website_url"`
-	Logo                   types.String  `tfsdk:"logo"`
-	LogoDark               types.String  `tfsdk:"logo_dark"`
-	Favicon                types.String  `tfsdk:"favicon"`
-	HasPrivilegeConsent    types.Bool    `tfsdk:"has_privilege_consent"`
-	PasswordType           types.String  `tfsdk:"password_type"`
-	PasswordSalt           types.String  `tfsdk:"password_salt"`
-	PasswordOptions        types.List    `tfsdk:"password_options"`
-	PasswordObfuscatorType types.String  `tfsdk:"password_obfuscator_type"`
-	PasswordObfuscatorKey  types.String  `tfsdk:"password_obfuscator_key"`
-	PasswordExpireDays     types.Int64   `tfsdk:"password_expire_days"`
-	CountryCodes           types.List    `tfsdk:"country_codes"`
-	DefaultAvatar          types.String  `tfsdk:"default_avatar"`
-	DefaultApplication     types.String  `tfsdk:"default_application"`
-	UserTypes              types.List    `tfsdk:"user_types"`
-	Tags                   types.List    `tfsdk:"tags"`
-	Languages              types.List    `tfsdk:"languages"`
-	ThemeData              types.Object  `tfsdk:"theme_data"`
-	MasterPassword         types.String  `tfsdk:"master_password"`
-	DefaultPassword        types.String  `tfsdk:"default_password"`
-	MasterVerificationCode types.String  `tfsdk:"master_verification_code"`
-	IPWhitelist            types.String  `tfsdk:"ip_whitelist"`
-	InitScore              types.Int64   `tfsdk:"init_score"`
-	EnableSoftDeletion     types.Bool    `tfsdk:"enable_soft_deletion"`
-	IsProfilePublic        types.Bool    `tfsdk:"is_profile_public"`
-	UseEmailAsUsername     types.Bool    `tfsdk:"use_email_as_username"`
-	EnableTour             types.Bool    `tfsdk:"enable_tour"`
-	DisableSignin          types.Bool    `tfsdk:"disable_signin"`
-	IPRestriction          types.String  `tfsdk:"ip_restriction"`
-	NavItems               types.List    `tfsdk:"nav_items"`
-	UserNavItems           types.List    `tfsdk:"user_nav_items"`
-	WidgetItems            types.List    `tfsdk:"widget_items"`
-	MfaItems               types.List    `tfsdk:"mfa_items"`
-	MfaRememberInHours     types.Int64   `tfsdk:"mfa_remember_in_hours"`
-	AccountItems           types.List    `tfsdk:"account_items"`
-	OrgBalance             types.Float64 `tfsdk:"org_balance"`
-	UserBalance            types.Float64 `tfsdk:"user_balance"`
-	BalanceCredit          types.Float64 `tfsdk:"balance_credit"`
-	BalanceCurrency        types.String  `tfsdk:"balance_currency"`
-	AccountMenu            types.String  `tfsdk:"account_menu"`
-	DcrPolicy              types.String  `tfsdk:"dcr_policy"`
+	ID                     types.String   `tfsdk:"id"`
+	Owner                  types.String   `tfsdk:"owner"`
+	Name                   types.String   `tfsdk:"name"`
+	CreatedTime            types.String   `tfsdk:"created_time"`
+	DisplayName            types.String   `tfsdk:"display_name"`
+	WebsiteURL             types.String   `tfsdk:"website_url"`
+	Logo                   types.String   `tfsdk:"logo"`
+	LogoDark               types.String   `tfsdk:"logo_dark"`
+	Favicon                types.String   `tfsdk:"favicon"`
+	HasPrivilegeConsent    types.Bool     `tfsdk:"has_privilege_consent"`
+	PasswordType           types.String   `tfsdk:"password_type"`
+	PasswordSalt           types.String   `tfsdk:"password_salt"`
+	PasswordOptions        types.List     `tfsdk:"password_options"`
+	PasswordObfuscatorType types.String   `tfsdk:"password_obfuscator_type"`
+	PasswordObfuscatorKey  types.String   `tfsdk:"password_obfuscator_key"`
+	PasswordExpireDays     types.Int64    `tfsdk:"password_expire_days"`
+	CountryCodes           types.List     `tfsdk:"country_codes"`
+	DefaultAvatar          types.String   `tfsdk:"default_avatar"`
+	DefaultApplication     types.String   `tfsdk:"default_application"`
+	UserTypes              types.List     `tfsdk:"user_types"`
+	Tags                   types.List     `tfsdk:"tags"`
+	Languages              types.List     `tfsdk:"languages"`
+	ThemeData              types.Object   `tfsdk:"theme_data"`
+	MasterPassword         types.String   `tfsdk:"master_password"`
+	DefaultPassword        types.String   `tfsdk:"default_password"`
+	MasterVerificationCode types.String   `tfsdk:"master_verification_code"`
+	IPWhitelist            types.String   `tfsdk:"ip_whitelist"`
+	InitScore              types.Int64    `tfsdk:"init_score"`
+	EnableSoftDeletion     types.Bool     `tfsdk:"enable_soft_deletion"`
+	IsProfilePublic        types.Bool     `tfsdk:"is_profile_public"`
+	UseEmailAsUsername     types.Bool     `tfsdk:"use_email_as_username"`
+	EnableTour             types.Bool     `tfsdk:"enable_tour"`
+	DisableSignin          types.Bool     `tfsdk:"disable_signin"`
+	IPRestriction          types.String   `tfsdk:"ip_restriction"`
+	NavItems               types.List     `tfsdk:"nav_items"`
+	UserNavItems           types.List     `tfsdk:"user_nav_items"`
+	WidgetItems            types.List     `tfsdk:"widget_items"`
+	MfaItems               types.List     `tfsdk:"mfa_items"`
+	MfaRememberInHours     types.Int64    `tfsdk:"mfa_remember_in_hours"`
+	AccountItems           types.List     `tfsdk:"account_items"`
+	OrgBalance             types.Float64  `tfsdk:"org_balance"`
+	UserBalance            types.Float64  `tfsdk:"user_balance"`
+	BalanceCredit          types.Float64  `tfsdk:"balance_credit"`
+	BalanceCurrency        types.String   `tfsdk:"balance_currency"`
+	AccountMenu            types.String   `tfsdk:"account_menu"`
+	DcrPolicy              types.String   `tfsdk:"dcr_policy"`
+	AdoptExisting          types.Bool     `tfsdk:"adopt_existing"`
+	DeletionProtection     types.Bool     `tfsdk:"deletion_protection"`
+	DeletePolicy           types.String   `tfsdk:"delete_policy"`
+	Timeouts               timeouts.Value `tfsdk:"timeouts"`
 }
 
 func NewOrganizationResource() resource.Resource {
@@ -143,7 +151,7 @@ func (r *OrganizationResource) Metadata(_ context.Context, req resource.Metadata
 	resp.TypeName = req.ProviderTypeName + "_organization"
 }
 
-func (r *OrganizationResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+func (r *OrganizationResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		Description: "Manages a Casdoor organization.",
 		Attributes: map[string]schema.Attribute{
@@ -151,24 +159,13 @@ func (r *OrganizationResource) Schema(_ context.Context, _ resource.SchemaReques
 				Description: "The ID of the organization in the format 'owner/name'.",
 				Computed:    true,
 				PlanModifiers: []planmodifier.String{
-					stringplanmodifier.UseStateForUnknown(),
-				},
-			},
-			"owner": schema.StringAttribute{
-				Description: "The owner of the organization. Defaults to 'admin'.",
-				Optional:    true,
-				Computed:    true,
-				Default:     stringdefault.StaticString("admin"),
-				PlanModifiers: []planmodifier.String{
-					stringplanmodifier.RequiresReplace(),
+					useStateUnlessChanged(path.Root("name")),
 				},
 			},
+			"owner": adminOwnerAttribute("organization"),
 			"name": schema.StringAttribute{
-				Description: "The unique name of the organization.",
+				Description: "The unique name of the organization. Changing it renames the organization in place.",
 				Required:    true,
-				PlanModifiers: []planmodifier.String{
-					stringplanmodifier.RequiresReplace(),
-				},
 			},
 			"created_time": schema.StringAttribute{
 				Description: "The time when the organization was created.",
@@ -477,6 +474,25 @@ func (r *OrganizationResource) Schema(_ context.Context, _ resource.SchemaReques
 				Computed:    true,
 				Default:     stringdefault.StaticString(""),
 			},
+			"adopt_existing":      adoptExistingAttribute("organization"),
+			"deletion_protection": deletionProtectionAttribute("organization"),
+			"delete_policy": schema.StringAttribute{
+				Description: "What happens to the users, groups, roles, permissions, applications, certificates and " +
+					"providers of the organization when it is deleted. 'orphan' deletes only the organization and leaves " +
+					"them behind, 'restrict' refuses to delete the organization while any of them exist and lists them, " +
+					"'cascade' deletes them first. Defaults to 'orphan'.",
+				Optional: true,
+				Computed: true,
+				Default:  stringdefault.StaticString(deletePolicyOrphan),
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Read:   true,
+				Update: true,
+				Delete: true,
+			}),
 		},
 	}
 }
@@ -486,16 +502,39 @@ func (r *OrganizationResource) Configure(_ context.Context, req resource.Configu
 		return
 	}
 
-	client, ok := req.ProviderData.(*casdoorsdk.Client)
+	data, ok := req.ProviderData.(*CasdoorProviderData)
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Resource Configure Type",
-			fmt.Sprintf("Expected *casdoorsdk.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected *CasdoorProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 		return
 	}
 
-	r.client = client
+	r.client = data.Client
+	r.providerData = data
+}
+
+func (r *OrganizationResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if !req.Plan.Raw.IsNull() {
+		var policy types.String
+		resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("delete_policy"), &policy)...)
+
+		switch policy.ValueString() {
+		case deletePolicyOrphan, deletePolicyRestrict, deletePolicyCascade:
+		default:
+			if !policy.IsUnknown() {
+				resp.Diagnostics.AddAttributeError(
+					path.Root("delete_policy"),
+					"Invalid Delete Policy",
+					fmt.Sprintf("Expected %q, %q or %q, got: %q", deletePolicyOrphan, deletePolicyRestrict, deletePolicyCascade, policy.ValueString()),
+				)
+				return
+			}
+		}
+	}
+
+	planAdoption(ctx, req, resp, r.providerData, "organization", r.Read)
 }
 
 func organizationPlanToSDK(ctx context.Context, plan OrganizationResourceModel, createdTime string) (*casdoorsdk.Organization, diag.Diagnostics) {
@@ -621,6 +660,10 @@ func organizationPlanToSDK(ctx context.Context, plan OrganizationResourceModel,
 }
 
 func (r *OrganizationResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	if checkUnconfigured(r.client, &resp.Diagnostics) {
+		return
+	}
+
 	var plan OrganizationResourceModel
 
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
@@ -628,6 +671,12 @@ func (r *OrganizationResource) Create(ctx context.Context, req resource.CreateRe
 		return
 	}
 
+	ctx, done := startOperation(ctx, plan.Timeouts.Create, defaultOperationTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	createdTime := plan.CreatedTime.ValueString()
 	if createdTime == "" {
 		createdTime = time.Now().UTC().Format(time.RFC3339)
@@ -639,13 +688,20 @@ func (r *OrganizationResource) Create(ctx context.Context, req resource.CreateRe
 		return
 	}
 
-	ok, err := r.client.AddOrganization(org)
-	if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("creating organization %q", plan.Name.ValueString())) {
+	adopted := adoptOnCreate(ctx, r.providerData, req.Plan, req.Config, &resp.Diagnostics, "organization", plan.Owner.ValueString()+"/"+plan.Name.ValueString(), org, r.client.Organizations())
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
+	if !adopted {
+		ok, err := r.client.Organizations().Add(ctx, org)
+		if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("creating organization %q", plan.Name.ValueString())) {
+			return
+		}
+	}
+
 	// Read back the organization to get server-generated values like CreatedTime.
-	createdOrg, err := r.client.GetOrganization(plan.Owner.ValueString() + "/" + plan.Name.ValueString())
+	createdOrg, err := r.client.Organizations().Get(ctx, plan.Owner.ValueString()+"/"+plan.Name.ValueString())
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Reading Organization",
@@ -689,6 +745,10 @@ func (r *OrganizationResource) Create(ctx context.Context, req resource.CreateRe
 }
 
 func (r *OrganizationResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	if r.client == nil {
+		return
+	}
+
 	var state OrganizationResourceModel
 
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
@@ -696,7 +756,13 @@ func (r *OrganizationResource) Read(ctx context.Context, req resource.ReadReques
 		return
 	}
 
-	org, err := r.client.GetOrganization(state.Owner.ValueString() + "/" + state.Name.ValueString())
+	ctx, done := startOperation(ctx, state.Timeouts.Read, defaultReadTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	org, err := r.client.Organizations().Get(ctx, state.Owner.ValueString()+"/"+state.Name.ValueString())
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Reading Organization",
@@ -847,25 +913,51 @@ func (r *OrganizationResource) Read(ctx context.Context, req resource.ReadReques
 		return
 	}
 
+	if state.DeletionProtection.IsNull() {
+		state.DeletionProtection = types.BoolValue(false)
+	}
+	if state.DeletePolicy.IsNull() {
+		state.DeletePolicy = types.StringValue(deletePolicyOrphan)
+	}
+
+	resp.Diagnostics.Append(setObjectFingerprint(ctx, resp.Private, org)...)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
 }
 
 func (r *OrganizationResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	var plan OrganizationResourceModel
+	if checkUnconfigured(r.client, &resp.Diagnostics) {
+		return
+	}
+
+	var plan, state OrganizationResourceModel
 
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
+	ctx, done := startOperation(ctx, plan.Timeouts.Update, defaultOperationTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if checkBuiltInModify(r.providerData, &resp.Diagnostics, "organization", state.Owner.ValueString()+"/"+state.Name.ValueString()) {
+		return
+	}
+
+	if checkUpdateConflict(ctx, r.providerData, &resp.Diagnostics, req.Private, "organization", state.Owner.ValueString()+"/"+state.Name.ValueString(), r.client.Organizations().Get) {
+		return
+	}
+
 	org, diags := organizationPlanToSDK(ctx, plan, plan.CreatedTime.ValueString())
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	ok, err := r.client.UpdateOrganization(org)
-	if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("updating organization %q", plan.Name.ValueString())) {
+	if renameUpdate(ctx, resp, r.client, "organization", plan.Owner.ValueString(), state.Name.ValueString(), plan.Name.ValueString(), org) {
 		return
 	}
 
@@ -895,10 +987,15 @@ func (r *OrganizationResource) Update(ctx context.Context, req resource.UpdateRe
 
 	plan.ID = types.StringValue(plan.Owner.ValueString() + "/" + plan.Name.ValueString())
 
+	resp.Diagnostics.Append(clearObjectFingerprint(ctx, resp.Private)...)
 	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
 }
 
 func (r *OrganizationResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	if checkUnconfigured(r.client, &resp.Diagnostics) {
+		return
+	}
+
 	var state OrganizationResourceModel
 
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
@@ -906,12 +1003,55 @@ func (r *OrganizationResource) Delete(ctx context.Context, req resource.DeleteRe
 		return
 	}
 
+	ctx, done := startOperation(ctx, state.Timeouts.Delete, defaultOperationTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	id := state.Owner.ValueString() + "/" + state.Name.ValueString()
+	if checkDeletionProtection(&resp.Diagnostics, state.DeletionProtection, "organization", id) ||
+		checkBuiltInDelete(r.providerData, &resp.Diagnostics, "organization", id) {
+		return
+	}
+
+	if policy := state.DeletePolicy.ValueString(); policy == deletePolicyRestrict || policy == deletePolicyCascade {
+		deps, err := organizationDependents(ctx, r.client, state.Name.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error Listing Organization Objects",
+				fmt.Sprintf("Could not list the objects of organization %q: %s", state.Name.ValueString(), err),
+			)
+			return
+		}
+
+		if policy == deletePolicyRestrict && len(deps) > 0 {
+			resp.Diagnostics.AddError(
+				"Organization Not Empty",
+				fmt.Sprintf("The organization %q has delete_policy = %q and still has %d objects:\n\n%s\n"+
+					"Delete them first, or set delete_policy = %q to delete them along with the organization.",
+					state.Name.ValueString(), deletePolicyRestrict, len(deps), describeDependents(deps, 20), deletePolicyCascade),
+			)
+			return
+		}
+
+		if policy == deletePolicyCascade {
+			if err := deleteOrganizationDependents(ctx, state.Name.ValueString(), deps); err != nil {
+				resp.Diagnostics.AddError(
+					"Error Deleting Organization Objects",
+					fmt.Sprintf("Could not delete the objects of organization %q: %s", state.Name.ValueString(), err),
+				)
+				return
+			}
+		}
+	}
+
 	org := &casdoorsdk.Organization{
 		Owner: state.Owner.ValueString(),
 		Name:  state.Name.ValueString(),
 	}
 
-	ok, err := r.client.DeleteOrganization(org)
+	ok, err := r.client.Organizations().Delete(ctx, org)
 	if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("deleting organization %q", state.Name.ValueString())) {
 		return
 	}
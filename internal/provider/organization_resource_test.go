@@ -3,12 +3,16 @@
 package provider
 
 import (
+	"context"
 	"fmt"
+	"regexp"
+	"slices"
 	"testing"
 
 	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
 	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
 )
 
 // TestCasdoorSDKClient tests the Casdoor SDK client directly to verify connectivity.
@@ -105,6 +109,59 @@ func TestCasdoorSDKClient(t *testing.T) {
 	}
 }
 
+// TestOrganizationDependents verifies that the objects deleted along with an
+// organization are its own, and not the certificates Casdoor lists for every
+// organization.
+func TestOrganizationDependents(t *testing.T) {
+	config := setupTestConfig(t)
+	rName := "tf-test-" + acctest.RandStringFromCharSet(8, acctest.CharSetAlphaNum)
+
+	client := casdoorsdk.NewClient(
+		config.Endpoint,
+		config.ClientID,
+		config.ClientSecret,
+		config.Certificate,
+		config.OrganizationName,
+		config.ApplicationName,
+	)
+
+	if _, err := client.AddOrganization(&casdoorsdk.Organization{Owner: "admin", Name: rName, PasswordType: "plain"}); err != nil {
+		t.Fatalf("Failed to create organization: %v", err)
+	}
+	t.Cleanup(func() {
+		_, _ = client.DeleteOrganization(&casdoorsdk.Organization{Owner: "admin", Name: rName})
+	})
+	cert := &casdoorsdk.Cert{
+		Owner:           rName,
+		Name:            "cert",
+		DisplayName:     "Organization Cert",
+		Scope:           "JWT",
+		Type:            "x509",
+		CryptoAlgorithm: "RS256",
+		BitSize:         4096,
+		ExpireInYears:   20,
+	}
+	if _, err := client.AddCert(cert); err != nil {
+		t.Fatalf("Failed to create cert: %v", err)
+	}
+	t.Cleanup(func() {
+		_, _ = client.DeleteCert(cert)
+	})
+
+	deps, err := organizationDependents(context.Background(), newSDKClient(client), rName)
+	if err != nil {
+		t.Fatalf("Failed to list dependents: %v", err)
+	}
+
+	var ids []string
+	for _, dep := range deps {
+		ids = append(ids, dep.kind+" "+dep.id)
+	}
+	if want := []string{"certificate " + rName + "/cert"}; !slices.Equal(ids, want) {
+		t.Fatalf("Expected dependents %q, got %q", want, ids)
+	}
+}
+
 func TestAccOrganizationResource_basic(t *testing.T) {
 	config := setupTestConfig(t)
 	rName := "tf-test-" + acctest.RandStringFromCharSet(8, acctest.CharSetAlphaNum)
@@ -160,6 +217,96 @@ func TestAccOrganizationResource_import(t *testing.T) {
 	})
 }
 
+func TestAccOrganizationResource_deletionProtection(t *testing.T) {
+	config := setupTestConfig(t)
+	rName := "tf-test-" + acctest.RandStringFromCharSet(8, acctest.CharSetAlphaNum)
+	resourceName := "casdoor_organization.test"
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories(config),
+		Steps: []resource.TestStep{
+			// Create a protected organization
+			{
+				Config: testAccProviderConfig(config) + testAccOrganizationResourceProtectedConfig(rName, true),
+				Check:  resource.TestCheckResourceAttr(resourceName, "deletion_protection", "true"),
+			},
+			// Destroying it fails
+			{
+				Config:      testAccProviderConfig(config),
+				ExpectError: regexp.MustCompile("Deletion Protection Enabled"),
+			},
+			// Lift the protection so that the organization can be destroyed
+			{
+				Config: testAccProviderConfig(config) + testAccOrganizationResourceProtectedConfig(rName, false),
+				Check:  resource.TestCheckResourceAttr(resourceName, "deletion_protection", "false"),
+			},
+		},
+	})
+}
+
+func TestAccOrganizationResource_deletePolicy(t *testing.T) {
+	config := setupTestConfig(t)
+	rName := "tf-test-" + acctest.RandStringFromCharSet(8, acctest.CharSetAlphaNum)
+	resourceName := "casdoor_organization.test"
+
+	client := func() *casdoorsdk.Client {
+		return casdoorsdk.NewClient(
+			config.Endpoint,
+			config.ClientID,
+			config.ClientSecret,
+			config.Certificate,
+			config.OrganizationName,
+			config.ApplicationName,
+		)
+	}
+
+	// addGroup creates a group in the organization outside of Terraform.
+	addGroup := func() {
+		group := &casdoorsdk.Group{
+			Owner:       rName,
+			Name:        "unmanaged",
+			DisplayName: "Unmanaged Group",
+			Type:        "Virtual",
+			IsEnabled:   true,
+		}
+		if _, err := client().AddGroup(group); err != nil {
+			t.Fatalf("Failed to add group: %v", err)
+		}
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories(config),
+		CheckDestroy: func(*terraform.State) error {
+			group, err := client().GetGroup(rName + "/unmanaged")
+			if err != nil {
+				return err
+			}
+			if group != nil {
+				return fmt.Errorf("group %s/unmanaged still exists", rName)
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			// Create an organization that refuses to be deleted while not empty
+			{
+				Config: testAccProviderConfig(config) + testAccOrganizationResourceDeletePolicyConfig(rName, "restrict"),
+				Check:  resource.TestCheckResourceAttr(resourceName, "delete_policy", "restrict"),
+			},
+			// Destroying it fails once it has a group
+			{
+				PreConfig:   addGroup,
+				Config:      testAccProviderConfig(config),
+				ExpectError: regexp.MustCompile("Organization Not Empty"),
+			},
+			// Switch to cascade so that destroying deletes the group as well
+			{
+				Config: testAccProviderConfig(config) + testAccOrganizationResourceDeletePolicyConfig(rName, "cascade"),
+				Check:  resource.TestCheckResourceAttr(resourceName, "delete_policy", "cascade"),
+			},
+		},
+	})
+}
+
 func testAccOrganizationResourceConfig(name, displayName string) string {
 	return fmt.Sprintf(`
 resource "casdoor_organization" "test" {
@@ -168,3 +315,27 @@ resource "casdoor_organization" "test" {
 }
 `, name, displayName)
 }
+
+func testAccOrganizationResourceProtectedConfig(name string, protected bool) string {
+	return fmt.Sprintf(`
+resource "casdoor_organization" "test" {
+  name                = %q
+  display_name        = "Protected Organization"
+  deletion_protection = %t
+}
+`, name, protected)
+}
+
+func testAccOrganizationResourceDeletePolicyConfig(name, policy string) string {
+	return fmt.Sprintf(`
+resource "casdoor_organization" "test" {
+  name          = %q
+  display_name  = "Organization with Delete Policy"
+  delete_policy = %q
+
+  timeouts {
+    delete = "5m"
+  }
+}
+`, name, policy)
+}
@@ -0,0 +1,161 @@
+// Copyright (c) HashiCorp, Inc.
+
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ datasource.DataSource              = &PaymentsDataSource{}
+	_ datasource.DataSourceWithConfigure = &PaymentsDataSource{}
+)
+
+type PaymentsDataSource struct {
+	client       casdoorClient
+	providerData *CasdoorProviderData
+}
+
+type PaymentModel struct {
+	Name                types.String  `tfsdk:"name"`
+	CreatedTime         types.String  `tfsdk:"created_time"`
+	DisplayName         types.String  `tfsdk:"display_name"`
+	Provider            types.String  `tfsdk:"provider"`
+	Type                types.String  `tfsdk:"type"`
+	Products            types.List    `tfsdk:"products"`
+	ProductsDisplayName types.String  `tfsdk:"products_display_name"`
+	Detail              types.String  `tfsdk:"detail"`
+	Currency            types.String  `tfsdk:"currency"`
+	Price               types.Float64 `tfsdk:"price"`
+	User                types.String  `tfsdk:"user"`
+	Order               types.String  `tfsdk:"order"`
+	OutOrderId          types.String  `tfsdk:"out_order_id"`
+	State               types.String  `tfsdk:"state"`
+	Message             types.String  `tfsdk:"message"`
+}
+
+// paymentRecords are the records listed by the casdoor_payments data source.
+var paymentRecords = billingRecords[casdoorsdk.Payment, PaymentModel]{
+	kind:        "payment",
+	plural:      "payments",
+	description: "Lists the payments of a Casdoor organization, optionally filtered by user, product, state and creation time.",
+	withProduct: true,
+	attributes: map[string]schema.Attribute{
+		"provider": schema.StringAttribute{
+			Description: "The payment provider the payment was made with.",
+			Computed:    true,
+		},
+		"type": schema.StringAttribute{
+			Description: "The type of the payment provider.",
+			Computed:    true,
+		},
+		"products": schema.ListAttribute{
+			Description: "Names of the products paid for.",
+			Computed:    true,
+			ElementType: types.StringType,
+		},
+		"products_display_name": schema.StringAttribute{
+			Description: "The display names of the products paid for.",
+			Computed:    true,
+		},
+		"detail": schema.StringAttribute{
+			Description: "The detail of the payment.",
+			Computed:    true,
+		},
+		"currency": schema.StringAttribute{
+			Description: "The currency of the price.",
+			Computed:    true,
+		},
+		"price": schema.Float64Attribute{
+			Description: "The amount paid.",
+			Computed:    true,
+		},
+		"user": schema.StringAttribute{
+			Description: "The name of the paying user.",
+			Computed:    true,
+		},
+		"order": schema.StringAttribute{
+			Description: "The name of the order the payment is for.",
+			Computed:    true,
+		},
+		"out_order_id": schema.StringAttribute{
+			Description: "The ID of the order at the payment provider.",
+			Computed:    true,
+		},
+		"state": schema.StringAttribute{
+			Description: "The state of the payment (e.g., 'Created', 'Paid', 'Canceled').",
+			Computed:    true,
+		},
+		"message": schema.StringAttribute{
+			Description: "The message the payment provider returned.",
+			Computed:    true,
+		},
+	},
+
+	list: casdoorClient.ListPayments,
+	match: func(filter billingFilter, payment *casdoorsdk.Payment) bool {
+		return filter.match(payment.User, payment.Products, payment.State, payment.CreatedTime)
+	},
+	flatten: func(ctx context.Context, payment *casdoorsdk.Payment) (PaymentModel, diag.Diagnostics) {
+		products, diags := types.ListValueFrom(ctx, types.StringType, payment.Products)
+
+		return PaymentModel{
+			Name:                types.StringValue(payment.Name),
+			CreatedTime:         types.StringValue(payment.CreatedTime),
+			DisplayName:         types.StringValue(payment.DisplayName),
+			Provider:            types.StringValue(payment.Provider),
+			Type:                types.StringValue(payment.Type),
+			Products:            products,
+			ProductsDisplayName: types.StringValue(payment.ProductsDisplayName),
+			Detail:              types.StringValue(payment.Detail),
+			Currency:            types.StringValue(payment.Currency),
+			Price:               types.Float64Value(payment.Price),
+			User:                types.StringValue(payment.User),
+			Order:               types.StringValue(payment.Order),
+			OutOrderId:          types.StringValue(payment.OutOrderId),
+			State:               types.StringValue(payment.State),
+			Message:             types.StringValue(payment.Message),
+		}, diags
+	},
+}
+
+func NewPaymentsDataSource() datasource.DataSource {
+	return &PaymentsDataSource{}
+}
+
+func (d *PaymentsDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_payments"
+}
+
+func (d *PaymentsDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = paymentRecords.schema()
+}
+
+func (d *PaymentsDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*CasdoorProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *CasdoorProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	d.client = data.Client
+	d.providerData = data
+}
+
+func (d *PaymentsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	paymentRecords.read(ctx, d.client, req, resp)
+}
@@ -0,0 +1,83 @@
+// Copyright (c) HashiCorp, Inc.
+
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+// addTestPayments adds three payments of users named after name: two of
+// "<name>-alice", paid in January and canceled in March, and one of
+// "<name>-bob", paid in February.
+func addTestPayments(t *testing.T, config CasdoorTestConfig, name string) {
+	t.Helper()
+
+	payments := []*casdoorsdk.Payment{
+		{Owner: "built-in", Name: name + "-1", CreatedTime: "2026-01-15T10:00:00Z", User: name + "-alice", Products: []string{name + "-basic"}, Currency: "USD", Price: 9.99, State: "Paid"},
+		{Owner: "built-in", Name: name + "-2", CreatedTime: "2026-02-15T10:00:00Z", User: name + "-bob", Products: []string{name + "-premium"}, Currency: "USD", Price: 29.99, State: "Paid"},
+		{Owner: "built-in", Name: name + "-3", CreatedTime: "2026-03-15T10:00:00Z", User: name + "-alice", Products: []string{name + "-premium"}, Currency: "USD", Price: 29.99, State: "Canceled"},
+	}
+	addTestBillingRecords(t, config, "payment", payments,
+		func(client *casdoorsdk.Client, payment *casdoorsdk.Payment) (bool, error) {
+			return client.AddPayment(payment)
+		},
+		func(client *casdoorsdk.Client, payment *casdoorsdk.Payment) (bool, error) {
+			return client.DeletePayment(payment)
+		},
+	)
+}
+
+func TestAccPaymentsDataSource_basic(t *testing.T) {
+	config := setupTestConfig(t)
+	rName := "tf-test-" + acctest.RandStringFromCharSet(8, acctest.CharSetAlphaNum)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories(config),
+		Steps: []resource.TestStep{
+			{
+				PreConfig: func() { addTestPayments(t, config, rName) },
+				Config:    testAccProviderConfig(config) + testAccPaymentsDataSourceConfig(rName),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.casdoor_payments.user", "id", "built-in"),
+					resource.TestCheckResourceAttr("data.casdoor_payments.user", "payments.#", "2"),
+					resource.TestCheckResourceAttr("data.casdoor_payments.user", "payments.0.name", rName+"-1"),
+					resource.TestCheckResourceAttr("data.casdoor_payments.user", "payments.0.price", "9.99"),
+					resource.TestCheckResourceAttr("data.casdoor_payments.user", "payments.0.products.0", rName+"-basic"),
+					resource.TestCheckResourceAttr("data.casdoor_payments.user", "payments.1.name", rName+"-3"),
+					resource.TestCheckResourceAttr("data.casdoor_payments.paid_premium", "payments.#", "1"),
+					resource.TestCheckResourceAttr("data.casdoor_payments.paid_premium", "payments.0.user", rName+"-bob"),
+					resource.TestCheckResourceAttr("data.casdoor_payments.february", "payments.#", "1"),
+					resource.TestCheckResourceAttr("data.casdoor_payments.february", "payments.0.name", rName+"-2"),
+				),
+			},
+		},
+	})
+}
+
+func testAccPaymentsDataSourceConfig(name string) string {
+	return fmt.Sprintf(`
+data "casdoor_payments" "user" {
+  owner     = "built-in"
+  user      = "%[1]s-alice"
+  page_size = 2
+}
+
+data "casdoor_payments" "paid_premium" {
+  owner   = "built-in"
+  product = "%[1]s-premium"
+  state   = "Paid"
+}
+
+data "casdoor_payments" "february" {
+  owner          = "built-in"
+  product        = "%[1]s-premium"
+  created_after  = "2026-02-01T00:00:00Z"
+  created_before = "2026-03-01T00:00:00Z"
+}
+`, name)
+}
@@ -0,0 +1,407 @@
+// Copyright (c) HashiCorp, Inc.
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ resource.Resource                = &PermissionApprovalResource{}
+	_ resource.ResourceWithConfigure   = &PermissionApprovalResource{}
+	_ resource.ResourceWithImportState = &PermissionApprovalResource{}
+)
+
+type PermissionApprovalResource struct {
+	client       casdoorClient
+	providerData *CasdoorProviderData
+}
+
+type PermissionApprovalResourceModel struct {
+	ID           types.String   `tfsdk:"id"`
+	PermissionID types.String   `tfsdk:"permission_id"`
+	Approver     types.String   `tfsdk:"approver"`
+	Decision     types.String   `tfsdk:"decision"`
+	Submitter    types.String   `tfsdk:"submitter"`
+	ApproveTime  types.String   `tfsdk:"approve_time"`
+	Timeouts     timeouts.Value `tfsdk:"timeouts"`
+}
+
+func NewPermissionApprovalResource() resource.Resource {
+	return &PermissionApprovalResource{}
+}
+
+func (r *PermissionApprovalResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_permission_approval"
+}
+
+func (r *PermissionApprovalResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Approves or rejects a pending Casdoor permission. Destroying the approval returns the permission to the 'Pending' state.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "The ID of the approved permission in the format 'owner/name'.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"permission_id": schema.StringAttribute{
+				Description: "The ID of the permission to approve in the format 'owner/name'.",
+				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"approver": schema.StringAttribute{
+				Description: "The user recording the decision (format: 'organization/username').",
+				Required:    true,
+			},
+			"decision": schema.StringAttribute{
+				Description: "The approval decision ('Approved' or 'Rejected').",
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString(permissionStateApproved),
+			},
+			"submitter": schema.StringAttribute{
+				Description: "The user who submitted the permission for approval.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"approve_time": schema.StringAttribute{
+				Description: "The time when the decision was recorded.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					useStateUnlessChanged(path.Root("decision"), path.Root("approver")),
+				},
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Read:   true,
+				Update: true,
+				Delete: true,
+			}),
+		},
+	}
+}
+
+func (r *PermissionApprovalResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*CasdoorProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *CasdoorProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.client = data.Client
+	r.providerData = data
+}
+
+// validateDecision checks that the configured decision is one Casdoor knows.
+func validateDecision(plan PermissionApprovalResourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	decision := plan.Decision.ValueString()
+	if decision != permissionStateApproved && decision != permissionStateRejected {
+		diags.AddAttributeError(
+			path.Root("decision"),
+			"Invalid Decision",
+			fmt.Sprintf("Expected %q or %q, got: %q", permissionStateApproved, permissionStateRejected, decision),
+		)
+	}
+
+	return diags
+}
+
+// decide records the approval decision on the permission.
+func (r *PermissionApprovalResource) decide(ctx context.Context, plan *PermissionApprovalResourceModel, permission *casdoorsdk.Permission) error {
+	permission.Approver = plan.Approver.ValueString()
+	permission.ApproveTime = time.Now().UTC().Format(time.RFC3339)
+	permission.State = plan.Decision.ValueString()
+
+	ok, err := r.client.Permissions().Update(ctx, permission)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("Casdoor returned failure when updating permission %q", plan.PermissionID.ValueString())
+	}
+
+	plan.ID = types.StringValue(permission.Owner + "/" + permission.Name)
+	plan.Submitter = types.StringValue(permission.Submitter)
+	plan.ApproveTime = types.StringValue(permission.ApproveTime)
+
+	return nil
+}
+
+func (r *PermissionApprovalResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	if checkUnconfigured(r.client, &resp.Diagnostics) {
+		return
+	}
+
+	var plan PermissionApprovalResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, done := startOperation(ctx, plan.Timeouts.Create, defaultOperationTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(validateDecision(plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	permissionID := plan.PermissionID.ValueString()
+	if !strings.Contains(permissionID, "/") {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("permission_id"),
+			"Invalid Permission ID",
+			fmt.Sprintf("Expected permission ID in the format 'owner/name', got: %q", permissionID),
+		)
+		return
+	}
+
+	permission, err := r.client.Permissions().Get(ctx, permissionID)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading Permission",
+			fmt.Sprintf("Could not read permission %q: %s", permissionID, err),
+		)
+		return
+	}
+
+	if permission == nil {
+		resp.Diagnostics.AddError(
+			"Permission Not Found",
+			fmt.Sprintf("Permission %q does not exist.", permissionID),
+		)
+		return
+	}
+
+	// Only pending permissions can be decided on. A permission that already
+	// carries the same decision by the same approver is taken over as is.
+	if permission.State == plan.Decision.ValueString() {
+		if permission.Approver != plan.Approver.ValueString() {
+			resp.Diagnostics.AddError(
+				"Permission Already Decided",
+				fmt.Sprintf("Permission %q is already in state %q, decided by %q rather than %q.",
+					permissionID, permission.State, permission.Approver, plan.Approver.ValueString()),
+			)
+			return
+		}
+
+		plan.ID = types.StringValue(permission.Owner + "/" + permission.Name)
+		plan.Submitter = types.StringValue(permission.Submitter)
+		plan.ApproveTime = types.StringValue(permission.ApproveTime)
+		resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+		return
+	}
+
+	if permission.State != permissionStatePending {
+		resp.Diagnostics.AddError(
+			"Permission Not Pending",
+			fmt.Sprintf("Permission %q is in state %q; only pending permissions can be approved or rejected.", permissionID, permission.State),
+		)
+		return
+	}
+
+	if err := r.decide(ctx, &plan, permission); err != nil {
+		resp.Diagnostics.AddError(
+			"Error Approving Permission",
+			fmt.Sprintf("Could not record decision %q on permission %q: %s", plan.Decision.ValueString(), permissionID, err),
+		)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+}
+
+func (r *PermissionApprovalResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	if r.client == nil {
+		return
+	}
+
+	var state PermissionApprovalResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, done := startOperation(ctx, state.Timeouts.Read, defaultReadTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	permission, err := r.client.Permissions().Get(ctx, state.PermissionID.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading Permission",
+			fmt.Sprintf("Could not read permission %q: %s", state.PermissionID.ValueString(), err),
+		)
+		return
+	}
+
+	// The approval is gone if the permission was deleted or sent back for
+	// approval outside of Terraform.
+	if permission == nil || (permission.State != permissionStateApproved && permission.State != permissionStateRejected) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	state.ID = types.StringValue(permission.Owner + "/" + permission.Name)
+	state.PermissionID = types.StringValue(permission.Owner + "/" + permission.Name)
+	state.Approver = types.StringValue(permission.Approver)
+	state.Decision = types.StringValue(permission.State)
+	state.Submitter = types.StringValue(permission.Submitter)
+	state.ApproveTime = types.StringValue(permission.ApproveTime)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+func (r *PermissionApprovalResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	if checkUnconfigured(r.client, &resp.Diagnostics) {
+		return
+	}
+
+	var plan PermissionApprovalResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, done := startOperation(ctx, plan.Timeouts.Update, defaultOperationTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(validateDecision(plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The approval time is only planned as unknown when the decision or the
+	// approver changes; anything else (the timeouts) leaves the recorded
+	// decision as it is.
+	if !plan.ApproveTime.IsUnknown() {
+		resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+		return
+	}
+
+	permissionID := plan.PermissionID.ValueString()
+
+	permission, err := r.client.Permissions().Get(ctx, permissionID)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading Permission",
+			fmt.Sprintf("Could not read permission %q: %s", permissionID, err),
+		)
+		return
+	}
+
+	if permission == nil {
+		resp.Diagnostics.AddError(
+			"Permission Not Found",
+			fmt.Sprintf("Permission %q does not exist.", permissionID),
+		)
+		return
+	}
+
+	if err := r.decide(ctx, &plan, permission); err != nil {
+		resp.Diagnostics.AddError(
+			"Error Approving Permission",
+			fmt.Sprintf("Could not record decision %q on permission %q: %s", plan.Decision.ValueString(), permissionID, err),
+		)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+}
+
+func (r *PermissionApprovalResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	if checkUnconfigured(r.client, &resp.Diagnostics) {
+		return
+	}
+
+	var state PermissionApprovalResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, done := startOperation(ctx, state.Timeouts.Delete, defaultOperationTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	permission, err := r.client.Permissions().Get(ctx, state.PermissionID.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading Permission",
+			fmt.Sprintf("Could not read permission %q: %s", state.PermissionID.ValueString(), err),
+		)
+		return
+	}
+
+	// Nothing to revoke if the permission itself is already gone.
+	if permission == nil {
+		return
+	}
+
+	permission.Approver = ""
+	permission.ApproveTime = ""
+	permission.State = permissionStatePending
+
+	ok, err := r.client.Permissions().Update(ctx, permission)
+	if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("revoking approval of permission %q", state.PermissionID.ValueString())) {
+		return
+	}
+}
+
+func (r *PermissionApprovalResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	if !strings.Contains(req.ID, "/") {
+		resp.Diagnostics.AddError(
+			"Invalid Import ID",
+			fmt.Sprintf("Expected import ID in the format 'owner/name', got: %q", req.ID),
+		)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), req.ID)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("permission_id"), req.ID)...)
+}
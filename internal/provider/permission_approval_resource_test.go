@@ -0,0 +1,129 @@
+// Copyright (c) HashiCorp, Inc.
+
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccPermissionApprovalResource_basic(t *testing.T) {
+	config := setupTestConfig(t)
+	rName := "tf-test-" + acctest.RandStringFromCharSet(8, acctest.CharSetAlphaNum)
+	resourceName := "casdoor_permission_approval.test"
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories(config),
+		Steps: []resource.TestStep{
+			// Submitted permissions start out pending
+			{
+				Config: testAccProviderConfig(config) + testAccPermissionApprovalPermissionConfig(rName),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("casdoor_permission.test", "state", "Pending"),
+					resource.TestCheckResourceAttr("casdoor_permission.test", "approver", ""),
+				),
+			},
+			// Approve the permission
+			{
+				Config: testAccProviderConfig(config) + testAccPermissionApprovalResourceConfig(rName, "Approved"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "permission_id", "built-in/"+rName),
+					resource.TestCheckResourceAttr(resourceName, "decision", "Approved"),
+					resource.TestCheckResourceAttr(resourceName, "approver", "built-in/admin"),
+					resource.TestCheckResourceAttr(resourceName, "submitter", "built-in/admin"),
+					resource.TestCheckResourceAttrSet(resourceName, "approve_time"),
+				),
+			},
+			// The permission reflects the approval after refresh
+			{
+				Config: testAccProviderConfig(config) + testAccPermissionApprovalResourceConfig(rName, "Approved"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("casdoor_permission.test", "state", "Approved"),
+					resource.TestCheckResourceAttr("casdoor_permission.test", "approver", "built-in/admin"),
+					resource.TestCheckResourceAttrSet("casdoor_permission.test", "approve_time"),
+				),
+			},
+			// Change the decision in place
+			{
+				Config: testAccProviderConfig(config) + testAccPermissionApprovalResourceConfig(rName, "Rejected"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "decision", "Rejected"),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:      resourceName,
+				ImportState:       true,
+				ImportStateId:     "built-in/" + rName,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestAccPermissionApprovalResource_alreadyDecided(t *testing.T) {
+	config := setupTestConfig(t)
+	rName := "tf-test-" + acctest.RandStringFromCharSet(8, acctest.CharSetAlphaNum)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories(config),
+		Steps: []resource.TestStep{
+			// The deprecated approval attributes are still written
+			{
+				Config: testAccProviderConfig(config) + testAccPermissionApprovalDecidedConfig(rName),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("casdoor_permission.test", "state", "Approved"),
+					resource.TestCheckResourceAttr("casdoor_permission.test", "approver", "built-in/admin"),
+				),
+			},
+			// A decision by another approver is not taken over
+			{
+				Config: testAccProviderConfig(config) + testAccPermissionApprovalDecidedConfig(rName) + `
+resource "casdoor_permission_approval" "test" {
+  permission_id = casdoor_permission.test.id
+  approver      = "built-in/other-admin"
+}
+`,
+				ExpectError: regexp.MustCompile("Permission Already Decided"),
+			},
+		},
+	})
+}
+
+func testAccPermissionApprovalDecidedConfig(name string) string {
+	return fmt.Sprintf(`
+resource "casdoor_permission" "test" {
+  owner        = "built-in"
+  name         = %q
+  display_name = "Decided Permission"
+  submitter    = "built-in/admin"
+  approver     = "built-in/admin"
+  state        = "Approved"
+}
+`, name)
+}
+
+func testAccPermissionApprovalPermissionConfig(name string) string {
+	return fmt.Sprintf(`
+resource "casdoor_permission" "test" {
+  owner        = "built-in"
+  name         = %q
+  display_name = "Pending Permission"
+  submitter    = "built-in/admin"
+}
+`, name)
+}
+
+func testAccPermissionApprovalResourceConfig(name, decision string) string {
+	return testAccPermissionApprovalPermissionConfig(name) + fmt.Sprintf(`
+resource "casdoor_permission_approval" "test" {
+  permission_id = casdoor_permission.test.id
+  approver      = "built-in/admin"
+  decision      = %q
+}
+`, decision)
+}
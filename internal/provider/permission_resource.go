@@ -8,13 +8,16 @@ import (
 	"time"
 
 	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
@@ -22,34 +25,38 @@ var (
 	_ resource.Resource                = &PermissionResource{}
 	_ resource.ResourceWithConfigure   = &PermissionResource{}
 	_ resource.ResourceWithImportState = &PermissionResource{}
+	_ resource.ResourceWithModifyPlan  = &PermissionResource{}
 )
 
 type PermissionResource struct {
-	client *casdoorsdk.Client
+	client       casdoorClient
+	providerData *CasdoorProviderData
 }
 
 type PermissionResourceModel struct {
-	ID           types.String `tfsdk:"id"`
-	Owner        types.String `tfsdk:"owner"`
-	Name         types.String `tfsdk:"name"`
-	CreatedTime  types.String `tfsdk:"created_time"`
-	DisplayName  types.String `tfsdk:"display_name"`
-	Description  types.String `tfsdk:"description"`
-	Users        types.List   `tfsdk:"users"`
-	Groups       types.List   `tfsdk:"groups"`
-	Roles        types.List   `tfsdk:"roles"`
-	Domains      types.List   `tfsdk:"domains"`
-	Model        types.String `tfsdk:"model"`
-	Adapter      types.String `tfsdk:"adapter"`
-	ResourceType types.String `tfsdk:"resource_type"`
-	Resources    types.List   `tfsdk:"resources"`
-	Actions      types.List   `tfsdk:"actions"`
-	Effect       types.String `tfsdk:"effect"`
-	IsEnabled    types.Bool   `tfsdk:"is_enabled"`
-	Submitter    types.String `tfsdk:"submitter"`
-	Approver     types.String `tfsdk:"approver"`
-	ApproveTime  types.String `tfsdk:"approve_time"`
-	State        types.String `tfsdk:"state"`
+	ID            types.String   `tfsdk:"id"`
+	Owner         types.String   `tfsdk:"owner"`
+	Name          types.String   `tfsdk:"name"`
+	CreatedTime   types.String   `tfsdk:"created_time"`
+	DisplayName   types.String   `tfsdk:"display_name"`
+	Description   types.String   `tfsdk:"description"`
+	Users         types.List     `tfsdk:"users"`
+	Groups        types.List     `tfsdk:"groups"`
+	Roles         types.List     `tfsdk:"roles"`
+	Domains       types.List     `tfsdk:"domains"`
+	Model         types.String   `tfsdk:"model"`
+	Adapter       types.String   `tfsdk:"adapter"`
+	ResourceType  types.String   `tfsdk:"resource_type"`
+	Resources     types.List     `tfsdk:"resources"`
+	Actions       types.List     `tfsdk:"actions"`
+	Effect        types.String   `tfsdk:"effect"`
+	IsEnabled     types.Bool     `tfsdk:"is_enabled"`
+	Submitter     types.String   `tfsdk:"submitter"`
+	Approver      types.String   `tfsdk:"approver"`
+	ApproveTime   types.String   `tfsdk:"approve_time"`
+	State         types.String   `tfsdk:"state"`
+	AdoptExisting types.Bool     `tfsdk:"adopt_existing"`
+	Timeouts      timeouts.Value `tfsdk:"timeouts"`
 }
 
 func NewPermissionResource() resource.Resource {
@@ -60,7 +67,7 @@ func (r *PermissionResource) Metadata(_ context.Context, req resource.MetadataRe
 	resp.TypeName = req.ProviderTypeName + "_permission"
 }
 
-func (r *PermissionResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+func (r *PermissionResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		Description: "Manages a Casdoor permission.",
 		Attributes: map[string]schema.Attribute{
@@ -68,22 +75,13 @@ func (r *PermissionResource) Schema(_ context.Context, _ resource.SchemaRequest,
 				Description: "The ID of the permission in the format 'owner/name'.",
 				Computed:    true,
 				PlanModifiers: []planmodifier.String{
-					stringplanmodifier.UseStateForUnknown(),
-				},
-			},
-			"owner": schema.StringAttribute{
-				Description: "The organization that owns this permission.",
-				Required:    true,
-				PlanModifiers: []planmodifier.String{
-					stringplanmodifier.RequiresReplace(),
+					useStateUnlessChanged(path.Root("name")),
 				},
 			},
+			"owner": ownerAttribute("permission"),
 			"name": schema.StringAttribute{
-				Description: "The unique name of the permission.",
+				Description: "The unique name of the permission. Changing it renames the permission in place.",
 				Required:    true,
-				PlanModifiers: []planmodifier.String{
-					stringplanmodifier.RequiresReplace(),
-				},
 			},
 			"created_time": schema.StringAttribute{
 				Description: "The time when the permission was created.",
@@ -165,29 +163,51 @@ func (r *PermissionResource) Schema(_ context.Context, _ resource.SchemaRequest,
 				Default:     booldefault.StaticBool(true),
 			},
 			"submitter": schema.StringAttribute{
-				Description: "The user who submitted this permission for approval.",
-				Optional:    true,
-				Computed:    true,
-				Default:     stringdefault.StaticString(""),
+				Description: "The user who submitted this permission for approval. When set, the permission is created in the 'Pending' state " +
+					"and must be approved with casdoor_permission_approval; otherwise it is created as 'Approved'.",
+				Optional: true,
+				Computed: true,
+				Default:  stringdefault.StaticString(""),
 			},
 			"approver": schema.StringAttribute{
-				Description: "The user who approved this permission.",
-				Optional:    true,
-				Computed:    true,
-				Default:     stringdefault.StaticString(""),
+				Description: "The user who approved or rejected this permission. Managed by casdoor_permission_approval.",
+				DeprecationMessage: "approver is managed by Casdoor's approval workflow. A configured value is still written to the " +
+					"permission; approve or reject the permission with casdoor_permission_approval instead.",
+				Optional: true,
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
 			},
 			"approve_time": schema.StringAttribute{
-				Description: "The time when this permission was approved.",
-				Optional:    true,
-				Computed:    true,
-				Default:     stringdefault.StaticString(""),
+				Description: "The time when this permission was approved or rejected. Managed by casdoor_permission_approval.",
+				DeprecationMessage: "approve_time is managed by Casdoor's approval workflow. A configured value is still written to the " +
+					"permission; approve or reject the permission with casdoor_permission_approval instead.",
+				Optional: true,
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
 			},
 			"state": schema.StringAttribute{
-				Description: "The approval state of this permission.",
-				Optional:    true,
-				Computed:    true,
-				Default:     stringdefault.StaticString(""),
+				Description: "The approval state of this permission ('Pending', 'Approved' or 'Rejected'). Managed by casdoor_permission_approval.",
+				DeprecationMessage: "state is managed by Casdoor's approval workflow. A configured value is still written to the " +
+					"permission; approve or reject the permission with casdoor_permission_approval instead.",
+				Optional: true,
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
 			},
+			"adopt_existing": adoptExistingAttribute("permission"),
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Read:   true,
+				Update: true,
+				Delete: true,
+			}),
 		},
 	}
 }
@@ -197,16 +217,54 @@ func (r *PermissionResource) Configure(_ context.Context, req resource.Configure
 		return
 	}
 
-	client, ok := req.ProviderData.(*casdoorsdk.Client)
+	data, ok := req.ProviderData.(*CasdoorProviderData)
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Resource Configure Type",
-			fmt.Sprintf("Expected *casdoorsdk.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected *CasdoorProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 		return
 	}
 
-	r.client = client
+	r.client = data.Client
+	r.providerData = data
+}
+
+func (r *PermissionResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	planDefaultOwner(ctx, req, resp, r.providerData)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	planAdoption(ctx, req, resp, r.providerData, "permission", r.Read)
+}
+
+// Approval states of a Casdoor permission.
+const (
+	permissionStatePending  = "Pending"
+	permissionStateApproved = "Approved"
+	permissionStateRejected = "Rejected"
+)
+
+// configuredPermissionApproval sets the deprecated approval fields of
+// permission that are set in config, which are written as they used to be
+// before casdoor_permission_approval managed them.
+func configuredPermissionApproval(ctx context.Context, config tfsdk.Config, permission *casdoorsdk.Permission) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	for name, field := range map[string]*string{
+		"approver":     &permission.Approver,
+		"approve_time": &permission.ApproveTime,
+		"state":        &permission.State,
+	} {
+		var value types.String
+		diags.Append(config.GetAttribute(ctx, path.Root(name), &value)...)
+		if !value.IsNull() && !value.IsUnknown() {
+			*field = value.ValueString()
+		}
+	}
+
+	return diags
 }
 
 func permissionPlanToSDK(ctx context.Context, plan PermissionResourceModel, createdTime string) (*casdoorsdk.Permission, diag.Diagnostics) {
@@ -250,6 +308,10 @@ func permissionPlanToSDK(ctx context.Context, plan PermissionResourceModel, crea
 }
 
 func (r *PermissionResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	if checkUnconfigured(r.client, &resp.Diagnostics) {
+		return
+	}
+
 	var plan PermissionResourceModel
 
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
@@ -257,6 +319,12 @@ func (r *PermissionResource) Create(ctx context.Context, req resource.CreateRequ
 		return
 	}
 
+	ctx, done := startOperation(ctx, plan.Timeouts.Create, defaultOperationTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	createdTime := plan.CreatedTime.ValueString()
 	if createdTime == "" {
 		createdTime = time.Now().UTC().Format(time.RFC3339)
@@ -268,13 +336,34 @@ func (r *PermissionResource) Create(ctx context.Context, req resource.CreateRequ
 		return
 	}
 
-	ok, err := r.client.AddPermission(permission)
-	if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("creating permission %q", plan.Name.ValueString())) {
+	// A submitted permission waits for casdoor_permission_approval, the same
+	// way Casdoor treats permissions created by non-admin users.
+	permission.Approver = ""
+	permission.ApproveTime = ""
+	permission.State = permissionStateApproved
+	if permission.Submitter != "" {
+		permission.State = permissionStatePending
+	}
+
+	resp.Diagnostics.Append(configuredPermissionApproval(ctx, req.Config, permission)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	adopted := adoptOnCreate(ctx, r.providerData, req.Plan, req.Config, &resp.Diagnostics, "permission", plan.Owner.ValueString()+"/"+plan.Name.ValueString(), permission, r.client.Permissions())
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
+	if !adopted {
+		ok, err := r.client.Permissions().Add(ctx, permission)
+		if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("creating permission %q", plan.Name.ValueString())) {
+			return
+		}
+	}
+
 	// Read back the permission to get server-generated values like CreatedTime.
-	createdPermission, err := r.client.GetPermission(plan.Owner.ValueString() + "/" + plan.Name.ValueString())
+	createdPermission, err := r.client.Permissions().Get(ctx, plan.Owner.ValueString()+"/"+plan.Name.ValueString())
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Reading Permission",
@@ -285,7 +374,13 @@ func (r *PermissionResource) Create(ctx context.Context, req resource.CreateRequ
 
 	if createdPermission != nil {
 		plan.CreatedTime = types.StringValue(createdPermission.CreatedTime)
+		permission.Approver = createdPermission.Approver
+		permission.ApproveTime = createdPermission.ApproveTime
+		permission.State = createdPermission.State
 	}
+	plan.Approver = types.StringValue(permission.Approver)
+	plan.ApproveTime = types.StringValue(permission.ApproveTime)
+	plan.State = types.StringValue(permission.State)
 
 	// Set list values to null if empty.
 	plan.Users, diags = stringListFromSDK(ctx, permission.Users)
@@ -306,6 +401,10 @@ func (r *PermissionResource) Create(ctx context.Context, req resource.CreateRequ
 }
 
 func (r *PermissionResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	if r.client == nil {
+		return
+	}
+
 	var state PermissionResourceModel
 
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
@@ -313,7 +412,13 @@ func (r *PermissionResource) Read(ctx context.Context, req resource.ReadRequest,
 		return
 	}
 
-	permission, err := r.client.GetPermission(state.Owner.ValueString() + "/" + state.Name.ValueString())
+	ctx, done := startOperation(ctx, state.Timeouts.Read, defaultReadTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	permission, err := r.client.Permissions().Get(ctx, state.Owner.ValueString()+"/"+state.Name.ValueString())
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Reading Permission",
@@ -361,25 +466,61 @@ func (r *PermissionResource) Read(ctx context.Context, req resource.ReadRequest,
 		return
 	}
 
+	resp.Diagnostics.Append(setObjectFingerprint(ctx, resp.Private, permission)...)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
 }
 
 func (r *PermissionResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	var plan PermissionResourceModel
+	if checkUnconfigured(r.client, &resp.Diagnostics) {
+		return
+	}
+
+	var plan, state PermissionResourceModel
 
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, done := startOperation(ctx, plan.Timeouts.Update, defaultOperationTimeout, &resp.Diagnostics)
+	defer done()
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
+	if checkUpdateConflict(ctx, r.providerData, &resp.Diagnostics, req.Private, "permission", state.Owner.ValueString()+"/"+state.Name.ValueString(), r.client.Permissions().Get) {
+		return
+	}
+
 	permission, diags := permissionPlanToSDK(ctx, plan, plan.CreatedTime.ValueString())
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	ok, err := r.client.UpdatePermission(permission)
-	if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("updating permission %q", plan.Name.ValueString())) {
+	// The approval fields are managed by casdoor_permission_approval and may
+	// have changed since the last refresh, so send what Casdoor has now
+	// rather than the prior state, which would revert a decision.
+	existingPermission, err := r.client.Permissions().Get(ctx, state.Owner.ValueString()+"/"+state.Name.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading Permission",
+			fmt.Sprintf("Could not read permission %q before update: %s", state.Name.ValueString(), err),
+		)
+		return
+	}
+	if existingPermission != nil {
+		permission.Approver = existingPermission.Approver
+		permission.ApproveTime = existingPermission.ApproveTime
+		permission.State = existingPermission.State
+	}
+	resp.Diagnostics.Append(configuredPermissionApproval(ctx, req.Config, permission)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if renameUpdate(ctx, resp, r.client, "permission", plan.Owner.ValueString(), state.Name.ValueString(), plan.Name.ValueString(), permission) {
 		return
 	}
 
@@ -398,10 +539,15 @@ func (r *PermissionResource) Update(ctx context.Context, req resource.UpdateRequ
 	resp.Diagnostics.Append(diags...)
 
 	plan.ID = types.StringValue(plan.Owner.ValueString() + "/" + plan.Name.ValueString())
+	resp.Diagnostics.Append(clearObjectFingerprint(ctx, resp.Private)...)
 	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
 }
 
 func (r *PermissionResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	if checkUnconfigured(r.client, &resp.Diagnostics) {
+		return
+	}
+
 	var state PermissionResourceModel
 
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
@@ -409,12 +555,18 @@ func (r *PermissionResource) Delete(ctx context.Context, req resource.DeleteRequ
 		return
 	}
 
+	ctx, done := startOperation(ctx, state.Timeouts.Delete, defaultOperationTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	permission := &casdoorsdk.Permission{
 		Owner: state.Owner.ValueString(),
 		Name:  state.Name.ValueString(),
 	}
 
-	ok, err := r.client.DeletePermission(permission)
+	ok, err := r.client.Permissions().Delete(ctx, permission)
 	if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("deleting permission %q", state.Name.ValueString())) {
 		return
 	}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
@@ -24,27 +25,31 @@ var (
 	_ resource.Resource                = &PlanResource{}
 	_ resource.ResourceWithConfigure   = &PlanResource{}
 	_ resource.ResourceWithImportState = &PlanResource{}
+	_ resource.ResourceWithModifyPlan  = &PlanResource{}
 )
 
 type PlanResource struct {
-	client *casdoorsdk.Client
+	client       casdoorClient
+	providerData *CasdoorProviderData
 }
 
 type PlanResourceModel struct {
-	ID               types.String  `tfsdk:"id"`
-	Owner            types.String  `tfsdk:"owner"`
-	Name             types.String  `tfsdk:"name"`
-	CreatedTime      types.String  `tfsdk:"created_time"`
-	DisplayName      types.String  `tfsdk:"display_name"`
-	Description      types.String  `tfsdk:"description"`
-	Price            types.Float64 `tfsdk:"price"`
-	Currency         types.String  `tfsdk:"currency"`
-	Period           types.String  `tfsdk:"period"`
-	Product          types.String  `tfsdk:"product"`
-	PaymentProviders types.List    `tfsdk:"payment_providers"`
-	IsEnabled        types.Bool    `tfsdk:"is_enabled"`
-	Role             types.String  `tfsdk:"role"`
-	Options          types.List    `tfsdk:"options"`
+	ID               types.String   `tfsdk:"id"`
+	Owner            types.String   `tfsdk:"owner"`
+	Name             types.String   `tfsdk:"name"`
+	CreatedTime      types.String   `tfsdk:"created_time"`
+	DisplayName      types.String   `tfsdk:"display_name"`
+	Description      types.String   `tfsdk:"description"`
+	Price            types.Float64  `tfsdk:"price"`
+	Currency         types.String   `tfsdk:"currency"`
+	Period           types.String   `tfsdk:"period"`
+	Product          types.String   `tfsdk:"product"`
+	PaymentProviders types.List     `tfsdk:"payment_providers"`
+	IsEnabled        types.Bool     `tfsdk:"is_enabled"`
+	Role             types.String   `tfsdk:"role"`
+	Options          types.List     `tfsdk:"options"`
+	AdoptExisting    types.Bool     `tfsdk:"adopt_existing"`
+	Timeouts         timeouts.Value `tfsdk:"timeouts"`
 }
 
 func NewPlanResource() resource.Resource {
@@ -55,7 +60,7 @@ func (r *PlanResource) Metadata(_ context.Context, req resource.MetadataRequest,
 	resp.TypeName = req.ProviderTypeName + "_plan"
 }
 
-func (r *PlanResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+func (r *PlanResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		Description: "Manages a Casdoor subscription plan for SaaS products.",
 		Attributes: map[string]schema.Attribute{
@@ -66,13 +71,7 @@ func (r *PlanResource) Schema(_ context.Context, _ resource.SchemaRequest, resp
 					stringplanmodifier.UseStateForUnknown(),
 				},
 			},
-			"owner": schema.StringAttribute{
-				Description: "The organization that owns this plan.",
-				Required:    true,
-				PlanModifiers: []planmodifier.String{
-					stringplanmodifier.RequiresReplace(),
-				},
-			},
+			"owner": ownerAttribute("plan"),
 			"name": schema.StringAttribute{
 				Description: "The unique name of the plan.",
 				Required:    true,
@@ -154,6 +153,15 @@ func (r *PlanResource) Schema(_ context.Context, _ resource.SchemaRequest, resp
 					listplanmodifier.UseStateForUnknown(),
 				},
 			},
+			"adopt_existing": adoptExistingAttribute("plan"),
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Read:   true,
+				Update: true,
+				Delete: true,
+			}),
 		},
 	}
 }
@@ -163,16 +171,26 @@ func (r *PlanResource) Configure(_ context.Context, req resource.ConfigureReques
 		return
 	}
 
-	client, ok := req.ProviderData.(*casdoorsdk.Client)
+	data, ok := req.ProviderData.(*CasdoorProviderData)
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Resource Configure Type",
-			fmt.Sprintf("Expected *casdoorsdk.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected *CasdoorProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 		return
 	}
 
-	r.client = client
+	r.client = data.Client
+	r.providerData = data
+}
+
+func (r *PlanResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	planDefaultOwner(ctx, req, resp, r.providerData)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	planAdoption(ctx, req, resp, r.providerData, "plan", r.Read)
 }
 
 func planPlanToSDK(ctx context.Context, plan PlanResourceModel, createdTime string) (*casdoorsdk.Plan, diag.Diagnostics) {
@@ -204,6 +222,10 @@ func planPlanToSDK(ctx context.Context, plan PlanResourceModel, createdTime stri
 }
 
 func (r *PlanResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	if checkUnconfigured(r.client, &resp.Diagnostics) {
+		return
+	}
+
 	var plan PlanResourceModel
 
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
@@ -211,6 +233,12 @@ func (r *PlanResource) Create(ctx context.Context, req resource.CreateRequest, r
 		return
 	}
 
+	ctx, done := startOperation(ctx, plan.Timeouts.Create, defaultOperationTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	createdTime := plan.CreatedTime.ValueString()
 	if createdTime == "" {
 		createdTime = time.Now().UTC().Format(time.RFC3339)
@@ -222,13 +250,20 @@ func (r *PlanResource) Create(ctx context.Context, req resource.CreateRequest, r
 		return
 	}
 
-	ok, err := r.client.AddPlan(planObj)
-	if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("creating plan %q", plan.Name.ValueString())) {
+	adopted := adoptOnCreate(ctx, r.providerData, req.Plan, req.Config, &resp.Diagnostics, "plan", plan.Owner.ValueString()+"/"+plan.Name.ValueString(), planObj, r.client.Plans())
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
+	if !adopted {
+		ok, err := r.client.Plans().Add(ctx, planObj)
+		if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("creating plan %q", plan.Name.ValueString())) {
+			return
+		}
+	}
+
 	// Read back the plan to get server-generated values.
-	createdPlan, err := r.client.GetPlan(plan.Owner.ValueString() + "/" + plan.Name.ValueString())
+	createdPlan, err := r.client.Plans().Get(ctx, plan.Owner.ValueString()+"/"+plan.Name.ValueString())
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Reading Plan",
@@ -258,6 +293,10 @@ func (r *PlanResource) Create(ctx context.Context, req resource.CreateRequest, r
 }
 
 func (r *PlanResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	if r.client == nil {
+		return
+	}
+
 	var state PlanResourceModel
 
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
@@ -265,7 +304,13 @@ func (r *PlanResource) Read(ctx context.Context, req resource.ReadRequest, resp
 		return
 	}
 
-	planObj, err := r.client.GetPlan(state.Owner.ValueString() + "/" + state.Name.ValueString())
+	ctx, done := startOperation(ctx, state.Timeouts.Read, defaultReadTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	planObj, err := r.client.Plans().Get(ctx, state.Owner.ValueString()+"/"+state.Name.ValueString())
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Reading Plan",
@@ -297,33 +342,54 @@ func (r *PlanResource) Read(ctx context.Context, req resource.ReadRequest, resp
 	optionsList, _ := types.ListValueFrom(ctx, types.StringType, planObj.Options)
 	state.Options = optionsList
 
+	resp.Diagnostics.Append(setObjectFingerprint(ctx, resp.Private, planObj)...)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
 }
 
 func (r *PlanResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	var plan PlanResourceModel
+	if checkUnconfigured(r.client, &resp.Diagnostics) {
+		return
+	}
+
+	var plan, state PlanResourceModel
 
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, done := startOperation(ctx, plan.Timeouts.Update, defaultOperationTimeout, &resp.Diagnostics)
+	defer done()
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
+	if checkUpdateConflict(ctx, r.providerData, &resp.Diagnostics, req.Private, "plan", state.Owner.ValueString()+"/"+state.Name.ValueString(), r.client.Plans().Get) {
+		return
+	}
+
 	planObj, diags := planPlanToSDK(ctx, plan, plan.CreatedTime.ValueString())
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	ok, err := r.client.UpdatePlan(planObj)
+	ok, err := r.client.Plans().Update(ctx, planObj)
 	if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("updating plan %q", plan.Name.ValueString())) {
 		return
 	}
 
 	plan.ID = types.StringValue(plan.Owner.ValueString() + "/" + plan.Name.ValueString())
+	resp.Diagnostics.Append(clearObjectFingerprint(ctx, resp.Private)...)
 	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
 }
 
 func (r *PlanResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	if checkUnconfigured(r.client, &resp.Diagnostics) {
+		return
+	}
+
 	var state PlanResourceModel
 
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
@@ -331,12 +397,18 @@ func (r *PlanResource) Delete(ctx context.Context, req resource.DeleteRequest, r
 		return
 	}
 
+	ctx, done := startOperation(ctx, state.Timeouts.Delete, defaultOperationTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	planObj := &casdoorsdk.Plan{
 		Owner: state.Owner.ValueString(),
 		Name:  state.Name.ValueString(),
 	}
 
-	ok, err := r.client.DeletePlan(planObj)
+	ok, err := r.client.Plans().Delete(ctx, planObj)
 	if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("deleting plan %q", state.Name.ValueString())) {
 		return
 	}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
@@ -24,27 +25,31 @@ var (
 	_ resource.Resource                = &PricingResource{}
 	_ resource.ResourceWithConfigure   = &PricingResource{}
 	_ resource.ResourceWithImportState = &PricingResource{}
+	_ resource.ResourceWithModifyPlan  = &PricingResource{}
 )
 
 type PricingResource struct {
-	client *casdoorsdk.Client
+	client       casdoorClient
+	providerData *CasdoorProviderData
 }
 
 type PricingResourceModel struct {
-	ID            types.String `tfsdk:"id"`
-	Owner         types.String `tfsdk:"owner"`
-	Name          types.String `tfsdk:"name"`
-	CreatedTime   types.String `tfsdk:"created_time"`
-	DisplayName   types.String `tfsdk:"display_name"`
-	Description   types.String `tfsdk:"description"`
-	Plans         types.List   `tfsdk:"plans"`
-	IsEnabled     types.Bool   `tfsdk:"is_enabled"`
-	TrialDuration types.Int64  `tfsdk:"trial_duration"`
-	Application   types.String `tfsdk:"application"`
-	Submitter     types.String `tfsdk:"submitter"`
-	Approver      types.String `tfsdk:"approver"`
-	ApproveTime   types.String `tfsdk:"approve_time"`
-	State         types.String `tfsdk:"state"`
+	ID            types.String   `tfsdk:"id"`
+	Owner         types.String   `tfsdk:"owner"`
+	Name          types.String   `tfsdk:"name"`
+	CreatedTime   types.String   `tfsdk:"created_time"`
+	DisplayName   types.String   `tfsdk:"display_name"`
+	Description   types.String   `tfsdk:"description"`
+	Plans         types.List     `tfsdk:"plans"`
+	IsEnabled     types.Bool     `tfsdk:"is_enabled"`
+	TrialDuration types.Int64    `tfsdk:"trial_duration"`
+	Application   types.String   `tfsdk:"application"`
+	Submitter     types.String   `tfsdk:"submitter"`
+	Approver      types.String   `tfsdk:"approver"`
+	ApproveTime   types.String   `tfsdk:"approve_time"`
+	State         types.String   `tfsdk:"state"`
+	AdoptExisting types.Bool     `tfsdk:"adopt_existing"`
+	Timeouts      timeouts.Value `tfsdk:"timeouts"`
 }
 
 func NewPricingResource() resource.Resource {
@@ -55,7 +60,7 @@ func (r *PricingResource) Metadata(_ context.Context, req resource.MetadataReque
 	resp.TypeName = req.ProviderTypeName + "_pricing"
 }
 
-func (r *PricingResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+func (r *PricingResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		Description: "Manages a Casdoor pricing configuration for SaaS products.",
 		Attributes: map[string]schema.Attribute{
@@ -66,13 +71,7 @@ func (r *PricingResource) Schema(_ context.Context, _ resource.SchemaRequest, re
 					stringplanmodifier.UseStateForUnknown(),
 				},
 			},
-			"owner": schema.StringAttribute{
-				Description: "The organization that owns this pricing.",
-				Required:    true,
-				PlanModifiers: []planmodifier.String{
-					stringplanmodifier.RequiresReplace(),
-				},
-			},
+			"owner": ownerAttribute("pricing"),
 			"name": schema.StringAttribute{
 				Description: "The unique name of the pricing.",
 				Required:    true,
@@ -150,6 +149,15 @@ func (r *PricingResource) Schema(_ context.Context, _ resource.SchemaRequest, re
 				Computed:    true,
 				Default:     stringdefault.StaticString(""),
 			},
+			"adopt_existing": adoptExistingAttribute("pricing"),
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Read:   true,
+				Update: true,
+				Delete: true,
+			}),
 		},
 	}
 }
@@ -159,16 +167,26 @@ func (r *PricingResource) Configure(_ context.Context, req resource.ConfigureReq
 		return
 	}
 
-	client, ok := req.ProviderData.(*casdoorsdk.Client)
+	data, ok := req.ProviderData.(*CasdoorProviderData)
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Resource Configure Type",
-			fmt.Sprintf("Expected *casdoorsdk.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected *CasdoorProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 		return
 	}
 
-	r.client = client
+	r.client = data.Client
+	r.providerData = data
+}
+
+func (r *PricingResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	planDefaultOwner(ctx, req, resp, r.providerData)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	planAdoption(ctx, req, resp, r.providerData, "pricing", r.Read)
 }
 
 func pricingPlanToSDK(ctx context.Context, plan PricingResourceModel, createdTime string) (*casdoorsdk.Pricing, diag.Diagnostics) {
@@ -195,6 +213,10 @@ func pricingPlanToSDK(ctx context.Context, plan PricingResourceModel, createdTim
 }
 
 func (r *PricingResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	if checkUnconfigured(r.client, &resp.Diagnostics) {
+		return
+	}
+
 	var plan PricingResourceModel
 
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
@@ -202,6 +224,12 @@ func (r *PricingResource) Create(ctx context.Context, req resource.CreateRequest
 		return
 	}
 
+	ctx, done := startOperation(ctx, plan.Timeouts.Create, defaultOperationTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	createdTime := plan.CreatedTime.ValueString()
 	if createdTime == "" {
 		createdTime = time.Now().UTC().Format(time.RFC3339)
@@ -213,13 +241,20 @@ func (r *PricingResource) Create(ctx context.Context, req resource.CreateRequest
 		return
 	}
 
-	ok, err := r.client.AddPricing(pricing)
-	if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("creating pricing %q", plan.Name.ValueString())) {
+	adopted := adoptOnCreate(ctx, r.providerData, req.Plan, req.Config, &resp.Diagnostics, "pricing", plan.Owner.ValueString()+"/"+plan.Name.ValueString(), pricing, r.client.Pricings())
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
+	if !adopted {
+		ok, err := r.client.Pricings().Add(ctx, pricing)
+		if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("creating pricing %q", plan.Name.ValueString())) {
+			return
+		}
+	}
+
 	// Read back the pricing to get server-generated values.
-	createdPricing, err := r.client.GetPricing(plan.Owner.ValueString() + "/" + plan.Name.ValueString())
+	createdPricing, err := r.client.Pricings().Get(ctx, plan.Owner.ValueString()+"/"+plan.Name.ValueString())
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Reading Pricing",
@@ -245,6 +280,10 @@ func (r *PricingResource) Create(ctx context.Context, req resource.CreateRequest
 }
 
 func (r *PricingResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	if r.client == nil {
+		return
+	}
+
 	var state PricingResourceModel
 
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
@@ -252,7 +291,13 @@ func (r *PricingResource) Read(ctx context.Context, req resource.ReadRequest, re
 		return
 	}
 
-	pricing, err := r.client.GetPricing(state.Owner.ValueString() + "/" + state.Name.ValueString())
+	ctx, done := startOperation(ctx, state.Timeouts.Read, defaultReadTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	pricing, err := r.client.Pricings().Get(ctx, state.Owner.ValueString()+"/"+state.Name.ValueString())
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Reading Pricing",
@@ -283,33 +328,54 @@ func (r *PricingResource) Read(ctx context.Context, req resource.ReadRequest, re
 	plansList, _ := types.ListValueFrom(ctx, types.StringType, pricing.Plans)
 	state.Plans = plansList
 
+	resp.Diagnostics.Append(setObjectFingerprint(ctx, resp.Private, pricing)...)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
 }
 
 func (r *PricingResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	var plan PricingResourceModel
+	if checkUnconfigured(r.client, &resp.Diagnostics) {
+		return
+	}
+
+	var plan, state PricingResourceModel
 
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, done := startOperation(ctx, plan.Timeouts.Update, defaultOperationTimeout, &resp.Diagnostics)
+	defer done()
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
+	if checkUpdateConflict(ctx, r.providerData, &resp.Diagnostics, req.Private, "pricing", state.Owner.ValueString()+"/"+state.Name.ValueString(), r.client.Pricings().Get) {
+		return
+	}
+
 	pricing, diags := pricingPlanToSDK(ctx, plan, plan.CreatedTime.ValueString())
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	ok, err := r.client.UpdatePricing(pricing)
+	ok, err := r.client.Pricings().Update(ctx, pricing)
 	if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("updating pricing %q", plan.Name.ValueString())) {
 		return
 	}
 
 	plan.ID = types.StringValue(plan.Owner.ValueString() + "/" + plan.Name.ValueString())
+	resp.Diagnostics.Append(clearObjectFingerprint(ctx, resp.Private)...)
 	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
 }
 
 func (r *PricingResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	if checkUnconfigured(r.client, &resp.Diagnostics) {
+		return
+	}
+
 	var state PricingResourceModel
 
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
@@ -317,12 +383,18 @@ func (r *PricingResource) Delete(ctx context.Context, req resource.DeleteRequest
 		return
 	}
 
+	ctx, done := startOperation(ctx, state.Timeouts.Delete, defaultOperationTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	pricing := &casdoorsdk.Pricing{
 		Owner: state.Owner.ValueString(),
 		Name:  state.Name.ValueString(),
 	}
 
-	ok, err := r.client.DeletePricing(pricing)
+	ok, err := r.client.Pricings().Delete(ctx, pricing)
 	if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("deleting pricing %q", state.Name.ValueString())) {
 		return
 	}
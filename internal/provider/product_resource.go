@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
@@ -24,33 +25,36 @@ var (
 	_ resource.Resource                = &ProductResource{}
 	_ resource.ResourceWithConfigure   = &ProductResource{}
 	_ resource.ResourceWithImportState = &ProductResource{}
+	_ resource.ResourceWithModifyPlan  = &ProductResource{}
 )
 
 type ProductResource struct {
-	client *casdoorsdk.Client
+	client       casdoorClient
+	providerData *CasdoorProviderData
 }
 
 type ProductResourceModel struct {
-	ID                    types.String  `tfsdk:"id"`
-	Owner                 types.String  `tfsdk:"owner"`
-	Name                  types.String  `tfsdk:"name"`
-	CreatedTime           types.String  `tfsdk:"created_time"`
-	DisplayName           types.String  `tfsdk:"display_name"`
-	Image                 types.String  `tfsdk:"image"`
-	Detail                types.String  `tfsdk:"detail"`
-	Description           types.String  `tfsdk:"description"`
-	Tag                   types.String  `tfsdk:"tag"`
-	Currency              types.String  `tfsdk:"currency"`
-	Price                 types.Float64 `tfsdk:"price"`
-	Quantity              types.Int64   `tfsdk:"quantity"`
-	Sold                  types.Int64   `tfsdk:"sold"`
-	IsRecharge            types.Bool    `tfsdk:"is_recharge"`
-	RechargeOptions       types.List    `tfsdk:"recharge_options"`
-	DisableCustomRecharge types.Bool    `tfsdk:"disable_custom_recharge"`
-	SuccessUrl            types.String  `tfsdk:"success_url"`
-	Providers             types.List    `tfsdk:"providers"`
-	State                 types.String  `tfsdk:"state"`
-	ManagedByPlan         types.Bool    `tfsdk:"managed_by_plan"`
+	ID                    types.String   `tfsdk:"id"`
+	Owner                 types.String   `tfsdk:"owner"`
+	Name                  types.String   `tfsdk:"name"`
+	CreatedTime           types.String   `tfsdk:"created_time"`
+	DisplayName           types.String   `tfsdk:"display_name"`
+	Image                 types.String   `tfsdk:"image"`
+	Detail                types.String   `tfsdk:"detail"`
+	Description           types.String   `tfsdk:"description"`
+	Tag                   types.String   `tfsdk:"tag"`
+	Currency              types.String   `tfsdk:"currency"`
+	Price                 types.Float64  `tfsdk:"price"`
+	Quantity              types.Int64    `tfsdk:"quantity"`
+	Sold                  types.Int64    `tfsdk:"sold"`
+	IsRecharge            types.Bool     `tfsdk:"is_recharge"`
+	RechargeOptions       types.List     `tfsdk:"recharge_options"`
+	DisableCustomRecharge types.Bool     `tfsdk:"disable_custom_recharge"`
+	SuccessUrl            types.String   `tfsdk:"success_url"`
+	Providers             types.List     `tfsdk:"providers"`
+	State                 types.String   `tfsdk:"state"`
+	ManagedByPlan         types.Bool     `tfsdk:"managed_by_plan"`
+	Timeouts              timeouts.Value `tfsdk:"timeouts"`
 }
 
 func NewProductResource() resource.Resource {
@@ -61,7 +65,7 @@ func (r *ProductResource) Metadata(_ context.Context, req resource.MetadataReque
 	resp.TypeName = req.ProviderTypeName + "_product"
 }
 
-func (r *ProductResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+func (r *ProductResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		Description: "Manages a Casdoor product for the SaaS product catalog.",
 		Attributes: map[string]schema.Attribute{
@@ -72,13 +76,7 @@ func (r *ProductResource) Schema(_ context.Context, _ resource.SchemaRequest, re
 					stringplanmodifier.UseStateForUnknown(),
 				},
 			},
-			"owner": schema.StringAttribute{
-				Description: "The organization that owns this product.",
-				Required:    true,
-				PlanModifiers: []planmodifier.String{
-					stringplanmodifier.RequiresReplace(),
-				},
-			},
+			"owner": ownerAttribute("product"),
 			"name": schema.StringAttribute{
 				Description: "The unique name of the product.",
 				Required:    true,
@@ -222,6 +220,14 @@ func (r *ProductResource) Schema(_ context.Context, _ resource.SchemaRequest, re
 				},
 			},
 		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Read:   true,
+				Update: true,
+				Delete: true,
+			}),
+		},
 	}
 }
 
@@ -230,16 +236,21 @@ func (r *ProductResource) Configure(_ context.Context, req resource.ConfigureReq
 		return
 	}
 
-	client, ok := req.ProviderData.(*casdoorsdk.Client)
+	data, ok := req.ProviderData.(*CasdoorProviderData)
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Resource Configure Type",
-			fmt.Sprintf("Expected *casdoorsdk.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected *CasdoorProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 		return
 	}
 
-	r.client = client
+	r.client = data.Client
+	r.providerData = data
+}
+
+func (r *ProductResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	planDefaultOwner(ctx, req, resp, r.providerData)
 }
 
 func productPlanToSDK(ctx context.Context, plan ProductResourceModel, createdTime string) (*casdoorsdk.Product, diag.Diagnostics) {
@@ -282,6 +293,10 @@ func productPlanToSDK(ctx context.Context, plan ProductResourceModel, createdTim
 }
 
 func (r *ProductResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	if checkUnconfigured(r.client, &resp.Diagnostics) {
+		return
+	}
+
 	var plan ProductResourceModel
 
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
@@ -289,10 +304,16 @@ func (r *ProductResource) Create(ctx context.Context, req resource.CreateRequest
 		return
 	}
 
+	ctx, done := startOperation(ctx, plan.Timeouts.Create, defaultOperationTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	productID := plan.Owner.ValueString() + "/" + plan.Name.ValueString()
 
 	// Check if the product already exists (e.g. auto-created by a casdoor_plan).
-	existing, err := r.client.GetProduct(productID)
+	existing, err := r.client.Products().Get(ctx, productID)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Reading Product",
@@ -364,7 +385,7 @@ func (r *ProductResource) Create(ctx context.Context, req resource.CreateRequest
 			}
 		}
 
-		ok, err := r.client.UpdateProduct(existing)
+		ok, err := r.client.Products().Update(ctx, existing)
 		if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("adopting product %q", plan.Name.ValueString())) {
 			return
 		}
@@ -383,14 +404,14 @@ func (r *ProductResource) Create(ctx context.Context, req resource.CreateRequest
 			return
 		}
 
-		ok, err := r.client.AddProduct(product)
+		ok, err := r.client.Products().Add(ctx, product)
 		if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("creating product %q", plan.Name.ValueString())) {
 			return
 		}
 	}
 
 	// Read back the product to get server-generated values.
-	createdProduct, err := r.client.GetProduct(productID)
+	createdProduct, err := r.client.Products().Get(ctx, productID)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Reading Product",
@@ -437,6 +458,10 @@ func (r *ProductResource) Create(ctx context.Context, req resource.CreateRequest
 }
 
 func (r *ProductResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	if r.client == nil {
+		return
+	}
+
 	var state ProductResourceModel
 
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
@@ -444,7 +469,13 @@ func (r *ProductResource) Read(ctx context.Context, req resource.ReadRequest, re
 		return
 	}
 
-	product, err := r.client.GetProduct(state.Owner.ValueString() + "/" + state.Name.ValueString())
+	ctx, done := startOperation(ctx, state.Timeouts.Read, defaultReadTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	product, err := r.client.Products().Get(ctx, state.Owner.ValueString()+"/"+state.Name.ValueString())
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Reading Product",
@@ -486,30 +517,46 @@ func (r *ProductResource) Read(ctx context.Context, req resource.ReadRequest, re
 		state.ManagedByPlan = types.BoolValue(false)
 	}
 
+	resp.Diagnostics.Append(setObjectFingerprint(ctx, resp.Private, product)...)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
 }
 
 func (r *ProductResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	var plan ProductResourceModel
+	if checkUnconfigured(r.client, &resp.Diagnostics) {
+		return
+	}
+
+	var plan, state ProductResourceModel
 
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, done := startOperation(ctx, plan.Timeouts.Update, defaultOperationTimeout, &resp.Diagnostics)
+	defer done()
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
+	if checkUpdateConflict(ctx, r.providerData, &resp.Diagnostics, req.Private, "product", state.Owner.ValueString()+"/"+state.Name.ValueString(), r.client.Products().Get) {
+		return
+	}
+
 	product, diags := productPlanToSDK(ctx, plan, plan.CreatedTime.ValueString())
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	ok, err := r.client.UpdateProduct(product)
+	ok, err := r.client.Products().Update(ctx, product)
 	if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("updating product %q", plan.Name.ValueString())) {
 		return
 	}
 
 	// Read back to get server-updated fields.
-	updatedProduct, err := r.client.GetProduct(plan.Owner.ValueString() + "/" + plan.Name.ValueString())
+	updatedProduct, err := r.client.Products().Get(ctx, plan.Owner.ValueString()+"/"+plan.Name.ValueString())
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Reading Product",
@@ -551,10 +598,15 @@ func (r *ProductResource) Update(ctx context.Context, req resource.UpdateRequest
 		plan.RechargeOptions = rechargeOptionsList
 	}
 
+	resp.Diagnostics.Append(clearObjectFingerprint(ctx, resp.Private)...)
 	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
 }
 
 func (r *ProductResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	if checkUnconfigured(r.client, &resp.Diagnostics) {
+		return
+	}
+
 	var state ProductResourceModel
 
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
@@ -562,6 +614,12 @@ func (r *ProductResource) Delete(ctx context.Context, req resource.DeleteRequest
 		return
 	}
 
+	ctx, done := startOperation(ctx, state.Timeouts.Delete, defaultOperationTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	// Plan-managed products are deleted by Casdoor when the plan is destroyed.
 	if state.ManagedByPlan.ValueBool() {
 		return
@@ -572,7 +630,7 @@ func (r *ProductResource) Delete(ctx context.Context, req resource.DeleteRequest
 		Name:  state.Name.ValueString(),
 	}
 
-	ok, err := r.client.DeleteProduct(product)
+	ok, err := r.client.Products().Delete(ctx, product)
 	if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("deleting product %q", state.Name.ValueString())) {
 		return
 	}
@@ -8,11 +8,13 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/cookiejar"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
@@ -33,8 +35,24 @@ type CasdoorProviderModel struct {
 	OrganizationName types.String `tfsdk:"organization_name"`
 	ApplicationName  types.String `tfsdk:"application_name"`
 	// Alternative auth: admin login.
-	Username types.String `tfsdk:"username"`
-	Password types.String `tfsdk:"password"`
+	Username   types.String `tfsdk:"username"`
+	Password   types.String `tfsdk:"password"`
+	TOTPSecret types.String `tfsdk:"totp_secret"`
+	TOTPCode   types.String `tfsdk:"totp_code"`
+	LoginMode  types.String `tfsdk:"login_mode"`
+	// Alternative auth: user access key.
+	AccessKey    types.String `tfsdk:"access_key"`
+	AccessSecret types.String `tfsdk:"access_secret"`
+	// High availability.
+	FailoverEndpoints      types.List `tfsdk:"failover_endpoints"`
+	WriteFallbackEndpoints types.List `tfsdk:"write_fallback_endpoints"`
+	// Behaviour.
+	IgnoreUpdateConflicts types.Bool   `tfsdk:"ignore_update_conflicts"`
+	AdoptExisting         types.Bool   `tfsdk:"adopt_existing"`
+	BuiltInProtection     types.String `tfsdk:"built_in_protection"`
+	DefaultOwner          types.String `tfsdk:"default_owner"`
+	WaitForReady          types.String `tfsdk:"wait_for_ready"`
+	ReadOnly              types.Bool   `tfsdk:"read_only"`
 }
 
 func New(version string) func() provider.Provider {
@@ -56,7 +74,7 @@ func (p *CasdoorProvider) Schema(_ context.Context, _ provider.SchemaRequest, re
 
 ## Authentication
 
-The provider supports two authentication methods:
+The provider supports three authentication methods:
 
 ### 1. OAuth Application Credentials (recommended for production)
 
@@ -65,12 +83,14 @@ provider "casdoor" {
   endpoint          = "https://casdoor.example.com"
   client_id         = "your-client-id"
   client_secret     = "your-client-secret"
-  certificate       = file("path/to/cert.pem")
   organization_name = "built-in"
   application_name  = "app-built-in"
 }
 ` + "```" + `
 
+The keys Casdoor signs JWTs with are discovered from its JSON Web Key Set (` + "`/.well-known/jwks`" + `), so certificate
+rotations need no configuration change. Set ` + "`certificate`" + ` to pin the key instead.
+
 ### 2. Admin Username/Password (convenient for development)
 
 ` + "```hcl" + `
@@ -84,6 +104,22 @@ provider "casdoor" {
 ` + "```" + `
 
 When using username/password, the provider will login and automatically fetch the application's OAuth credentials.
+If the admin has TOTP MFA enabled, set ` + "`totp_secret`" + ` to let the provider generate the current code, or pass the code itself in ` + "`totp_code`" + `.
+Set ` + "`login_mode = \"session\"`" + ` to make all calls with the login session instead, so that the application's client secret is never read.
+
+### 3. User Access Key (for machine-to-machine use)
+
+` + "```hcl" + `
+provider "casdoor" {
+  endpoint          = "https://casdoor.example.com"
+  organization_name = "built-in"
+  access_key        = var.casdoor_access_key
+  access_secret     = var.casdoor_access_secret
+}
+` + "```" + `
+
+The provider authenticates as the user owning the access key, such as a service account managed with
+` + "`casdoor_user`" + `, so each pipeline can have its own credentials. Neither a certificate nor ` + "`application_name`" + ` is needed.
 
 ## Obtaining OAuth Credentials
 
@@ -97,32 +133,84 @@ To use known credentials from day one, use Casdoor's
 4. Use the same values in your provider configuration
 
 See the [Casdoor Data Initialization docs](https://casdoor.org/docs/deployment/data-initialization/) for the full reference.
+
+## Deploying Casdoor in the Same Configuration
+
+If the provider configuration depends on resources created in the same run, such as the Casdoor deployment itself,
+Terraform versions that support deferred actions (` + "`-allow-deferral`" + `) defer the Casdoor resources until the
+configuration is known. Other versions plan them without refreshing them from Casdoor, and apply them once the
+configuration is known. Set ` + "`wait_for_ready`" + ` to let a freshly started Casdoor become healthy before the provider uses it:
+
+` + "```hcl" + `
+provider "casdoor" {
+  endpoint          = "https://${helm_release.casdoor.name}.example.com"
+  organization_name = "built-in"
+  application_name  = "app-built-in"
+  username          = "admin"
+  password          = var.casdoor_admin_password
+  wait_for_ready    = "5m"
+}
+` + "```" + `
+## High Availability
+
+If Casdoor runs in several regions behind separate hostnames, list the other endpoints in ` + "`failover_endpoints`" + `.
+Reads go to the first healthy endpoint and fail over to the next one when an endpoint cannot be reached or answers
+with a server error; an endpoint that failed is skipped until its health check succeeds again. Writes are pinned to
+` + "`endpoint`" + ` so that they are never applied twice, unless ` + "`write_fallback_endpoints`" + ` lists where, in order,
+they may go when ` + "`endpoint`" + ` cannot be reached. Errors name the endpoint that served the failing request.
+
+` + "```hcl" + `
+provider "casdoor" {
+  endpoint                 = "https://eu.casdoor.example.com"
+  failover_endpoints       = ["https://us.casdoor.example.com", "https://ap.casdoor.example.com"]
+  write_fallback_endpoints = ["https://us.casdoor.example.com"]
+  client_id                = "your-client-id"
+  client_secret            = "your-client-secret"
+  organization_name        = "built-in"
+  application_name         = "app-built-in"
+}
+` + "```" + `
 `,
 		Attributes: map[string]schema.Attribute{
 			"endpoint": schema.StringAttribute{
 				Description: "The Casdoor server endpoint URL (e.g., https://casdoor.example.com).",
 				Required:    true,
 			},
+			"failover_endpoints": schema.ListAttribute{
+				Description: "Further endpoints of the same Casdoor deployment, e.g. in other regions. Reads go to the " +
+					"first healthy endpoint, starting with endpoint, and fail over to the next one, in this order, " +
+					"when an endpoint cannot be reached or answers with a server error. Cannot be used with login_mode 'session'.",
+				ElementType: types.StringType,
+				Optional:    true,
+			},
+			"write_fallback_endpoints": schema.ListAttribute{
+				Description: "Writes are pinned to endpoint. If set, they fall back to these endpoints, in this order, " +
+					"when endpoint cannot be reached. Each must be one of failover_endpoints. By default writes do not fail over.",
+				ElementType: types.StringType,
+				Optional:    true,
+			},
 			"client_id": schema.StringAttribute{
-				Description: "The OAuth2 client ID for the Casdoor application. Required if username is not set.",
+				Description: "The OAuth2 client ID for the Casdoor application. Required if neither username nor access_key is set.",
 				Optional:    true,
 			},
 			"client_secret": schema.StringAttribute{
-				Description: "The OAuth2 client secret for the Casdoor application. Required if username is not set.",
+				Description: "The OAuth2 client secret for the Casdoor application. Required if neither username nor access_key is set.",
 				Optional:    true,
 				Sensitive:   true,
 			},
 			"certificate": schema.StringAttribute{
-				Description: "The X.509 certificate (public key) for JWT verification. Required if username is not set.",
-				Optional:    true,
+				Description: "The X.509 certificate (public key) for JWT verification. If not set, the signing keys are " +
+					"discovered from Casdoor's JSON Web Key Set, or, with username, read from the application's cert.",
+				Optional: true,
 			},
 			"organization_name": schema.StringAttribute{
 				Description: "The organization name in Casdoor.",
 				Required:    true,
 			},
 			"application_name": schema.StringAttribute{
-				Description: "The application name in Casdoor.",
-				Required:    true,
+				Description: "The application name in Casdoor, or 'owner/name' for an application not owned by admin. " +
+					"Required unless access_key is set.",
+				Optional: true,
 			},
 			"username": schema.StringAttribute{
 				Description: "Admin username for authentication. If set, the provider will login and fetch OAuth credentials automatically.",
@@ -133,6 +221,72 @@ See the [Casdoor Data Initialization docs](https://casdoor.org/docs/deployment/d
 				Optional:    true,
 				Sensitive:   true,
 			},
+			"login_mode": schema.StringAttribute{
+				Description: "How the provider uses the username/password login. 'credentials' (default) reads the " +
+					"application's client ID and secret, and its certificate unless certificate is set, and uses them " +
+					"for all calls. 'session' makes all calls with the login session instead, logging in again when " +
+					"it expires, so the application's secret is never read.",
+				Optional: true,
+			},
+			"totp_secret": schema.StringAttribute{
+				Description: "Base32-encoded TOTP secret of the admin user, for admins with MFA enabled. " +
+					"The provider generates the current code from it to complete the login. Conflicts with totp_code.",
+				Optional:  true,
+				Sensitive: true,
+			},
+			"totp_code": schema.StringAttribute{
+				Description: "Current TOTP code of the admin user, for admins with MFA enabled, e.g. passed in " +
+					"with -var from an authenticator app. The code cannot be used to log in again once it has expired, " +
+					"so a session that expires during the run needs totp_secret. Conflicts with totp_secret.",
+				Optional:  true,
+				Sensitive: true,
+			},
+			"access_key": schema.StringAttribute{
+				Description: "Access key of a Casdoor user to authenticate as, e.g. a service account. " +
+					"If set, neither application credentials, certificate nor application_name are needed.",
+				Optional: true,
+			},
+			"access_secret": schema.StringAttribute{
+				Description: "Access secret of the user owning access_key. Required if access_key is set.",
+				Optional:    true,
+				Sensitive:   true,
+			},
+			"ignore_update_conflicts": schema.BoolAttribute{
+				Description: "Update objects even if they were changed in Casdoor after Terraform last read them. " +
+					"By default such updates fail so that changes made outside Terraform are not silently overwritten.",
+				Optional: true,
+			},
+			"adopt_existing": schema.BoolAttribute{
+				Description: "Default for the adopt_existing attribute of resources. When true, creating an object that " +
+					"already exists in Casdoor, such as the application, cert and admin user Casdoor creates for a new " +
+					"organization, adopts it instead of failing.",
+				Optional: true,
+			},
+			"built_in_protection": schema.StringAttribute{
+				Description: "Guards Casdoor's built-in objects (the 'built-in' organization, 'app-built-in', " +
+					"'cert-built-in' and the 'built-in/admin' user), which Casdoor cannot work without. " +
+					"'delete' (default) refuses to delete them, 'all' also refuses to modify them and 'none' allows both.",
+				Optional: true,
+			},
+			"default_owner": schema.StringAttribute{
+				Description: "Organization that owns the objects of resources that leave owner out, applied at plan time " +
+					"so that their IDs are known. Changing it replaces those objects. Organizations, applications, tokens " +
+					"and LDAP configurations are owned by 'admin' unless their owner is set.",
+				Optional: true,
+			},
+			"wait_for_ready": schema.StringAttribute{
+				Description: "How long to wait for Casdoor to become healthy before making any other call, as a " +
+					"duration such as '5m'. The provider polls the /api/health endpoint of endpoint and of every " +
+					"failover endpoint until one of them succeeds or the time runs out. Useful when Casdoor is " +
+					"deployed in the same configuration. By default the provider does not wait.",
+				Optional: true,
+			},
+			"read_only": schema.BoolAttribute{
+				Description: "Refuse every call that could change Casdoor, e.g. for drift detection with 'terraform plan'. " +
+					"Creating, updating or deleting a resource fails before anything is changed, while reads, imports " +
+					"and data sources keep working.",
+				Optional: true,
+			},
 		},
 	}
 }
@@ -145,12 +299,123 @@ func (p *CasdoorProvider) Configure(ctx context.Context, req provider.ConfigureR
 		return
 	}
 
+	// The configuration can depend on resources that do not exist yet, such
+	// as the Casdoor deployment itself. Defer the resources of this provider
+	// until it is known, if Terraform allows it. Otherwise they are planned
+	// unconfigured, which only fails if Casdoor has to be called; by apply
+	// the configuration is known.
+	if !req.Config.Raw.IsFullyKnown() {
+		if req.ClientCapabilities.DeferralAllowed {
+			resp.Deferred = &provider.Deferred{Reason: provider.DeferredReasonProviderConfigUnknown}
+		}
+		return
+	}
+
+	builtInProtection := builtInProtectionDelete
+	if !config.BuiltInProtection.IsNull() {
+		builtInProtection = config.BuiltInProtection.ValueString()
+	}
+	switch builtInProtection {
+	case builtInProtectionDelete, builtInProtectionAll, builtInProtectionNone:
+	default:
+		resp.Diagnostics.AddAttributeError(
+			path.Root("built_in_protection"),
+			"Invalid Built-in Protection",
+			fmt.Sprintf("Expected %q, %q or %q, got: %q", builtInProtectionDelete, builtInProtectionAll, builtInProtectionNone, builtInProtection),
+		)
+		return
+	}
+
+	loginMode := loginModeCredentials
+	if !config.LoginMode.IsNull() {
+		loginMode = config.LoginMode.ValueString()
+	}
+	if loginMode != loginModeCredentials && loginMode != loginModeSession {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("login_mode"),
+			"Invalid Login Mode",
+			fmt.Sprintf("Expected %q or %q, got: %q", loginModeCredentials, loginModeSession, loginMode),
+		)
+		return
+	}
+
+	var failoverEndpoints, writeFallbackEndpoints []string
+	resp.Diagnostics.Append(config.FailoverEndpoints.ElementsAs(ctx, &failoverEndpoints, false)...)
+	resp.Diagnostics.Append(config.WriteFallbackEndpoints.ElementsAs(ctx, &writeFallbackEndpoints, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	for _, endpoint := range writeFallbackEndpoints {
+		if !slices.Contains(failoverEndpoints, endpoint) {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("write_fallback_endpoints"),
+				"Invalid Write Fallback Endpoint",
+				fmt.Sprintf("%q is not one of failover_endpoints.", endpoint),
+			)
+			return
+		}
+	}
+	if len(failoverEndpoints) > 0 && loginMode == loginModeSession {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("failover_endpoints"),
+			"Conflicting Login Mode",
+			"failover_endpoints cannot be used with login_mode \"session\", as a login session is bound to the server it was created on.",
+		)
+		return
+	}
+
+	if !config.WaitForReady.IsNull() {
+		timeout, err := time.ParseDuration(config.WaitForReady.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("wait_for_ready"),
+				"Invalid Wait For Ready",
+				fmt.Sprintf("Expected a duration such as \"5m\", got: %q", config.WaitForReady.ValueString()),
+			)
+			return
+		}
+
+		if err := waitForReady(ctx, append([]string{config.Endpoint.ValueString()}, failoverEndpoints...), timeout); err != nil {
+			resp.Diagnostics.AddError(
+				"Casdoor Not Ready",
+				fmt.Sprintf("Failed to wait for Casdoor to become ready: %s", err),
+			)
+			return
+		}
+	}
+
+	var pool *endpointPool
+	loginEndpoint := config.Endpoint.ValueString()
+	if len(failoverEndpoints) > 0 {
+		pool = newEndpointPool(config.Endpoint.ValueString(), failoverEndpoints, writeFallbackEndpoints)
+		loginEndpoint = pool.healthy(ctx)
+	}
+
 	var clientID, clientSecret, certificate string
+	var session *loginSession
 
 	// Determine authentication method.
 	useLoginAuth := !config.Username.IsNull() && config.Username.ValueString() != ""
+	useAccessKeyAuth := !config.AccessKey.IsNull() && config.AccessKey.ValueString() != ""
+
+	if useAccessKeyAuth && (useLoginAuth || (!config.ClientID.IsNull() && config.ClientID.ValueString() != "")) {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("access_key"),
+			"Conflicting Authentication Methods",
+			"access_key cannot be combined with username or client_id.",
+		)
+		return
+	}
+	if !useAccessKeyAuth && config.ApplicationName.ValueString() == "" {
+		resp.Diagnostics.AddError(
+			"Missing Application Name",
+			"application_name is required unless access_key is used for authentication.",
+		)
+		return
+	}
 
-	if useLoginAuth {
+	switch {
+	case useLoginAuth:
 		// Validate password is provided.
 		if config.Password.IsNull() || config.Password.ValueString() == "" {
 			resp.Diagnostics.AddError(
@@ -160,14 +425,47 @@ func (p *CasdoorProvider) Configure(ctx context.Context, req provider.ConfigureR
 			return
 		}
 
+		var passcode func() (string, error)
+		switch {
+		case config.TOTPSecret.ValueString() != "" && config.TOTPCode.ValueString() != "":
+			resp.Diagnostics.AddAttributeError(
+				path.Root("totp_code"),
+				"Conflicting TOTP Settings",
+				"Only one of totp_secret and totp_code can be set.",
+			)
+			return
+		case config.TOTPSecret.ValueString() != "":
+			passcode = func() (string, error) { return totpCode(config.TOTPSecret.ValueString(), time.Now()) }
+		case config.TOTPCode.ValueString() != "":
+			passcode = func() (string, error) { return config.TOTPCode.ValueString(), nil }
+		}
+
+		login := &passwordLogin{
+			endpoint:     loginEndpoint,
+			organization: config.OrganizationName.ValueString(),
+			application:  config.ApplicationName.ValueString(),
+			username:     config.Username.ValueString(),
+			password:     config.Password.ValueString(),
+			passcode:     passcode,
+		}
+
+		if loginMode == loginModeSession {
+			// Keep the session for all calls; the application's secret and
+			// certificate are not needed.
+			var err error
+			session, err = newLoginSession(ctx, login)
+			if err != nil {
+				resp.Diagnostics.AddError(
+					"Authentication Failed",
+					fmt.Sprintf("Failed to authenticate with Casdoor: %s", err),
+				)
+				return
+			}
+			break
+		}
+
 		// Login and fetch credentials.
-		creds, err := fetchCredentialsViaLogin(
-			config.Endpoint.ValueString(),
-			config.OrganizationName.ValueString(),
-			config.ApplicationName.ValueString(),
-			config.Username.ValueString(),
-			config.Password.ValueString(),
-		)
+		creds, err := fetchCredentialsViaLogin(ctx, login, config.Certificate.ValueString())
 		if err != nil {
 			resp.Diagnostics.AddError(
 				"Authentication Failed",
@@ -179,12 +477,22 @@ func (p *CasdoorProvider) Configure(ctx context.Context, req provider.ConfigureR
 		clientID = creds.ClientID
 		clientSecret = creds.ClientSecret
 		certificate = creds.Certificate
-	} else {
+	case useAccessKeyAuth:
+		// Requests are authenticated as the user owning the access key, which
+		// needs neither application credentials nor the certificate.
+		if config.AccessSecret.IsNull() || config.AccessSecret.ValueString() == "" {
+			resp.Diagnostics.AddError(
+				"Missing Access Secret",
+				"access_secret is required when using access key authentication.",
+			)
+			return
+		}
+	default:
 		// Use OAuth credentials directly.
 		if config.ClientID.IsNull() || config.ClientID.ValueString() == "" {
 			resp.Diagnostics.AddError(
 				"Missing Client ID",
-				"Either client_id or username must be provided for authentication.",
+				"Either client_id, username or access_key must be provided for authentication.",
 			)
 			return
 		}
@@ -195,14 +503,8 @@ func (p *CasdoorProvider) Configure(ctx context.Context, req provider.ConfigureR
 			)
 			return
 		}
-		if config.Certificate.IsNull() || config.Certificate.ValueString() == "" {
-			resp.Diagnostics.AddError(
-				"Missing Certificate",
-				"certificate is required when using OAuth authentication.",
-			)
-			return
-		}
-
+		// Without a certificate, JWTs are verified with the keys discovered
+		// from Casdoor's JSON Web Key Set.
 		clientID = config.ClientID.ValueString()
 		clientSecret = config.ClientSecret.ValueString()
 		certificate = config.Certificate.ValueString()
@@ -216,9 +518,29 @@ func (p *CasdoorProvider) Configure(ctx context.Context, req provider.ConfigureR
 		config.OrganizationName.ValueString(),
 		config.ApplicationName.ValueString(),
 	)
+	if useAccessKeyAuth {
+		withAccessKey(client, config.AccessKey.ValueString(), config.AccessSecret.ValueString())
+	}
+	if session != nil {
+		sdkHTTPClient.withSession(client, session)
+	}
+	if pool != nil {
+		sdkHTTPClient.withEndpoints(client, pool)
+	}
+	if config.ReadOnly.ValueBool() {
+		withReadOnly(client)
+	}
 
-	resp.DataSourceData = client
-	resp.ResourceData = client
+	data := &CasdoorProviderData{
+		Client:                newSDKClient(client),
+		IgnoreUpdateConflicts: config.IgnoreUpdateConflicts.ValueBool(),
+		AdoptExisting:         config.AdoptExisting.ValueBool(),
+		BuiltInProtection:     builtInProtection,
+		DefaultOwner:          config.DefaultOwner.ValueString(),
+	}
+
+	resp.DataSourceData = data
+	resp.ResourceData = data
 }
 
 // appCredentials holds the OAuth credentials fetched via login.
@@ -228,8 +550,81 @@ type appCredentials struct {
 	Certificate  string
 }
 
+// mfaRequired is what Casdoor answers a login with, instead of logging the
+// user in, when the user has multi-factor authentication enabled.
+const mfaRequired = "NextMfa"
+
+// passwordLogin holds what the provider logs in to Casdoor with when it
+// authenticates with username/password.
+type passwordLogin struct {
+	endpoint     string
+	organization string
+	// application is the application name, or "owner/name" for an
+	// application not owned by admin.
+	application string
+	username    string
+	password    string
+	// passcode returns the current TOTP code if the user has MFA enabled. It
+	// is nil if none is configured.
+	passcode func() (string, error)
+}
+
+// applicationID returns the ID of the application logged in to.
+func (l *passwordLogin) applicationID() string {
+	if strings.Contains(l.application, "/") {
+		return l.application
+	}
+	return "admin/" + l.application
+}
+
+// login logs in with client, whose cookie jar then holds the session,
+// completing the MFA challenge if the user has MFA enabled.
+func (l *passwordLogin) login(ctx context.Context, client *http.Client) error {
+	_, application, _ := strings.Cut(l.applicationID(), "/")
+	loginPayload := map[string]string{
+		"application":  application,
+		"organization": l.organization,
+		"username":     l.username,
+		"password":     l.password,
+		"type":         "login",
+	}
+
+	loginData, err := postLogin(ctx, client, l.endpoint, loginPayload)
+	if err != nil {
+		return err
+	}
+	if loginData != mfaRequired {
+		return nil
+	}
+
+	if l.passcode == nil {
+		return fmt.Errorf("user %s/%s has MFA enabled; set totp_secret or totp_code", l.organization, l.username)
+	}
+
+	code, err := l.passcode()
+	if err != nil {
+		return err
+	}
+
+	// The first response set the session cookie that ties the MFA step to
+	// this login.
+	loginPayload["passcode"] = code
+	loginPayload["mfaType"] = "app"
+	loginData, err = postLogin(ctx, client, l.endpoint, loginPayload)
+	if err != nil {
+		return fmt.Errorf("MFA verification failed: %w", err)
+	}
+	if loginData == mfaRequired {
+		return fmt.Errorf("MFA verification failed: Casdoor asked for another factor")
+	}
+
+	return nil
+}
+
 // fetchCredentialsViaLogin authenticates with username/password and fetches application credentials.
-func fetchCredentialsViaLogin(endpoint, organization, application, username, password string) (*appCredentials, error) {
+// The certificate is read from the cert the application uses, unless one is
+// given.
+func fetchCredentialsViaLogin(ctx context.Context, login *passwordLogin, certificate string) (*appCredentials, error) {
 	jar, err := cookiejar.New(nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create cookie jar: %w", err)
@@ -240,38 +635,17 @@ func fetchCredentialsViaLogin(endpoint, organization, application, username, pas
 	}
 
 	// Step 1: Login.
-	loginPayload := map[string]string{
-		"application":  application,
-		"organization": organization,
-		"username":     username,
-		"password":     password,
-		"type":         "login",
+	if err := login.login(ctx, client); err != nil {
+		return nil, err
 	}
-	loginBody, _ := json.Marshal(loginPayload)
 
-	loginResp, err := client.Post(
-		endpoint+"/api/login",
-		"application/json",
-		strings.NewReader(string(loginBody)),
-	)
+	// Step 2: Get application details.
+	appReq, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/api/get-application?id=%s", login.endpoint, login.applicationID()), nil)
 	if err != nil {
-		return nil, fmt.Errorf("login request failed: %w", err)
+		return nil, fmt.Errorf("failed to create get application request: %w", err)
 	}
-	defer func() { _ = loginResp.Body.Close() }()
 
-	var loginResult struct {
-		Status string `json:"status"`
-		Msg    string `json:"msg"`
-	}
-	if err := json.NewDecoder(loginResp.Body).Decode(&loginResult); err != nil {
-		return nil, fmt.Errorf("failed to decode login response: %w", err)
-	}
-	if loginResult.Status != "ok" {
-		return nil, fmt.Errorf("login failed: %s", loginResult.Msg)
-	}
-
-	// Step 2: Get application details.
-	appResp, err := client.Get(fmt.Sprintf("%s/api/get-application?id=admin/%s", endpoint, application))
+	appResp, err := client.Do(appReq)
 	if err != nil {
 		return nil, fmt.Errorf("get application request failed: %w", err)
 	}
@@ -280,7 +654,8 @@ func fetchCredentialsViaLogin(endpoint, organization, application, username, pas
 	var appResult struct {
 		Status string `json:"status"`
 		Msg    string `json:"msg"`
-		Data   struct {
+		Data   *struct {
+			Organization string `json:"organization"`
 			ClientID     string `json:"clientId"`
 			ClientSecret string `json:"clientSecret"`
 			Cert         string `json:"cert"`
@@ -289,59 +664,109 @@ func fetchCredentialsViaLogin(endpoint, organization, application, username, pas
 	if err := json.NewDecoder(appResp.Body).Decode(&appResult); err != nil {
 		return nil, fmt.Errorf("failed to decode application response: %w", err)
 	}
+	if appResult.Status != "ok" {
+		return nil, fmt.Errorf("get application failed: %s", appResult.Msg)
+	}
+	if appResult.Data == nil {
+		return nil, fmt.Errorf("application %s not found", login.applicationID())
+	}
+
+	if certificate == "" {
+		// Step 3: Get certificate. Casdoor looks the cert up in the
+		// application's organization first and then among the global certs.
+		if appResult.Data.Cert == "" {
+			return nil, fmt.Errorf("application %s has no cert; set certificate", login.applicationID())
+		}
+
+		certReq, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/api/get-cert?id=%s/%s", login.endpoint, appResult.Data.Organization, appResult.Data.Cert), nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create get cert request: %w", err)
+		}
+
+		certResp, err := client.Do(certReq)
+		if err != nil {
+			return nil, fmt.Errorf("get cert request failed: %w", err)
+		}
+		defer func() { _ = certResp.Body.Close() }()
+
+		var certResult struct {
+			Status string `json:"status"`
+			Msg    string `json:"msg"`
+			Data   struct {
+				Certificate string `json:"certificate"`
+			} `json:"data"`
+		}
+		if err := json.NewDecoder(certResp.Body).Decode(&certResult); err != nil {
+			return nil, fmt.Errorf("failed to decode cert response: %w", err)
+		}
+
+		certificate = certResult.Data.Certificate
+		if certificate == "" {
+			return nil, fmt.Errorf("certificate %s not found for application %s", appResult.Data.Cert, login.applicationID())
+		}
+	}
+
+	return &appCredentials{
+		ClientID:     appResult.Data.ClientID,
+		ClientSecret: appResult.Data.ClientSecret,
+		Certificate:  certificate,
+	}, nil
+}
 
-	// Step 3: Get certificate.
-	certName := appResult.Data.Cert
-	if certName == "" {
-		certName = "cert-built-in"
+// postLogin posts payload to /api/login and returns the data of Casdoor's
+// response, which is mfaRequired if the login needs another factor.
+func postLogin(ctx context.Context, client *http.Client, endpoint string, payload map[string]string) (string, error) {
+	loginBody, _ := json.Marshal(payload)
+
+	loginReq, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint+"/api/login", strings.NewReader(string(loginBody)))
+	if err != nil {
+		return "", fmt.Errorf("failed to create login request: %w", err)
 	}
+	loginReq.Header.Set("Content-Type", "application/json")
 
-	certResp, err := client.Get(fmt.Sprintf("%s/api/get-cert?id=admin/%s", endpoint, certName))
+	loginResp, err := client.Do(loginReq)
 	if err != nil {
-		return nil, fmt.Errorf("get cert request failed: %w", err)
+		return "", fmt.Errorf("login request failed: %w", err)
 	}
-	defer func() { _ = certResp.Body.Close() }()
+	defer func() { _ = loginResp.Body.Close() }()
 
-	var certResult struct {
+	var loginResult struct {
 		Status string `json:"status"`
 		Msg    string `json:"msg"`
-		Data   struct {
-			Certificate string `json:"certificate"`
-		} `json:"data"`
+		Data   any    `json:"data"`
 	}
-	if err := json.NewDecoder(certResp.Body).Decode(&certResult); err != nil {
-		return nil, fmt.Errorf("failed to decode cert response: %w", err)
+	if err := json.NewDecoder(loginResp.Body).Decode(&loginResult); err != nil {
+		return "", fmt.Errorf("failed to decode login response: %w", err)
 	}
-
-	certificate := certResult.Data.Certificate
-	if certificate == "" {
-		return nil, fmt.Errorf("certificate not found for application %s", application)
+	if loginResult.Status != "ok" {
+		return "", fmt.Errorf("login failed: %s", loginResult.Msg)
 	}
 
-	return &appCredentials{
-		ClientID:     appResult.Data.ClientID,
-		ClientSecret: appResult.Data.ClientSecret,
-		Certificate:  certificate,
-	}, nil
+	data, _ := loginResult.Data.(string)
+	return data, nil
 }
 
 func (p *CasdoorProvider) Resources(_ context.Context) []func() resource.Resource {
 	return []func() resource.Resource{
 		NewAdapterResource,
 		NewApplicationResource,
+		NewBalanceAdjustmentResource,
 		NewCertResource,
 		NewEnforcerResource,
 		NewGroupResource,
 		NewIdpResource,
+		NewInvitationResource,
 		NewLdapResource,
 		NewModelResource,
 		NewOrganizationResource,
 		NewPermissionResource,
+		NewPermissionApprovalResource,
 		NewPlanResource,
 		NewPricingResource,
 		NewProductResource,
 		NewResourceResource,
 		NewRoleResource,
+		NewSubscriptionResource,
 		NewSyncerResource,
 		NewTokenResource,
 		NewUserResource,
@@ -350,5 +775,10 @@ func (p *CasdoorProvider) Resources(_ context.Context) []func() resource.Resourc
 }
 
 func (p *CasdoorProvider) DataSources(_ context.Context) []func() datasource.DataSource {
-	return []func() datasource.DataSource{}
+	return []func() datasource.DataSource{
+		NewGroupTreeDataSource,
+		NewOrdersDataSource,
+		NewPaymentsDataSource,
+		NewTransactionsDataSource,
+	}
 }
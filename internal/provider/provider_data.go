@@ -0,0 +1,60 @@
+// Copyright (c) HashiCorp, Inc.
+
+package provider
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+// CasdoorProviderData is handed to resources and data sources through
+// ProviderData. It carries the API client together with provider-wide
+// settings that change how resources behave.
+type CasdoorProviderData struct {
+	Client casdoorClient
+
+	// IgnoreUpdateConflicts turns off the check that refuses to update an
+	// object that was changed in Casdoor after Terraform last read it.
+	IgnoreUpdateConflicts bool
+
+	// AdoptExisting makes resources that do not set adopt_existing take over
+	// an existing object with the same identity instead of failing to create
+	// it.
+	AdoptExisting bool
+
+	// BuiltInProtection is one of the builtInProtection* constants and says
+	// whether Casdoor's built-in objects may be deleted or modified.
+	BuiltInProtection string
+
+	// DefaultOwner is the owner of objects whose resource leaves owner out
+	// of configuration.
+	DefaultOwner string
+}
+
+// checkUnconfigured records an error and returns true if client cannot be
+// used to change Casdoor: either it is nil because the provider was left
+// unconfigured, as its configuration depended on values that were not known
+// yet, or the provider is read-only. Create, Update and Delete call it before
+// anything else, so that they fail before making any call. Reads of
+// resources do not call it; they keep the prior state instead.
+func checkUnconfigured(client casdoorClient, diags *diag.Diagnostics) bool {
+	switch {
+	case client == nil:
+		diags.AddError(
+			"Unknown Provider Configuration",
+			"The provider configuration depends on values that are not known yet, such as the endpoint of a "+
+				"Casdoor server created in the same configuration, so Casdoor cannot be called. Apply the "+
+				"resources it depends on first, or use a Terraform version that supports deferred actions "+
+				"with the -allow-deferral option.",
+		)
+		return true
+	case client.ReadOnly():
+		diags.AddError(
+			"Read-Only Provider",
+			"The provider is configured with read_only = true, so resources cannot be created, updated or "+
+				"deleted. Use it to detect drift with 'terraform plan', and apply changes with read_only unset.",
+		)
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,34 @@
+// Copyright (c) HashiCorp, Inc.
+
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+func TestCheckUnconfigured(t *testing.T) {
+	tests := []struct {
+		name        string
+		client      casdoorClient
+		wantSummary string
+	}{
+		{name: "configured", client: newFakeClient()},
+		{name: "unconfigured", wantSummary: "Unknown Provider Configuration"},
+		{name: "read-only", client: &fakeClient{readOnly: true}, wantSummary: "Read-Only Provider"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var diags diag.Diagnostics
+			got := checkUnconfigured(tt.client, &diags)
+			if got != (tt.wantSummary != "") || got != diags.HasError() {
+				t.Fatalf("Expected the client to be refused: %v, got %v (%v)", tt.wantSummary != "", got, diags)
+			}
+			if got && diags.Errors()[0].Summary() != tt.wantSummary {
+				t.Errorf("Expected error %q, got %q", tt.wantSummary, diags.Errors()[0].Summary())
+			}
+		})
+	}
+}
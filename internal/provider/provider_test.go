@@ -0,0 +1,587 @@
+// Copyright (c) HashiCorp, Inc.
+
+package provider
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+)
+
+func TestAccProvider_waitForReady(t *testing.T) {
+	config := setupTestConfig(t)
+	rName := "tf-test-" + acctest.RandStringFromCharSet(8, acctest.CharSetAlphaNum)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories(config),
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccProviderWaitForReadyConfig(config, "soon") + testAccRoleResourceConfig(config.OrganizationName, rName, "Test Role"),
+				ExpectError: regexp.MustCompile("Invalid Wait For Ready"),
+			},
+			{
+				Config: testAccProviderWaitForReadyConfig(config, "1m") + testAccRoleResourceConfig(config.OrganizationName, rName, "Test Role"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("casdoor_role.test", "name", rName),
+				),
+			},
+		},
+	})
+}
+
+func TestAccProvider_accessKey(t *testing.T) {
+	config := setupTestConfig(t)
+	rName := "tf-test-" + acctest.RandStringFromCharSet(8, acctest.CharSetAlphaNum)
+	accessKey := acctest.RandStringFromCharSet(20, acctest.CharSetAlphaNum)
+	accessSecret := acctest.RandStringFromCharSet(40, acctest.CharSetAlphaNum)
+
+	client := casdoorsdk.NewClient(
+		config.Endpoint,
+		config.ClientID,
+		config.ClientSecret,
+		config.Certificate,
+		config.OrganizationName,
+		config.ApplicationName,
+	)
+	serviceAccount := &casdoorsdk.User{
+		Owner:        config.OrganizationName,
+		Name:         rName + "-ci",
+		CreatedTime:  time.Now().UTC().Format(time.RFC3339),
+		Type:         "normal-user",
+		DisplayName:  "CI Service Account",
+		IsAdmin:      true,
+		AccessKey:    accessKey,
+		AccessSecret: accessSecret,
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories(config),
+		Steps: []resource.TestStep{
+			{
+				PreConfig: func() {
+					if _, err := client.AddUser(serviceAccount); err != nil {
+						t.Fatalf("Failed to create service account: %v", err)
+					}
+					t.Cleanup(func() {
+						_, _ = client.DeleteUser(serviceAccount)
+					})
+				},
+				Config:      testAccProviderAccessKeyConfig(config, accessKey, "wrong-secret") + testAccRoleResourceConfig(config.OrganizationName, rName, "Test Role"),
+				ExpectError: regexp.MustCompile("Unauthorized operation"),
+			},
+			{
+				Config: testAccProviderAccessKeyConfig(config, accessKey, accessSecret) + testAccRoleResourceConfig(config.OrganizationName, rName, "Test Role"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("casdoor_role.test", "name", rName),
+					resource.TestCheckResourceAttr("casdoor_role.test", "owner", config.OrganizationName),
+				),
+			},
+		},
+	})
+}
+
+func TestAccProvider_totp(t *testing.T) {
+	config := setupTestConfig(t)
+	rName := "tf-test-" + acctest.RandStringFromCharSet(8, acctest.CharSetAlphaNum)
+	password := acctest.RandStringFromCharSet(16, acctest.CharSetAlphaNum)
+	totpSecret := "JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"
+
+	client := casdoorsdk.NewClient(
+		config.Endpoint,
+		config.ClientID,
+		config.ClientSecret,
+		config.Certificate,
+		config.OrganizationName,
+		config.ApplicationName,
+	)
+	mfaAdmin := &casdoorsdk.User{
+		Owner:            config.OrganizationName,
+		Name:             rName + "-admin",
+		CreatedTime:      time.Now().UTC().Format(time.RFC3339),
+		Type:             "normal-user",
+		DisplayName:      "MFA Admin",
+		Password:         password,
+		IsAdmin:          true,
+		TotpSecret:       totpSecret,
+		PreferredMfaType: "app",
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories(config),
+		Steps: []resource.TestStep{
+			// Logging in without the second factor fails.
+			{
+				PreConfig: func() {
+					if _, err := client.AddUser(mfaAdmin); err != nil {
+						t.Fatalf("Failed to create MFA admin: %v", err)
+					}
+					t.Cleanup(func() {
+						_, _ = client.DeleteUser(mfaAdmin)
+					})
+				},
+				Config:      testAccProviderLoginConfig(config, mfaAdmin.Name, password, "") + testAccRoleResourceConfig(config.OrganizationName, rName, "Test Role"),
+				ExpectError: regexp.MustCompile("has MFA enabled"),
+			},
+			{
+				Config:      testAccProviderLoginConfig(config, mfaAdmin.Name, password, `totp_code = "abcdef"`) + testAccRoleResourceConfig(config.OrganizationName, rName, "Test Role"),
+				ExpectError: regexp.MustCompile("MFA verification failed"),
+			},
+			{
+				Config: testAccProviderLoginConfig(config, mfaAdmin.Name, password, fmt.Sprintf("totp_secret = %q", totpSecret)) + testAccRoleResourceConfig(config.OrganizationName, rName, "Test Role"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("casdoor_role.test", "name", rName),
+				),
+			},
+		},
+	})
+}
+
+func TestAccProvider_sessionLogin(t *testing.T) {
+	config := setupTestConfig(t)
+	rName := "tf-test-" + acctest.RandStringFromCharSet(8, acctest.CharSetAlphaNum)
+	password := acctest.RandStringFromCharSet(16, acctest.CharSetAlphaNum)
+
+	client := casdoorsdk.NewClient(
+		config.Endpoint,
+		config.ClientID,
+		config.ClientSecret,
+		config.Certificate,
+		config.OrganizationName,
+		config.ApplicationName,
+	)
+	admin := &casdoorsdk.User{
+		Owner:       config.OrganizationName,
+		Name:        rName + "-admin",
+		CreatedTime: time.Now().UTC().Format(time.RFC3339),
+		Type:        "normal-user",
+		DisplayName: "Session Admin",
+		Password:    password,
+		IsAdmin:     true,
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories(config),
+		Steps: []resource.TestStep{
+			{
+				PreConfig: func() {
+					if _, err := client.AddUser(admin); err != nil {
+						t.Fatalf("Failed to create admin: %v", err)
+					}
+					t.Cleanup(func() {
+						_, _ = client.DeleteUser(admin)
+					})
+				},
+				Config:      testAccProviderLoginConfig(config, admin.Name, password, `login_mode = "cookie"`) + testAccRoleResourceConfig(config.OrganizationName, rName, "Test Role"),
+				ExpectError: regexp.MustCompile("Invalid Login Mode"),
+			},
+			{
+				Config: testAccProviderLoginConfig(config, admin.Name, password, `login_mode = "session"`) + testAccRoleResourceConfig(config.OrganizationName, rName, "Test Role"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("casdoor_role.test", "name", rName),
+				),
+			},
+			{
+				Config: testAccProviderLoginConfig(config, admin.Name, password, `login_mode = "session"`) + testAccRoleResourceConfig(config.OrganizationName, rName, "Updated Role"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("casdoor_role.test", "display_name", "Updated Role"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccProvider_jwks(t *testing.T) {
+	config := setupTestConfig(t)
+	rName := "tf-test-" + acctest.RandStringFromCharSet(8, acctest.CharSetAlphaNum)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: rName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+
+	client := casdoorsdk.NewClient(
+		config.Endpoint,
+		config.ClientID,
+		config.ClientSecret,
+		config.Certificate,
+		config.OrganizationName,
+		config.ApplicationName,
+	)
+	cert := &casdoorsdk.Cert{
+		Owner:           "admin",
+		Name:            rName,
+		CreatedTime:     time.Now().UTC().Format(time.RFC3339),
+		DisplayName:     "JWKS Test Cert",
+		Scope:           "JWT",
+		Type:            "x509",
+		CryptoAlgorithm: "RS256",
+		BitSize:         2048,
+		ExpireInYears:   1,
+		Certificate:     string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		PrivateKey:      string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})),
+	}
+
+	// A token signed with the new cert's key verifies against the key set
+	// once the cert is added, without restarting the provider's client.
+	checkToken := func(kid string, wantErr bool) resource.TestCheckFunc {
+		return func(*terraform.State) error {
+			token := jwt.NewWithClaims(jwt.SigningMethodRS256, casdoorsdk.Claims{
+				User: casdoorsdk.User{Owner: config.OrganizationName, Name: rName},
+				RegisteredClaims: jwt.RegisteredClaims{
+					ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+				},
+			})
+			token.Header["kid"] = kid
+			signed, err := token.SignedString(key)
+			if err != nil {
+				return err
+			}
+
+			claims, err := newSDKClient(casdoorsdk.NewClient(
+				config.Endpoint, config.ClientID, config.ClientSecret, "", config.OrganizationName, config.ApplicationName,
+			)).ParseJwtToken(context.Background(), signed)
+			if wantErr {
+				if err == nil {
+					return fmt.Errorf("token with key ID %q verified", kid)
+				}
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("failed to verify token: %w", err)
+			}
+			if claims.Name != rName {
+				return fmt.Errorf("expected name %q, got %q", rName, claims.Name)
+			}
+			return nil
+		}
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories(config),
+		Steps: []resource.TestStep{
+			{
+				PreConfig: func() {
+					if _, err := client.AddCert(cert); err != nil {
+						t.Fatalf("Failed to create cert: %v", err)
+					}
+					t.Cleanup(func() {
+						_, _ = client.DeleteCert(cert)
+					})
+				},
+				Config: testAccProviderWithoutCertificateConfig(config) + testAccRoleResourceConfig(config.OrganizationName, rName, "Test Role"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("casdoor_role.test", "name", rName),
+					checkToken(rName, false),
+					checkToken(rName+"-unknown", true),
+				),
+			},
+		},
+	})
+}
+
+func TestAccProvider_failoverEndpoints(t *testing.T) {
+	config := setupTestConfig(t)
+	rName := "tf-test-" + acctest.RandStringFromCharSet(8, acctest.CharSetAlphaNum)
+	// Nothing listens on the discard port, so the primary cannot be reached.
+	unreachable := "http://127.0.0.1:9"
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories(config),
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccProviderFailoverConfig(config, unreachable, []string{config.Endpoint}, []string{"https://other.example.com"}) + testAccRoleResourceConfig(config.OrganizationName, rName, "Test Role"),
+				ExpectError: regexp.MustCompile("Invalid Write Fallback Endpoint"),
+			},
+			// Reads fail over, but writes are pinned to the primary.
+			{
+				Config:      testAccProviderFailoverConfig(config, unreachable, []string{config.Endpoint}, nil) + testAccRoleResourceConfig(config.OrganizationName, rName, "Test Role"),
+				ExpectError: regexp.MustCompile(regexp.QuoteMeta(unreachable)),
+			},
+			{
+				Config: testAccProviderFailoverConfig(config, unreachable, []string{config.Endpoint}, []string{config.Endpoint}) + testAccRoleResourceConfig(config.OrganizationName, rName, "Test Role"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("casdoor_role.test", "name", rName),
+				),
+			},
+		},
+	})
+}
+
+func TestAccProvider_readOnly(t *testing.T) {
+	config := setupTestConfig(t)
+	rName := "tf-test-" + acctest.RandStringFromCharSet(8, acctest.CharSetAlphaNum)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories(config),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(config) + testAccRoleResourceConfig(config.OrganizationName, rName, "Test Role"),
+			},
+			// Refreshing and planning work in read-only mode.
+			{
+				Config:   testAccProviderReadOnlyConfig(config) + testAccRoleResourceConfig(config.OrganizationName, rName, "Test Role"),
+				PlanOnly: true,
+			},
+			{
+				Config:            testAccProviderReadOnlyConfig(config) + testAccRoleResourceConfig(config.OrganizationName, rName, "Test Role"),
+				ResourceName:      "casdoor_role.test",
+				ImportState:       true,
+				ImportStateId:     config.OrganizationName + "/" + rName,
+				ImportStateVerify: true,
+			},
+			{
+				Config:      testAccProviderReadOnlyConfig(config) + testAccRoleResourceConfig(config.OrganizationName, rName, "Updated Role"),
+				ExpectError: regexp.MustCompile("Read-Only Provider"),
+			},
+			// Leave read-only mode so that the role can be destroyed.
+			{
+				Config: testAccProviderConfig(config) + testAccRoleResourceConfig(config.OrganizationName, rName, "Test Role"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("casdoor_role.test", "display_name", "Test Role"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccProvider_defaultOwner(t *testing.T) {
+	config := setupTestConfig(t)
+	rName := "tf-test-" + acctest.RandStringFromCharSet(8, acctest.CharSetAlphaNum)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories(config),
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccProviderConfig(config) + testAccProviderDefaultOwnerRoleConfig(rName),
+				ExpectError: regexp.MustCompile("Missing Owner"),
+			},
+			// The owner, and with it the ID, is known at plan time.
+			{
+				Config: testAccProviderDefaultOwnerConfig(config) + testAccProviderDefaultOwnerRoleConfig(rName),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectKnownValue("casdoor_role.test", tfjsonpath.New("owner"), knownvalue.StringExact(config.OrganizationName)),
+						plancheck.ExpectKnownValue("casdoor_role.test", tfjsonpath.New("id"), knownvalue.StringExact(config.OrganizationName+"/"+rName)),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("casdoor_role.test", "owner", config.OrganizationName),
+					resource.TestCheckResourceAttr("casdoor_role.test", "id", config.OrganizationName+"/"+rName),
+				),
+			},
+			// Setting the same owner explicitly changes nothing.
+			{
+				Config: testAccProviderDefaultOwnerConfig(config) + testAccRoleResourceConfig(config.OrganizationName, rName, ""),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectEmptyPlan(),
+					},
+				},
+			},
+		},
+	})
+}
+
+func testAccProviderWaitForReadyConfig(config CasdoorTestConfig, waitForReady string) string {
+	return fmt.Sprintf(`
+provider "casdoor" {
+  endpoint          = %q
+  client_id         = %q
+  client_secret     = %q
+  certificate       = %q
+  organization_name = %q
+  application_name  = %q
+  wait_for_ready    = %q
+}
+`, config.Endpoint, config.ClientID, config.ClientSecret, config.Certificate, config.OrganizationName, config.ApplicationName, waitForReady)
+}
+
+func testAccProviderWithoutCertificateConfig(config CasdoorTestConfig) string {
+	return fmt.Sprintf(`
+provider "casdoor" {
+  endpoint          = %q
+  client_id         = %q
+  client_secret     = %q
+  organization_name = %q
+  application_name  = %q
+}
+`, config.Endpoint, config.ClientID, config.ClientSecret, config.OrganizationName, config.ApplicationName)
+}
+
+func testAccProviderFailoverConfig(config CasdoorTestConfig, endpoint string, failoverEndpoints, writeFallbackEndpoints []string) string {
+	return fmt.Sprintf(`
+provider "casdoor" {
+  endpoint                 = %q
+  failover_endpoints       = %s
+  write_fallback_endpoints = %s
+  client_id                = %q
+  client_secret            = %q
+  certificate              = %q
+  organization_name        = %q
+  application_name         = %q
+}
+`, endpoint, testAccHCLList(failoverEndpoints), testAccHCLList(writeFallbackEndpoints), config.ClientID, config.ClientSecret, config.Certificate, config.OrganizationName, config.ApplicationName)
+}
+
+// testAccHCLList renders values as an HCL list of strings.
+func testAccHCLList(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = strconv.Quote(v)
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
+func testAccProviderReadOnlyConfig(config CasdoorTestConfig) string {
+	return fmt.Sprintf(`
+provider "casdoor" {
+  endpoint          = %q
+  client_id         = %q
+  client_secret     = %q
+  certificate       = %q
+  organization_name = %q
+  application_name  = %q
+  read_only         = true
+}
+`, config.Endpoint, config.ClientID, config.ClientSecret, config.Certificate, config.OrganizationName, config.ApplicationName)
+}
+
+func testAccProviderDefaultOwnerConfig(config CasdoorTestConfig) string {
+	return fmt.Sprintf(`
+provider "casdoor" {
+  endpoint          = %q
+  client_id         = %q
+  client_secret     = %q
+  certificate       = %q
+  organization_name = %q
+  application_name  = %q
+  default_owner     = %q
+}
+`, config.Endpoint, config.ClientID, config.ClientSecret, config.Certificate, config.OrganizationName, config.ApplicationName, config.OrganizationName)
+}
+
+func testAccProviderDefaultOwnerRoleConfig(name string) string {
+	return fmt.Sprintf(`
+resource "casdoor_role" "test" {
+  name = %q
+}
+`, name)
+}
+
+func testAccProviderAccessKeyConfig(config CasdoorTestConfig, accessKey, accessSecret string) string {
+	return fmt.Sprintf(`
+provider "casdoor" {
+  endpoint          = %q
+  organization_name = %q
+  access_key        = %q
+  access_secret     = %q
+}
+`, config.Endpoint, config.OrganizationName, accessKey, accessSecret)
+}
+
+func testAccProviderLoginConfig(config CasdoorTestConfig, username, password, extra string) string {
+	return fmt.Sprintf(`
+provider "casdoor" {
+  endpoint          = %q
+  organization_name = %q
+  application_name  = %q
+  username          = %q
+  password          = %q
+  %s
+}
+`, config.Endpoint, config.OrganizationName, config.ApplicationName, username, password, extra)
+}
+
+// TestFetchCredentialsViaLogin runs against the fake Casdoor server. The
+// credentials and the certificate are read from the application's real
+// owner and organization.
+func TestFetchCredentialsViaLogin(t *testing.T) {
+	fake := newFakeCasdoor()
+	t.Cleanup(fake.Close)
+
+	const orgCertificate = "-----BEGIN CERTIFICATE-----\norg\n-----END CERTIFICATE-----"
+	fake.seed("organization", map[string]any{"owner": "admin", "name": "acme", "passwordType": "plain"})
+	fake.seed("application", map[string]any{
+		"owner": "acme", "name": "app-acme", "organization": "acme", "cert": "cert-acme",
+		"clientId": "acme-client", "clientSecret": "acme-secret",
+	})
+	fake.seed("cert", map[string]any{"owner": "acme", "name": "cert-acme", "certificate": orgCertificate})
+
+	tests := []struct {
+		name            string
+		application     string
+		certificate     string
+		wantClientID    string
+		wantCertificate string
+	}{
+		{
+			name:            "admin application",
+			application:     "app-built-in",
+			wantClientID:    fakeClientID,
+			wantCertificate: testJwtPublicKey,
+		},
+		{
+			name:            "application owned by an organization",
+			application:     "acme/app-acme",
+			wantClientID:    "acme-client",
+			wantCertificate: orgCertificate,
+		},
+		{
+			name:            "configured certificate",
+			application:     "acme/app-acme",
+			certificate:     "configured",
+			wantClientID:    "acme-client",
+			wantCertificate: "configured",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			creds, err := fetchCredentialsViaLogin(context.Background(), &passwordLogin{
+				endpoint:     fake.server.URL,
+				organization: "built-in",
+				application:  tt.application,
+				username:     "admin",
+				password:     defaultAdminPassword,
+			}, tt.certificate)
+			if err != nil {
+				t.Fatalf("Failed to fetch credentials: %v", err)
+			}
+			if creds.ClientID != tt.wantClientID {
+				t.Errorf("Expected client ID %q, got %q", tt.wantClientID, creds.ClientID)
+			}
+			if creds.Certificate != tt.wantCertificate {
+				t.Errorf("Expected certificate %q, got %q", tt.wantCertificate, creds.Certificate)
+			}
+		})
+	}
+}
@@ -0,0 +1,225 @@
+// Copyright (c) HashiCorp, Inc.
+
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// adoptExistingAttribute returns the adopt_existing schema attribute for a
+// resource managing objects of the given kind.
+func adoptExistingAttribute(kind string) schema.BoolAttribute {
+	return schema.BoolAttribute{
+		Description: fmt.Sprintf("Adopt an existing %s with the same identity instead of failing to create it, "+
+			"e.g. one Casdoor created on its own or seeded from init_data.json. Attributes set in configuration "+
+			"overwrite the adopted %s; the others keep their current values. Defaults to the provider's "+
+			"adopt_existing setting.", kind, kind),
+		Optional: true,
+	}
+}
+
+// adoptionEnabled reports whether the resource adopts existing objects: its
+// adopt_existing attribute if set, otherwise the provider default.
+func adoptionEnabled(ctx context.Context, data *CasdoorProviderData, plan tfsdk.Plan) (bool, diag.Diagnostics) {
+	var adopt types.Bool
+
+	diags := plan.GetAttribute(ctx, path.Root("adopt_existing"), &adopt)
+	if diags.HasError() || adopt.IsUnknown() {
+		return false, diags
+	}
+	if !adopt.IsNull() {
+		return adopt.ValueBool(), diags
+	}
+
+	return data != nil && data.AdoptExisting, diags
+}
+
+// planAdoption is called from ModifyPlan. When a resource is about to be
+// created with adoption enabled and the object already exists, it reads the
+// object with read and plans every attribute left out of configuration with
+// the value the object already has, so that the plan shows what is adopted.
+func planAdoption(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse, data *CasdoorProviderData, kind string, read func(context.Context, resource.ReadRequest, *resource.ReadResponse)) {
+	if data == nil || resp.Plan.Raw.IsNull() || !req.State.Raw.IsNull() {
+		return
+	}
+
+	adopt, diags := adoptionEnabled(ctx, data, resp.Plan)
+	resp.Diagnostics.Append(diags...)
+	if !adopt || resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The object can only be looked up once its identity is known.
+	var planned map[string]tftypes.Value
+	if err := resp.Plan.Raw.As(&planned); err != nil {
+		resp.Diagnostics.AddError("Error Planning Adoption", fmt.Sprintf("Could not read plan: %s", err))
+		return
+	}
+	for name, attribute := range resp.Plan.Schema.GetAttributes() {
+		if (attribute.IsRequired() || name == "owner") && !planned[name].IsFullyKnown() {
+			return
+		}
+	}
+
+	readResp := &resource.ReadResponse{
+		State:   tfsdk.State{Schema: resp.Plan.Schema, Raw: resp.Plan.Raw.Copy()},
+		Private: resp.Private,
+	}
+	read(ctx, resource.ReadRequest{State: readResp.State}, readResp)
+	resp.Diagnostics.Append(readResp.Diagnostics...)
+	resp.Diagnostics.Append(clearObjectFingerprint(ctx, resp.Private)...)
+	if resp.Diagnostics.HasError() || readResp.State.Raw.IsNull() {
+		return
+	}
+
+	// Some kinds read another object when there is none with the planned
+	// identity, which is not the one to adopt.
+	var existing map[string]tftypes.Value
+	if err := readResp.State.Raw.As(&existing); err != nil {
+		resp.Diagnostics.AddError("Error Planning Adoption", fmt.Sprintf("Could not read the existing object: %s", err))
+		return
+	}
+	for _, name := range []string{"owner", "name", "id"} {
+		if value, ok := planned[name]; ok && value.IsKnown() && !value.IsNull() && !value.Equal(existing[name]) {
+			return
+		}
+	}
+
+	names, err := unconfiguredAttributes(req.Config)
+	if err != nil {
+		resp.Diagnostics.AddError("Error Planning Adoption", fmt.Sprintf("Could not read configuration: %s", err))
+		return
+	}
+
+	resp.Plan.Raw, err = copyAttributes(resp.Plan.Raw, readResp.State.Raw, names)
+	if err != nil {
+		resp.Diagnostics.AddError("Error Planning Adoption", fmt.Sprintf("Could not plan unconfigured attributes: %s", err))
+		return
+	}
+
+	var id types.String
+	resp.Diagnostics.Append(readResp.State.GetAttribute(ctx, path.Root("id"), &id)...)
+
+	resp.Diagnostics.AddWarning(
+		fmt.Sprintf("Adopting Existing %s", strings.ToUpper(kind[:1])+kind[1:]),
+		fmt.Sprintf("The %s %q already exists and will be adopted instead of created. "+
+			"Attributes set in configuration overwrite its current values; the others are kept as shown in the plan.", kind, id.ValueString()),
+	)
+}
+
+// configuredJSONKeys returns the Casdoor JSON keys of the attributes that are
+// set in configuration. These are the fields written onto an adopted object;
+// schema defaults and values planned from the adopted object itself are left
+// as Casdoor has them.
+func configuredJSONKeys(config tfsdk.Config) ([]string, error) {
+	names, err := configuredAttributes(config)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	for _, name := range names {
+		if name == "id" {
+			continue
+		}
+		keys = append(keys, attributeJSONKey(name))
+	}
+
+	return keys, nil
+}
+
+// adoptOnCreate is called from Create before the object is added. If
+// adoption is enabled for the resource and an object with id already exists,
+// the attributes set in config, plus any fields named by extraKeys, are
+// overlaid from planned onto it and it is updated through api instead of being
+// created. It returns true if the object was adopted; errors are recorded in
+// diags.
+func adoptOnCreate[T any](ctx context.Context, data *CasdoorProviderData, plan tfsdk.Plan, config tfsdk.Config, diags *diag.Diagnostics, kind, id string, planned *T, api objectAPI[T], extraKeys ...string) bool {
+	adopt, d := adoptionEnabled(ctx, data, plan)
+	diags.Append(d...)
+	if !adopt || diags.HasError() {
+		return false
+	}
+
+	existing, err := api.Get(ctx, id)
+	if err != nil {
+		diags.AddError(
+			"Error Checking for Existing Object",
+			fmt.Sprintf("Could not check for existing %s %q: %s", kind, id, err),
+		)
+		return false
+	}
+	if existing == nil {
+		return false
+	}
+	same, err := isObject(existing, id)
+	if err != nil {
+		diags.AddError(
+			"Error Checking for Existing Object",
+			fmt.Sprintf("Could not read the identity of existing %s %q: %s", kind, id, err),
+		)
+		return false
+	}
+	if !same {
+		return false
+	}
+	if checkBuiltInModify(data, diags, kind, id) {
+		return false
+	}
+
+	keys, err := configuredJSONKeys(config)
+	if err == nil {
+		existing, err = overlayJSON(existing, planned, append(keys, extraKeys...))
+	}
+	if err != nil {
+		diags.AddError(
+			"Error Adopting Existing Object",
+			fmt.Sprintf("Could not merge the planned values onto %s %q: %s", kind, id, err),
+		)
+		return false
+	}
+
+	ok, err := api.Update(ctx, existing)
+	if sdkError(diags, ok, err, fmt.Sprintf("adopting %s %q", kind, id)) {
+		return false
+	}
+
+	return true
+}
+
+// isObject reports whether obj is the object with the given 'owner/name' ID,
+// or 'owner/id' for kinds without a name. Casdoor falls back to an object of
+// the same name owned by admin when getting a certificate or a model that
+// does not exist, which must not be adopted in its place.
+func isObject(obj any, id string) (bool, error) {
+	content, err := json.Marshal(obj)
+	if err != nil {
+		return false, err
+	}
+
+	var fields struct {
+		Owner string `json:"owner"`
+		Name  string `json:"name"`
+		ID    string `json:"id"`
+	}
+	if err := json.Unmarshal(content, &fields); err != nil {
+		return false, err
+	}
+
+	name := fields.Name
+	if name == "" {
+		name = fields.ID
+	}
+	return fields.Owner+"/"+name == id, nil
+}
@@ -0,0 +1,36 @@
+// Copyright (c) HashiCorp, Inc.
+
+package provider
+
+import (
+	"testing"
+
+	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+)
+
+func TestIsObject(t *testing.T) {
+	tests := []struct {
+		name string
+		obj  any
+		id   string
+		want bool
+	}{
+		{name: "same", obj: casdoorsdk.Model{Owner: "acme", Name: "shared"}, id: "acme/shared", want: true},
+		{name: "built-in model fallback", obj: casdoorsdk.Model{Owner: "built-in", Name: "shared"}, id: "acme/shared"},
+		{name: "admin certificate fallback", obj: casdoorsdk.Cert{Owner: "admin", Name: "cert"}, id: "acme/cert"},
+		{name: "other name", obj: casdoorsdk.Model{Owner: "acme", Name: "other"}, id: "acme/shared"},
+		{name: "without name", obj: casdoorsdk.Ldap{Owner: "acme", Id: "ldap"}, id: "acme/ldap", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := isObject(tt.obj, tt.id)
+			if err != nil {
+				t.Fatalf("Failed to check %+v: %v", tt.obj, err)
+			}
+			if got != tt.want {
+				t.Errorf("Expected isObject(%+v, %q) to be %v, got %v", tt.obj, tt.id, tt.want, got)
+			}
+		})
+	}
+}
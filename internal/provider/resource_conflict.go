@@ -0,0 +1,140 @@
+// Copyright (c) HashiCorp, Inc.
+
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+// objectFingerprintKey is the private state key under which resources keep
+// the fingerprint of the object as Terraform last read it.
+const objectFingerprintKey = "object_fingerprint"
+
+// privateState is implemented by the Private field of resource responses.
+type privateState interface {
+	GetKey(ctx context.Context, key string) ([]byte, diag.Diagnostics)
+	SetKey(ctx context.Context, key string, value []byte) diag.Diagnostics
+}
+
+// objectFingerprint identifies the version of an object as returned by
+// Casdoor: its updatedTime when Casdoor maintains one, otherwise a hash of
+// the whole object.
+func objectFingerprint(obj any) (string, error) {
+	content, err := json.Marshal(obj)
+	if err != nil {
+		return "", err
+	}
+
+	var fields struct {
+		UpdatedTime string `json:"updatedTime"`
+	}
+	if err := json.Unmarshal(content, &fields); err == nil && fields.UpdatedTime != "" {
+		return "updatedTime:" + fields.UpdatedTime, nil
+	}
+
+	sum := sha256.Sum256(content)
+	return "sha256:" + hex.EncodeToString(sum[:]), nil
+}
+
+// setObjectFingerprint records the fingerprint of obj in private state. Read
+// calls it so that a later Update can tell whether the object changed in
+// between.
+func setObjectFingerprint(ctx context.Context, private privateState, obj any) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	fingerprint, err := objectFingerprint(obj)
+	if err != nil {
+		diags.AddWarning(
+			"Unable to Fingerprint Object",
+			fmt.Sprintf("Update conflicts will not be detected for this object: %s", err),
+		)
+		return diags
+	}
+
+	value, err := json.Marshal(fingerprint)
+	if err != nil {
+		diags.AddWarning(
+			"Unable to Fingerprint Object",
+			fmt.Sprintf("Update conflicts will not be detected for this object: %s", err),
+		)
+		return diags
+	}
+
+	return private.SetKey(ctx, objectFingerprintKey, value)
+}
+
+// clearObjectFingerprint drops the recorded fingerprint. Update calls it once
+// it has written the object, as the fingerprint no longer matches; the next
+// refresh records a new one.
+func clearObjectFingerprint(ctx context.Context, private privateState) diag.Diagnostics {
+	return private.SetKey(ctx, objectFingerprintKey, nil)
+}
+
+// checkUpdateConflict fetches the object about to be updated with get and
+// compares its fingerprint with the one recorded when Terraform last read
+// it. If they differ, someone changed the object in Casdoor in between and
+// the update would silently overwrite their change, so an error is recorded.
+// Nothing is checked if the provider has ignore_update_conflicts set or no
+// fingerprint was recorded yet. Returns true if an error was recorded.
+func checkUpdateConflict[T any](
+	ctx context.Context,
+	data *CasdoorProviderData,
+	diags *diag.Diagnostics,
+	private privateState,
+	kind, id string,
+	get func(ctx context.Context, id string) (*T, error),
+) bool {
+	if data == nil || data.IgnoreUpdateConflicts {
+		return false
+	}
+
+	recorded, d := private.GetKey(ctx, objectFingerprintKey)
+	diags.Append(d...)
+	if diags.HasError() {
+		return true
+	}
+	if len(recorded) == 0 {
+		return false
+	}
+
+	var want string
+	if err := json.Unmarshal(recorded, &want); err != nil {
+		return false
+	}
+
+	current, err := get(ctx, id)
+	if err != nil {
+		diags.AddError(
+			"Error Checking for Conflicting Changes",
+			fmt.Sprintf("Could not read %s %q to check for conflicting changes: %s", kind, id, err),
+		)
+		return true
+	}
+
+	if current == nil {
+		diags.AddError(
+			"Conflicting Update",
+			fmt.Sprintf("The %s %q was deleted in Casdoor after Terraform last read it. "+
+				"Run terraform plan again to review the change.", kind, id),
+		)
+		return true
+	}
+
+	got, err := objectFingerprint(current)
+	if err != nil || got == want {
+		return false
+	}
+
+	diags.AddError(
+		"Conflicting Update",
+		fmt.Sprintf("The %s %q was changed in Casdoor after Terraform last read it, and applying this plan would overwrite that change. "+
+			"Run terraform plan again to review the change, or set ignore_update_conflicts in the provider configuration to overwrite it anyway.", kind, id),
+	)
+	return true
+}
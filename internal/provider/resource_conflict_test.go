@@ -0,0 +1,176 @@
+// Copyright (c) HashiCorp, Inc.
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+// testPrivateState is an in-memory privateState.
+type testPrivateState map[string][]byte
+
+func (p testPrivateState) GetKey(_ context.Context, key string) ([]byte, diag.Diagnostics) {
+	return p[key], nil
+}
+
+func (p testPrivateState) SetKey(_ context.Context, key string, value []byte) diag.Diagnostics {
+	p[key] = value
+	return nil
+}
+
+type testVersionedObject struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+	UpdatedTime string `json:"updatedTime,omitempty"`
+}
+
+func TestObjectFingerprint(t *testing.T) {
+	fingerprint := func(obj testVersionedObject) string {
+		t.Helper()
+		got, err := objectFingerprint(obj)
+		if err != nil {
+			t.Fatalf("Failed to fingerprint %+v: %v", obj, err)
+		}
+		return got
+	}
+
+	// With updatedTime, only the time counts.
+	a := fingerprint(testVersionedObject{Name: "a", DisplayName: "A", UpdatedTime: "2026-01-01T00:00:00Z"})
+	if want := "updatedTime:2026-01-01T00:00:00Z"; a != want {
+		t.Errorf("Expected fingerprint %q, got %q", want, a)
+	}
+	if b := fingerprint(testVersionedObject{Name: "a", DisplayName: "B", UpdatedTime: "2026-01-01T00:00:00Z"}); b != a {
+		t.Errorf("Expected the same fingerprint for the same updatedTime, got %q and %q", a, b)
+	}
+
+	// Without it, the whole object is hashed.
+	c := fingerprint(testVersionedObject{Name: "a", DisplayName: "A"})
+	if !strings.HasPrefix(c, "sha256:") {
+		t.Errorf("Expected a sha256 fingerprint, got %q", c)
+	}
+	if d := fingerprint(testVersionedObject{Name: "a", DisplayName: "A"}); d != c {
+		t.Errorf("Expected the same fingerprint for the same object, got %q and %q", c, d)
+	}
+	if e := fingerprint(testVersionedObject{Name: "a", DisplayName: "B"}); e == c {
+		t.Errorf("Expected a different fingerprint for a changed object, got %q for both", c)
+	}
+}
+
+func TestCheckUpdateConflict(t *testing.T) {
+	read := testVersionedObject{Name: "a", DisplayName: "A", UpdatedTime: "2026-01-01T00:00:00Z"}
+	readUnversioned := testVersionedObject{Name: "a", DisplayName: "A"}
+
+	tests := []struct {
+		name         string
+		data         *CasdoorProviderData
+		recorded     *testVersionedObject
+		current      *testVersionedObject
+		getErr       error
+		wantConflict bool
+		wantSummary  string
+	}{
+		{
+			name:     "unchanged updatedTime",
+			recorded: &read,
+			current:  &testVersionedObject{Name: "a", DisplayName: "A", UpdatedTime: read.UpdatedTime},
+		},
+		{
+			name:         "changed updatedTime",
+			recorded:     &read,
+			current:      &testVersionedObject{Name: "a", DisplayName: "A", UpdatedTime: "2026-01-02T00:00:00Z"},
+			wantConflict: true,
+			wantSummary:  "Conflicting Update",
+		},
+		{
+			name:     "unchanged hash",
+			recorded: &readUnversioned,
+			current:  &testVersionedObject{Name: "a", DisplayName: "A"},
+		},
+		{
+			name:         "changed hash",
+			recorded:     &readUnversioned,
+			current:      &testVersionedObject{Name: "a", DisplayName: "B"},
+			wantConflict: true,
+			wantSummary:  "Conflicting Update",
+		},
+		{
+			name:     "ignored",
+			data:     &CasdoorProviderData{IgnoreUpdateConflicts: true},
+			recorded: &read,
+			current:  &testVersionedObject{Name: "a", DisplayName: "B", UpdatedTime: "2026-01-02T00:00:00Z"},
+		},
+		{
+			name:    "not recorded",
+			current: &testVersionedObject{Name: "a", DisplayName: "B"},
+		},
+		{
+			name:         "deleted",
+			recorded:     &read,
+			wantConflict: true,
+			wantSummary:  "Conflicting Update",
+		},
+		{
+			name:         "read error",
+			recorded:     &read,
+			getErr:       errors.New("connection refused"),
+			wantConflict: true,
+			wantSummary:  "Error Checking for Conflicting Changes",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			data := tt.data
+			if data == nil {
+				data = &CasdoorProviderData{}
+			}
+
+			private := testPrivateState{}
+			if tt.recorded != nil {
+				if diags := setObjectFingerprint(ctx, private, tt.recorded); diags.HasError() {
+					t.Fatalf("Failed to record fingerprint: %v", diags)
+				}
+			}
+
+			get := func(_ context.Context, id string) (*testVersionedObject, error) {
+				if id != "built-in/a" {
+					t.Errorf("Expected ID %q, got %q", "built-in/a", id)
+				}
+				return tt.current, tt.getErr
+			}
+
+			var diags diag.Diagnostics
+			conflict := checkUpdateConflict(ctx, data, &diags, private, "object", "built-in/a", get)
+			if conflict != tt.wantConflict {
+				t.Fatalf("Expected conflict %v, got %v (%v)", tt.wantConflict, conflict, diags)
+			}
+			if conflict != diags.HasError() {
+				t.Fatalf("Expected an error exactly when a conflict is reported, got %v", diags)
+			}
+			if tt.wantSummary != "" && diags.Errors()[0].Summary() != tt.wantSummary {
+				t.Errorf("Expected error %q, got %q", tt.wantSummary, diags.Errors()[0].Summary())
+			}
+		})
+	}
+}
+
+func TestClearObjectFingerprint(t *testing.T) {
+	ctx := context.Background()
+	private := testPrivateState{}
+
+	setObjectFingerprint(ctx, private, testVersionedObject{Name: "a", UpdatedTime: "2026-01-01T00:00:00Z"})
+	clearObjectFingerprint(ctx, private)
+
+	var diags diag.Diagnostics
+	changed := &testVersionedObject{Name: "a", UpdatedTime: "2026-01-02T00:00:00Z"}
+	if checkUpdateConflict(ctx, &CasdoorProviderData{}, &diags, private, "object", "built-in/a",
+		func(context.Context, string) (*testVersionedObject, error) { return changed, nil }) {
+		t.Fatalf("Expected no conflict once the fingerprint is cleared, got %v", diags)
+	}
+}
@@ -5,8 +5,11 @@ package provider
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
@@ -55,3 +58,50 @@ func stringListFromSDK(ctx context.Context, slice []string) (types.List, diag.Di
 
 	return types.ListValueFrom(ctx, types.StringType, slice)
 }
+
+// useStateUnlessChanged keeps the prior state value of a computed attribute,
+// like stringplanmodifier.UseStateForUnknown, unless one of the attributes at
+// deps is changing, in which case the value stays unknown until apply. It is
+// used for values derived from the name (the ID) or other configurable
+// attributes. A dependency that is left out of configuration and not planned
+// yet counts as unchanged.
+func useStateUnlessChanged(deps ...path.Path) planmodifier.String {
+	return useStateUnlessChangedModifier{deps: deps}
+}
+
+type useStateUnlessChangedModifier struct {
+	deps []path.Path
+}
+
+func (m useStateUnlessChangedModifier) Description(_ context.Context) string {
+	deps := make([]string, len(m.deps))
+	for i, dep := range m.deps {
+		deps[i] = dep.String()
+	}
+	return fmt.Sprintf("Keeps the prior state value unless %s changes.", strings.Join(deps, " or "))
+}
+
+func (m useStateUnlessChangedModifier) MarkdownDescription(ctx context.Context) string {
+	return m.Description(ctx)
+}
+
+func (m useStateUnlessChangedModifier) PlanModifyString(ctx context.Context, req planmodifier.StringRequest, resp *planmodifier.StringResponse) {
+	if req.StateValue.IsNull() || !req.PlanValue.IsUnknown() {
+		return
+	}
+
+	for _, dep := range m.deps {
+		var configDep, planDep, stateDep types.String
+		resp.Diagnostics.Append(req.Config.GetAttribute(ctx, dep, &configDep)...)
+		resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, dep, &planDep)...)
+		resp.Diagnostics.Append(req.State.GetAttribute(ctx, dep, &stateDep)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		if !planDep.Equal(stateDep) && (!configDep.IsNull() || !planDep.IsUnknown()) {
+			return
+		}
+	}
+
+	resp.PlanValue = req.StateValue
+}
@@ -0,0 +1,163 @@
+// Copyright (c) HashiCorp, Inc.
+
+package provider
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+const (
+	// updateModeFull sends every attribute to Casdoor on update, resetting
+	// attributes missing from configuration to their defaults.
+	updateModeFull = "full"
+	// updateModePartial sends only the attributes set in configuration and
+	// leaves everything else as it is on the server.
+	updateModePartial = "partial"
+)
+
+// unconfiguredAttributes returns the names of the top-level attributes that
+// are both optional and computed and are not set in configuration.
+func unconfiguredAttributes(config tfsdk.Config) ([]string, error) {
+	var values map[string]tftypes.Value
+	if err := config.Raw.As(&values); err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for name, attribute := range config.Schema.GetAttributes() {
+		if !attribute.IsOptional() || !attribute.IsComputed() {
+			continue
+		}
+		if v, ok := values[name]; ok && v.IsNull() {
+			names = append(names, name)
+		}
+	}
+
+	return names, nil
+}
+
+// configuredAttributes returns the names of the top-level attributes that are
+// set in configuration, including those whose value is not yet known.
+func configuredAttributes(config tfsdk.Config) ([]string, error) {
+	var values map[string]tftypes.Value
+	if err := config.Raw.As(&values); err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for name, v := range values {
+		if !v.IsNull() {
+			names = append(names, name)
+		}
+	}
+
+	return names, nil
+}
+
+// unknownAttributes returns the names of the top-level attributes of obj
+// whose value is unknown.
+func unknownAttributes(obj tftypes.Value) ([]string, error) {
+	var values map[string]tftypes.Value
+	if err := obj.As(&values); err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for name, v := range values {
+		if !v.IsKnown() {
+			names = append(names, name)
+		}
+	}
+
+	return names, nil
+}
+
+// replaceAttributes returns obj with each named top-level attribute replaced
+// by the result of value, which receives the attribute's current value.
+func replaceAttributes(obj tftypes.Value, names []string, value func(name string, current tftypes.Value) tftypes.Value) (tftypes.Value, error) {
+	var values map[string]tftypes.Value
+	if err := obj.As(&values); err != nil {
+		return obj, err
+	}
+
+	for _, name := range names {
+		if current, ok := values[name]; ok {
+			values[name] = value(name, current)
+		}
+	}
+
+	return tftypes.NewValue(obj.Type(), values), nil
+}
+
+// copyAttributes returns dst with the named top-level attributes taken from
+// src. Both values must be objects of the same schema.
+func copyAttributes(dst, src tftypes.Value, names []string) (tftypes.Value, error) {
+	var from map[string]tftypes.Value
+	if err := src.As(&from); err != nil {
+		return dst, err
+	}
+
+	return replaceAttributes(dst, names, func(name string, current tftypes.Value) tftypes.Value {
+		if v, ok := from[name]; ok {
+			return v
+		}
+		return current
+	})
+}
+
+// attributeJSONKey converts a snake_case attribute name to the camelCase key
+// Casdoor uses for the same field, e.g. "id_card_type" to "idCardType".
+func attributeJSONKey(name string) string {
+	parts := strings.Split(name, "_")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+// overlayJSON returns a copy of base with the fields named by the JSON keys
+// taken from src. Fields not listed keep the value they have in base, so
+// values set on the server survive an update that does not manage them.
+func overlayJSON[T any](base, src *T, keys []string) (*T, error) {
+	var baseFields, srcFields map[string]json.RawMessage
+
+	baseBytes, err := json.Marshal(base)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(baseBytes, &baseFields); err != nil {
+		return nil, err
+	}
+
+	srcBytes, err := json.Marshal(src)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(srcBytes, &srcFields); err != nil {
+		return nil, err
+	}
+
+	for _, key := range keys {
+		if v, ok := srcFields[key]; ok {
+			baseFields[key] = v
+		}
+	}
+
+	mergedBytes, err := json.Marshal(baseFields)
+	if err != nil {
+		return nil, err
+	}
+
+	merged := new(T)
+	if err := json.Unmarshal(mergedBytes, merged); err != nil {
+		return nil, err
+	}
+
+	return merged, nil
+}
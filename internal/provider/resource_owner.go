@@ -0,0 +1,102 @@
+// Copyright (c) HashiCorp, Inc.
+
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// ownerAttribute returns the owner schema attribute for a resource managing
+// objects of the given kind. Left out of configuration, the owner is the
+// provider's default_owner, planned by planDefaultOwner.
+func ownerAttribute(kind string) schema.StringAttribute {
+	return schema.StringAttribute{
+		Description: fmt.Sprintf("The organization that owns this %s. Defaults to the provider's default_owner.", kind),
+		Optional:    true,
+		Computed:    true,
+		PlanModifiers: []planmodifier.String{
+			stringplanmodifier.UseStateForUnknown(),
+			stringplanmodifier.RequiresReplace(),
+		},
+	}
+}
+
+// adminOwnerAttribute returns the owner schema attribute for a resource
+// managing objects of a kind that Casdoor owns by "admin", whatever
+// organization they belong to. See docs/casdoor-server-quirks.md.
+func adminOwnerAttribute(kind string) schema.StringAttribute {
+	return schema.StringAttribute{
+		Description: fmt.Sprintf("The owner of the %s. Defaults to 'admin'.", kind),
+		Optional:    true,
+		Computed:    true,
+		Default:     stringdefault.StaticString("admin"),
+		PlanModifiers: []planmodifier.String{
+			stringplanmodifier.RequiresReplace(),
+		},
+	}
+}
+
+// planDefaultOwner is called from ModifyPlan of resources whose ID is
+// 'owner/name', before anything else that needs the owner. It plans the owner
+// as planOwner does, and then the ID, so that it is known at plan time.
+func planDefaultOwner(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse, data *CasdoorProviderData) {
+	planOwner(ctx, req, resp, data)
+	if resp.Diagnostics.HasError() || resp.Plan.Raw.IsNull() {
+		return
+	}
+
+	var id, owner, name types.String
+	resp.Diagnostics.Append(resp.Plan.GetAttribute(ctx, path.Root("id"), &id)...)
+	resp.Diagnostics.Append(resp.Plan.GetAttribute(ctx, path.Root("owner"), &owner)...)
+	resp.Diagnostics.Append(resp.Plan.GetAttribute(ctx, path.Root("name"), &name)...)
+	if resp.Diagnostics.HasError() || !id.IsUnknown() || owner.IsNull() || owner.IsUnknown() || name.IsNull() || name.IsUnknown() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("id"), owner.ValueString()+"/"+name.ValueString())...)
+}
+
+// planOwner is called from ModifyPlan, before anything else that needs the
+// owner. When owner is left out of configuration, it plans the provider's
+// default_owner. A changed default_owner replaces the object, as a changed
+// owner does.
+func planOwner(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse, data *CasdoorProviderData) {
+	if data == nil || req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var configured types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("owner"), &configured)...)
+	if resp.Diagnostics.HasError() || !configured.IsNull() {
+		return
+	}
+
+	if data.DefaultOwner == "" {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("owner"),
+			"Missing Owner",
+			"owner must be set unless default_owner is set in the provider configuration.",
+		)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("owner"), data.DefaultOwner)...)
+	if req.State.Raw.IsNull() {
+		return
+	}
+
+	var current types.String
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("owner"), &current)...)
+	if current.ValueString() != data.DefaultOwner {
+		resp.RequiresReplace = append(resp.RequiresReplace, path.Root("owner"))
+	}
+}
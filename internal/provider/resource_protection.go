@@ -0,0 +1,98 @@
+// Copyright (c) HashiCorp, Inc.
+
+package provider
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+const (
+	// builtInProtectionDelete refuses to delete built-in objects.
+	builtInProtectionDelete = "delete"
+	// builtInProtectionAll refuses to delete or modify built-in objects.
+	builtInProtectionAll = "all"
+	// builtInProtectionNone allows built-in objects to be deleted and modified.
+	builtInProtectionNone = "none"
+)
+
+// builtInObjects lists, by kind, the IDs of the objects Casdoor itself
+// depends on. Losing any of them locks everybody out of Casdoor.
+var builtInObjects = map[string][]string{
+	"organization": {"admin/built-in"},
+	"application":  {"admin/app-built-in"},
+	"certificate":  {"admin/cert-built-in"},
+	"user":         {"built-in/admin"},
+}
+
+func isBuiltInObject(kind, id string) bool {
+	for _, builtIn := range builtInObjects[kind] {
+		if id == builtIn {
+			return true
+		}
+	}
+	return false
+}
+
+// deletionProtectionAttribute returns the deletion_protection schema
+// attribute for a resource managing objects of the given kind.
+func deletionProtectionAttribute(kind string) schema.BoolAttribute {
+	return schema.BoolAttribute{
+		Description: fmt.Sprintf("Whether Terraform is prevented from deleting the %s. While true, destroying or "+
+			"replacing the %s fails; set it to false and apply before removing the resource.", kind, kind),
+		Optional: true,
+		Computed: true,
+		Default:  booldefault.StaticBool(false),
+	}
+}
+
+// checkDeletionProtection records an error and returns true if the object
+// about to be deleted has deletion_protection set.
+func checkDeletionProtection(diags *diag.Diagnostics, protected types.Bool, kind, id string) bool {
+	if !protected.ValueBool() {
+		return false
+	}
+
+	diags.AddError(
+		"Deletion Protection Enabled",
+		fmt.Sprintf("The %s %q has deletion_protection set and cannot be deleted. "+
+			"Set deletion_protection = false and apply before destroying or replacing it.", kind, id),
+	)
+	return true
+}
+
+// checkBuiltInDelete records an error and returns true if the object about
+// to be deleted is a built-in object and the provider protects those.
+func checkBuiltInDelete(data *CasdoorProviderData, diags *diag.Diagnostics, kind, id string) bool {
+	if !isBuiltInObject(kind, id) || (data != nil && data.BuiltInProtection == builtInProtectionNone) {
+		return false
+	}
+
+	diags.AddError(
+		"Built-in Object Protected",
+		fmt.Sprintf("The %s %q is a Casdoor built-in object and deleting it would lock everybody out of Casdoor. "+
+			"Remove it from state with 'terraform state rm' instead, or set built_in_protection = %q in the provider "+
+			"configuration to allow deleting it.", kind, id, builtInProtectionNone),
+	)
+	return true
+}
+
+// checkBuiltInModify records an error and returns true if the object about
+// to be changed is a built-in object and the provider protects those from
+// changes as well.
+func checkBuiltInModify(data *CasdoorProviderData, diags *diag.Diagnostics, kind, id string) bool {
+	if !isBuiltInObject(kind, id) || data == nil || data.BuiltInProtection != builtInProtectionAll {
+		return false
+	}
+
+	diags.AddError(
+		"Built-in Object Protected",
+		fmt.Sprintf("The %s %q is a Casdoor built-in object and the provider is configured with "+
+			"built_in_protection = %q, which refuses changes to built-in objects.", kind, id, builtInProtectionAll),
+	)
+	return true
+}
@@ -0,0 +1,91 @@
+// Copyright (c) HashiCorp, Inc.
+
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestCheckBuiltInDelete(t *testing.T) {
+	tests := []struct {
+		name       string
+		data       *CasdoorProviderData
+		wantRefuse bool
+	}{
+		{name: "default", data: &CasdoorProviderData{}, wantRefuse: true},
+		{name: "unconfigured", data: nil, wantRefuse: true},
+		{name: "delete", data: &CasdoorProviderData{BuiltInProtection: builtInProtectionDelete}, wantRefuse: true},
+		{name: "all", data: &CasdoorProviderData{BuiltInProtection: builtInProtectionAll}, wantRefuse: true},
+		{name: "none", data: &CasdoorProviderData{BuiltInProtection: builtInProtectionNone}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for kind, ids := range builtInObjects {
+				for _, id := range ids {
+					var diags diag.Diagnostics
+					if got := checkBuiltInDelete(tt.data, &diags, kind, id); got != tt.wantRefuse || diags.HasError() != tt.wantRefuse {
+						t.Errorf("Expected deleting %s %q to be refused: %v, got %v (%v)", kind, id, tt.wantRefuse, got, diags)
+					}
+				}
+
+				// Objects of the same kind that are not built in are never protected.
+				var diags diag.Diagnostics
+				if checkBuiltInDelete(tt.data, &diags, kind, "admin/custom") || diags.HasError() {
+					t.Errorf("Expected deleting %s %q to be allowed, got %v", kind, "admin/custom", diags)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckBuiltInModify(t *testing.T) {
+	tests := []struct {
+		name       string
+		data       *CasdoorProviderData
+		wantRefuse bool
+	}{
+		{name: "default", data: &CasdoorProviderData{}},
+		{name: "unconfigured", data: nil},
+		{name: "delete", data: &CasdoorProviderData{BuiltInProtection: builtInProtectionDelete}},
+		{name: "all", data: &CasdoorProviderData{BuiltInProtection: builtInProtectionAll}, wantRefuse: true},
+		{name: "none", data: &CasdoorProviderData{BuiltInProtection: builtInProtectionNone}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for kind, ids := range builtInObjects {
+				for _, id := range ids {
+					var diags diag.Diagnostics
+					if got := checkBuiltInModify(tt.data, &diags, kind, id); got != tt.wantRefuse || diags.HasError() != tt.wantRefuse {
+						t.Errorf("Expected changing %s %q to be refused: %v, got %v (%v)", kind, id, tt.wantRefuse, got, diags)
+					}
+				}
+
+				var diags diag.Diagnostics
+				if checkBuiltInModify(tt.data, &diags, kind, "admin/custom") || diags.HasError() {
+					t.Errorf("Expected changing %s %q to be allowed, got %v", kind, "admin/custom", diags)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckDeletionProtection(t *testing.T) {
+	for _, tt := range []struct {
+		protected  types.Bool
+		wantRefuse bool
+	}{
+		{protected: types.BoolValue(true), wantRefuse: true},
+		{protected: types.BoolValue(false)},
+		{protected: types.BoolNull()},
+	} {
+		var diags diag.Diagnostics
+		if got := checkDeletionProtection(&diags, tt.protected, "user", "built-in/alice"); got != tt.wantRefuse || diags.HasError() != tt.wantRefuse {
+			t.Errorf("Expected deletion_protection = %s to refuse deletion: %v, got %v (%v)", tt.protected, tt.wantRefuse, got, diags)
+		}
+	}
+}
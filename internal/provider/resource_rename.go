@@ -0,0 +1,54 @@
+// Copyright (c) HashiCorp, Inc.
+
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+)
+
+// renameUpdate sends obj to the update endpoint for kind, addressed by the
+// object's current ID (owner/oldName) while obj carries newName. Once Casdoor
+// has renamed the object, the new name is recorded in resp.State straight
+// away, so that a failure later in Update leaves state pointing at the object
+// that actually exists. If the update itself fails, Casdoor is asked whether
+// the rename went through anyway. Returns true if an error was recorded.
+func renameUpdate(ctx context.Context, resp *resource.UpdateResponse, client casdoorClient, kind, owner, oldName, newName string, obj any) bool {
+	return renameUpdateWith(ctx, resp, client, kind, owner, oldName, newName, func(id string) (bool, error) {
+		return client.UpdateByID(ctx, kind, id, obj)
+	})
+}
+
+// renameUpdateWith is renameUpdate with the update call supplied by the
+// caller, for objects that are not updated through UpdateByID.
+func renameUpdateWith(ctx context.Context, resp *resource.UpdateResponse, client casdoorClient, kind, owner, oldName, newName string, update func(id string) (bool, error)) bool {
+	ok, err := update(owner + "/" + oldName)
+	if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("updating %s %q", kind, newName)) {
+		if oldName != newName {
+			if found, lookupErr := client.ObjectExists(ctx, kind, owner+"/"+newName); lookupErr == nil && found {
+				resp.Diagnostics.Append(setRenamedState(ctx, resp, owner, newName)...)
+			}
+		}
+		return true
+	}
+
+	if oldName != newName {
+		resp.Diagnostics.Append(setRenamedState(ctx, resp, owner, newName)...)
+	}
+
+	return resp.Diagnostics.HasError()
+}
+
+// setRenamedState moves the prior state of a renamed object to its new name.
+func setRenamedState(ctx context.Context, resp *resource.UpdateResponse, owner, name string) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	diags.Append(resp.State.SetAttribute(ctx, path.Root("name"), name)...)
+	diags.Append(resp.State.SetAttribute(ctx, path.Root("id"), owner+"/"+name)...)
+
+	return diags
+}
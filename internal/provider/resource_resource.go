@@ -8,6 +8,8 @@ import (
 	"fmt"
 
 	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
@@ -20,29 +22,32 @@ var (
 	_ resource.Resource                = &ResourceResource{}
 	_ resource.ResourceWithConfigure   = &ResourceResource{}
 	_ resource.ResourceWithImportState = &ResourceResource{}
+	_ resource.ResourceWithModifyPlan  = &ResourceResource{}
 )
 
 type ResourceResource struct {
-	client *casdoorsdk.Client
+	client       casdoorClient
+	providerData *CasdoorProviderData
 }
 
 type ResourceResourceModel struct {
-	ID              types.String `tfsdk:"id"`
-	Owner           types.String `tfsdk:"owner"`
-	Name            types.String `tfsdk:"name"`
-	CreatedTime     types.String `tfsdk:"created_time"`
-	User            types.String `tfsdk:"user"`
-	Tag             types.String `tfsdk:"tag"`
-	Parent          types.String `tfsdk:"parent"`
-	FileName        types.String `tfsdk:"file_name"`
-	ContentBase64   types.String `tfsdk:"content_base64"`
-	Description     types.String `tfsdk:"description"`
-	URL             types.String `tfsdk:"url"`
-	FileType        types.String `tfsdk:"file_type"`
-	FileFormat      types.String `tfsdk:"file_format"`
-	FileSize        types.Int64  `tfsdk:"file_size"`
-	StorageProvider types.String `tfsdk:"storage_provider"`
-	Application     types.String `tfsdk:"application"`
+	ID              types.String   `tfsdk:"id"`
+	Owner           types.String   `tfsdk:"owner"`
+	Name            types.String   `tfsdk:"name"`
+	CreatedTime     types.String   `tfsdk:"created_time"`
+	User            types.String   `tfsdk:"user"`
+	Tag             types.String   `tfsdk:"tag"`
+	Parent          types.String   `tfsdk:"parent"`
+	FileName        types.String   `tfsdk:"file_name"`
+	ContentBase64   types.String   `tfsdk:"content_base64"`
+	Description     types.String   `tfsdk:"description"`
+	URL             types.String   `tfsdk:"url"`
+	FileType        types.String   `tfsdk:"file_type"`
+	FileFormat      types.String   `tfsdk:"file_format"`
+	FileSize        types.Int64    `tfsdk:"file_size"`
+	StorageProvider types.String   `tfsdk:"storage_provider"`
+	Application     types.String   `tfsdk:"application"`
+	Timeouts        timeouts.Value `tfsdk:"timeouts"`
 }
 
 func NewResourceResource() resource.Resource {
@@ -53,7 +58,7 @@ func (r *ResourceResource) Metadata(_ context.Context, req resource.MetadataRequ
 	resp.TypeName = req.ProviderTypeName + "_resource"
 }
 
-func (r *ResourceResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+func (r *ResourceResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		Description: "Manages a Casdoor resource (uploaded file).",
 		Attributes: map[string]schema.Attribute{
@@ -65,10 +70,12 @@ func (r *ResourceResource) Schema(_ context.Context, _ resource.SchemaRequest, r
 				},
 			},
 			"owner": schema.StringAttribute{
-				Description: "The organization that owns this resource. Determined by the provider's organization_name.",
+				Description: "The organization that owns this resource. Defaults to the provider's default_owner, or else its organization_name.",
+				Optional:    true,
 				Computed:    true,
 				PlanModifiers: []planmodifier.String{
 					stringplanmodifier.UseStateForUnknown(),
+					stringplanmodifier.RequiresReplace(),
 				},
 			},
 			"name": schema.StringAttribute{
@@ -157,6 +164,13 @@ func (r *ResourceResource) Schema(_ context.Context, _ resource.SchemaRequest, r
 				Computed:    true,
 			},
 		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Read:   true,
+				Delete: true,
+			}),
+		},
 	}
 }
 
@@ -165,19 +179,44 @@ func (r *ResourceResource) Configure(_ context.Context, req resource.ConfigureRe
 		return
 	}
 
-	client, ok := req.ProviderData.(*casdoorsdk.Client)
+	data, ok := req.ProviderData.(*CasdoorProviderData)
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Resource Configure Type",
-			fmt.Sprintf("Expected *casdoorsdk.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected *CasdoorProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 		return
 	}
 
-	r.client = client
+	r.client = data.Client
+	r.providerData = data
+}
+
+// ModifyPlan plans the owner a new resource is uploaded to, which is the
+// provider's default_owner, or else its organization_name, unless set.
+func (r *ResourceResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if r.providerData == nil || req.Plan.Raw.IsNull() || !req.State.Raw.IsNull() {
+		return
+	}
+
+	var owner types.String
+	resp.Diagnostics.Append(resp.Plan.GetAttribute(ctx, path.Root("owner"), &owner)...)
+	if resp.Diagnostics.HasError() || !owner.IsUnknown() {
+		return
+	}
+
+	defaultOwner := r.providerData.DefaultOwner
+	if defaultOwner == "" {
+		defaultOwner = r.client.OrganizationName()
+	}
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("owner"), defaultOwner)...)
 }
 
 func (r *ResourceResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	if checkUnconfigured(r.client, &resp.Diagnostics) {
+		return
+	}
+
 	var plan ResourceResourceModel
 
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
@@ -185,6 +224,12 @@ func (r *ResourceResource) Create(ctx context.Context, req resource.CreateReques
 		return
 	}
 
+	ctx, done := startOperation(ctx, plan.Timeouts.Create, defaultOperationTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	fileBytes, err := base64.StdEncoding.DecodeString(plan.ContentBase64.ValueString())
 	if err != nil {
 		resp.Diagnostics.AddError(
@@ -194,7 +239,9 @@ func (r *ResourceResource) Create(ctx context.Context, req resource.CreateReques
 		return
 	}
 
-	fileURL, name, err := r.client.UploadResourceEx(
+	fileURL, name, err := r.client.UploadResource(
+		ctx,
+		plan.Owner.ValueString(),
 		plan.User.ValueString(),
 		plan.Tag.ValueString(),
 		plan.Parent.ValueString(),
@@ -219,10 +266,9 @@ func (r *ResourceResource) Create(ctx context.Context, req resource.CreateReques
 		return
 	}
 
-	// The owner comes from the client's OrganizationName.
-	id := r.client.OrganizationName + "/" + name
+	id := plan.Owner.ValueString() + "/" + name
 
-	created, err := r.client.GetResource(id)
+	created, err := r.client.Resources().Get(ctx, id)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Reading Resource After Create",
@@ -254,6 +300,10 @@ func (r *ResourceResource) Create(ctx context.Context, req resource.CreateReques
 }
 
 func (r *ResourceResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	if r.client == nil {
+		return
+	}
+
 	var state ResourceResourceModel
 
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
@@ -261,7 +311,13 @@ func (r *ResourceResource) Read(ctx context.Context, req resource.ReadRequest, r
 		return
 	}
 
-	res, err := r.client.GetResource(state.Owner.ValueString() + "/" + state.Name.ValueString())
+	ctx, done := startOperation(ctx, state.Timeouts.Read, defaultReadTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	res, err := r.client.Resources().Get(ctx, state.Owner.ValueString()+"/"+state.Name.ValueString())
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Reading Resource",
@@ -299,14 +355,29 @@ func (r *ResourceResource) Read(ctx context.Context, req resource.ReadRequest, r
 	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
 }
 
-func (r *ResourceResource) Update(_ context.Context, _ resource.UpdateRequest, resp *resource.UpdateResponse) {
-	resp.Diagnostics.AddError(
-		"Update Not Supported",
-		"Casdoor resources do not support updates. All attributes use RequiresReplace.",
-	)
+// Update only ever sees changes to the timeouts block, as every other
+// attribute requires replacement; Casdoor resources themselves cannot be
+// updated.
+func (r *ResourceResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	if checkUnconfigured(r.client, &resp.Diagnostics) {
+		return
+	}
+
+	var plan ResourceResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
 }
 
 func (r *ResourceResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	if checkUnconfigured(r.client, &resp.Diagnostics) {
+		return
+	}
+
 	var state ResourceResourceModel
 
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
@@ -314,12 +385,18 @@ func (r *ResourceResource) Delete(ctx context.Context, req resource.DeleteReques
 		return
 	}
 
+	ctx, done := startOperation(ctx, state.Timeouts.Delete, defaultOperationTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	res := &casdoorsdk.Resource{
 		Owner: state.Owner.ValueString(),
 		Name:  state.Name.ValueString(),
 	}
 
-	ok, err := r.client.DeleteResource(res)
+	ok, err := r.client.Resources().Delete(ctx, res)
 	if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("deleting resource %q", state.Name.ValueString())) {
 		return
 	}
@@ -8,7 +8,9 @@ import (
 	"time"
 
 	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
@@ -22,24 +24,28 @@ var (
 	_ resource.Resource                = &RoleResource{}
 	_ resource.ResourceWithConfigure   = &RoleResource{}
 	_ resource.ResourceWithImportState = &RoleResource{}
+	_ resource.ResourceWithModifyPlan  = &RoleResource{}
 )
 
 type RoleResource struct {
-	client *casdoorsdk.Client
+	client       casdoorClient
+	providerData *CasdoorProviderData
 }
 
 type RoleResourceModel struct {
-	ID          types.String `tfsdk:"id"`
-	Owner       types.String `tfsdk:"owner"`
-	Name        types.String `tfsdk:"name"`
-	CreatedTime types.String `tfsdk:"created_time"`
-	DisplayName types.String `tfsdk:"display_name"`
-	Description types.String `tfsdk:"description"`
-	Users       types.List   `tfsdk:"users"`
-	Groups      types.List   `tfsdk:"groups"`
-	Roles       types.List   `tfsdk:"roles"`
-	Domains     types.List   `tfsdk:"domains"`
-	IsEnabled   types.Bool   `tfsdk:"is_enabled"`
+	ID            types.String   `tfsdk:"id"`
+	Owner         types.String   `tfsdk:"owner"`
+	Name          types.String   `tfsdk:"name"`
+	CreatedTime   types.String   `tfsdk:"created_time"`
+	DisplayName   types.String   `tfsdk:"display_name"`
+	Description   types.String   `tfsdk:"description"`
+	Users         types.List     `tfsdk:"users"`
+	Groups        types.List     `tfsdk:"groups"`
+	Roles         types.List     `tfsdk:"roles"`
+	Domains       types.List     `tfsdk:"domains"`
+	IsEnabled     types.Bool     `tfsdk:"is_enabled"`
+	AdoptExisting types.Bool     `tfsdk:"adopt_existing"`
+	Timeouts      timeouts.Value `tfsdk:"timeouts"`
 }
 
 func NewRoleResource() resource.Resource {
@@ -50,7 +56,7 @@ func (r *RoleResource) Metadata(_ context.Context, req resource.MetadataRequest,
 	resp.TypeName = req.ProviderTypeName + "_role"
 }
 
-func (r *RoleResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+func (r *RoleResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		Description: "Manages a Casdoor role.",
 		Attributes: map[string]schema.Attribute{
@@ -58,22 +64,13 @@ func (r *RoleResource) Schema(_ context.Context, _ resource.SchemaRequest, resp
 				Description: "The ID of the role in the format 'owner/name'.",
 				Computed:    true,
 				PlanModifiers: []planmodifier.String{
-					stringplanmodifier.UseStateForUnknown(),
-				},
-			},
-			"owner": schema.StringAttribute{
-				Description: "The organization that owns this role.",
-				Required:    true,
-				PlanModifiers: []planmodifier.String{
-					stringplanmodifier.RequiresReplace(),
+					useStateUnlessChanged(path.Root("name")),
 				},
 			},
+			"owner": ownerAttribute("role"),
 			"name": schema.StringAttribute{
-				Description: "The unique name of the role.",
+				Description: "The unique name of the role. Changing it renames the role in place.",
 				Required:    true,
-				PlanModifiers: []planmodifier.String{
-					stringplanmodifier.RequiresReplace(),
-				},
 			},
 			"created_time": schema.StringAttribute{
 				Description: "The time when the role was created.",
@@ -120,6 +117,15 @@ func (r *RoleResource) Schema(_ context.Context, _ resource.SchemaRequest, resp
 				Computed:    true,
 				Default:     booldefault.StaticBool(true),
 			},
+			"adopt_existing": adoptExistingAttribute("role"),
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Read:   true,
+				Update: true,
+				Delete: true,
+			}),
 		},
 	}
 }
@@ -129,16 +135,26 @@ func (r *RoleResource) Configure(_ context.Context, req resource.ConfigureReques
 		return
 	}
 
-	client, ok := req.ProviderData.(*casdoorsdk.Client)
+	data, ok := req.ProviderData.(*CasdoorProviderData)
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Resource Configure Type",
-			fmt.Sprintf("Expected *casdoorsdk.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected *CasdoorProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 		return
 	}
 
-	r.client = client
+	r.client = data.Client
+	r.providerData = data
+}
+
+func (r *RoleResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	planDefaultOwner(ctx, req, resp, r.providerData)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	planAdoption(ctx, req, resp, r.providerData, "role", r.Read)
 }
 
 func rolePlanToSDK(ctx context.Context, plan RoleResourceModel, createdTime string) (*casdoorsdk.Role, diag.Diagnostics) {
@@ -168,6 +184,10 @@ func rolePlanToSDK(ctx context.Context, plan RoleResourceModel, createdTime stri
 }
 
 func (r *RoleResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	if checkUnconfigured(r.client, &resp.Diagnostics) {
+		return
+	}
+
 	var plan RoleResourceModel
 
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
@@ -175,6 +195,12 @@ func (r *RoleResource) Create(ctx context.Context, req resource.CreateRequest, r
 		return
 	}
 
+	ctx, done := startOperation(ctx, plan.Timeouts.Create, defaultOperationTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	createdTime := plan.CreatedTime.ValueString()
 	if createdTime == "" {
 		createdTime = time.Now().UTC().Format(time.RFC3339)
@@ -186,13 +212,20 @@ func (r *RoleResource) Create(ctx context.Context, req resource.CreateRequest, r
 		return
 	}
 
-	ok, err := r.client.AddRole(role)
-	if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("creating role %q", plan.Name.ValueString())) {
+	adopted := adoptOnCreate(ctx, r.providerData, req.Plan, req.Config, &resp.Diagnostics, "role", plan.Owner.ValueString()+"/"+plan.Name.ValueString(), role, r.client.Roles())
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
+	if !adopted {
+		ok, err := r.client.Roles().Add(ctx, role)
+		if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("creating role %q", plan.Name.ValueString())) {
+			return
+		}
+	}
+
 	// Read back the role to get server-generated values like CreatedTime.
-	createdRole, err := r.client.GetRole(plan.Owner.ValueString() + "/" + plan.Name.ValueString())
+	createdRole, err := r.client.Roles().Get(ctx, plan.Owner.ValueString()+"/"+plan.Name.ValueString())
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Reading Role",
@@ -220,6 +253,10 @@ func (r *RoleResource) Create(ctx context.Context, req resource.CreateRequest, r
 }
 
 func (r *RoleResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	if r.client == nil {
+		return
+	}
+
 	var state RoleResourceModel
 
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
@@ -227,7 +264,13 @@ func (r *RoleResource) Read(ctx context.Context, req resource.ReadRequest, resp
 		return
 	}
 
-	role, err := r.client.GetRole(state.Owner.ValueString() + "/" + state.Name.ValueString())
+	ctx, done := startOperation(ctx, state.Timeouts.Read, defaultReadTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	role, err := r.client.Roles().Get(ctx, state.Owner.ValueString()+"/"+state.Name.ValueString())
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Reading Role",
@@ -263,25 +306,40 @@ func (r *RoleResource) Read(ctx context.Context, req resource.ReadRequest, resp
 		return
 	}
 
+	resp.Diagnostics.Append(setObjectFingerprint(ctx, resp.Private, role)...)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
 }
 
 func (r *RoleResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	var plan RoleResourceModel
+	if checkUnconfigured(r.client, &resp.Diagnostics) {
+		return
+	}
+
+	var plan, state RoleResourceModel
 
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
+	ctx, done := startOperation(ctx, plan.Timeouts.Update, defaultOperationTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if checkUpdateConflict(ctx, r.providerData, &resp.Diagnostics, req.Private, "role", state.Owner.ValueString()+"/"+state.Name.ValueString(), r.client.Roles().Get) {
+		return
+	}
+
 	role, diags := rolePlanToSDK(ctx, plan, plan.CreatedTime.ValueString())
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	ok, err := r.client.UpdateRole(role)
-	if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("updating role %q", plan.Name.ValueString())) {
+	if renameUpdate(ctx, resp, r.client, "role", plan.Owner.ValueString(), state.Name.ValueString(), plan.Name.ValueString(), role) {
 		return
 	}
 
@@ -296,10 +354,15 @@ func (r *RoleResource) Update(ctx context.Context, req resource.UpdateRequest, r
 	resp.Diagnostics.Append(diags...)
 
 	plan.ID = types.StringValue(plan.Owner.ValueString() + "/" + plan.Name.ValueString())
+	resp.Diagnostics.Append(clearObjectFingerprint(ctx, resp.Private)...)
 	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
 }
 
 func (r *RoleResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	if checkUnconfigured(r.client, &resp.Diagnostics) {
+		return
+	}
+
 	var state RoleResourceModel
 
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
@@ -307,12 +370,18 @@ func (r *RoleResource) Delete(ctx context.Context, req resource.DeleteRequest, r
 		return
 	}
 
+	ctx, done := startOperation(ctx, state.Timeouts.Delete, defaultOperationTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	role := &casdoorsdk.Role{
 		Owner: state.Owner.ValueString(),
 		Name:  state.Name.ValueString(),
 	}
 
-	ok, err := r.client.DeleteRole(role)
+	ok, err := r.client.Roles().Delete(ctx, role)
 	if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("deleting role %q", state.Name.ValueString())) {
 		return
 	}
@@ -4,10 +4,18 @@ package provider
 
 import (
 	"fmt"
+	"regexp"
+	"slices"
 	"testing"
+	"time"
 
+	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
 	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
 )
 
 func TestAccRoleResource_basic(t *testing.T) {
@@ -41,6 +49,42 @@ func TestAccRoleResource_basic(t *testing.T) {
 	})
 }
 
+func TestAccRoleResource_rename(t *testing.T) {
+	config := setupTestConfig(t)
+	rName := "tf-test-" + acctest.RandStringFromCharSet(8, acctest.CharSetAlphaNum)
+	resourceName := "casdoor_role.test"
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories(config),
+		Steps: []resource.TestStep{
+			// Create the role
+			{
+				Config: testAccProviderConfig(config) + testAccRoleResourceConfig(config.OrganizationName, rName, "Test Role"),
+			},
+			// Rename in place
+			{
+				Config: testAccProviderConfig(config) + testAccRoleResourceConfig(config.OrganizationName, rName+"-renamed", "Test Role"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction(resourceName, plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "name", rName+"-renamed"),
+					resource.TestCheckResourceAttr(resourceName, "id", config.OrganizationName+"/"+rName+"-renamed"),
+				),
+			},
+			// ImportState testing under the new name
+			{
+				ResourceName:      resourceName,
+				ImportState:       true,
+				ImportStateId:     config.OrganizationName + "/" + rName + "-renamed",
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
 func TestAccRoleResource_import(t *testing.T) {
 	config := setupTestConfig(t)
 	rName := "tf-test-" + acctest.RandStringFromCharSet(8, acctest.CharSetAlphaNum)
@@ -65,6 +109,89 @@ func TestAccRoleResource_import(t *testing.T) {
 	})
 }
 
+func TestAccRoleResource_adoptExisting(t *testing.T) {
+	config := setupTestConfig(t)
+	rName := "tf-test-" + acctest.RandStringFromCharSet(8, acctest.CharSetAlphaNum)
+	resourceName := "casdoor_role.test"
+
+	// addRole creates the role outside of Terraform.
+	addRole := func() {
+		client := casdoorsdk.NewClient(
+			config.Endpoint,
+			config.ClientID,
+			config.ClientSecret,
+			config.Certificate,
+			config.OrganizationName,
+			config.ApplicationName,
+		)
+
+		_, err := client.AddRole(&casdoorsdk.Role{
+			Owner:       "built-in",
+			Name:        rName,
+			CreatedTime: time.Now().UTC().Format(time.RFC3339),
+			DisplayName: "Existing Role",
+			Description: "Created outside Terraform",
+			IsEnabled:   true,
+		})
+		if err != nil {
+			t.Fatalf("Failed to add role: %v", err)
+		}
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories(config),
+		Steps: []resource.TestStep{
+			// Adopt the existing role instead of failing to create it
+			{
+				PreConfig: addRole,
+				Config: testAccProviderConfig(config) + fmt.Sprintf(`
+resource "casdoor_role" "test" {
+  owner          = "built-in"
+  name           = %q
+  display_name   = "Adopted Role"
+  adopt_existing = true
+}
+`, rName),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction(resourceName, plancheck.ResourceActionCreate),
+						plancheck.ExpectKnownValue(resourceName, tfjsonpath.New("description"), knownvalue.StringExact("Created outside Terraform")),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "display_name", "Adopted Role"),
+					resource.TestCheckResourceAttr(resourceName, "description", "Created outside Terraform"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccRoleResource_timeouts(t *testing.T) {
+	config := setupTestConfig(t)
+	rName := "tf-test-" + acctest.RandStringFromCharSet(8, acctest.CharSetAlphaNum)
+	resourceName := "casdoor_role.test"
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories(config),
+		Steps: []resource.TestStep{
+			// A create timeout too short for any request aborts the create
+			{
+				Config:      testAccProviderConfig(config) + testAccRoleResourceTimeoutsConfig(config.OrganizationName, rName, "1ns"),
+				ExpectError: regexp.MustCompile("context deadline exceeded"),
+			},
+			// A reasonable one lets it through
+			{
+				Config: testAccProviderConfig(config) + testAccRoleResourceTimeoutsConfig(config.OrganizationName, rName, "2m"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "name", rName),
+					resource.TestCheckResourceAttr(resourceName, "timeouts.create", "2m"),
+				),
+			},
+		},
+	})
+}
+
 func testAccRoleResourceConfig(owner, name, displayName string) string {
 	return fmt.Sprintf(`
 resource "casdoor_role" "test" {
@@ -74,3 +201,86 @@ resource "casdoor_role" "test" {
 }
 `, owner, name, displayName)
 }
+
+func testAccRoleResourceTimeoutsConfig(owner, name, create string) string {
+	return fmt.Sprintf(`
+resource "casdoor_role" "test" {
+  owner        = %q
+  name         = %q
+  display_name = "Role with Timeouts"
+
+  timeouts {
+    create = %q
+  }
+}
+`, owner, name, create)
+}
+
+func TestRoleResource_fakeClient(t *testing.T) {
+	client := newFakeClient()
+	r, s := testResource(t, NewRoleResource(), client, CasdoorProviderData{})
+
+	config := testObjectValue(t, s, map[string]tftypes.Value{
+		"owner":        tftypes.NewValue(tftypes.String, "built-in"),
+		"name":         tftypes.NewValue(tftypes.String, "reader"),
+		"display_name": tftypes.NewValue(tftypes.String, "Reader"),
+		"description":  tftypes.NewValue(tftypes.String, ""),
+		"is_enabled":   tftypes.NewValue(tftypes.Bool, true),
+	})
+	state := testCreate(t, r, s, config)
+	if got := testAttribute(t, state, "id"); got != "built-in/reader" {
+		t.Errorf("Expected id %q, got %q", "built-in/reader", got)
+	}
+	if role := client.object("role", "built-in/reader"); role == nil || role["displayName"] != "Reader" {
+		t.Fatalf("Expected the role to be added with its display name, got %v", role)
+	}
+
+	// A change made in Casdoor shows up on refresh.
+	client.object("role", "built-in/reader")["displayName"] = "Changed"
+	state = testRead(t, r, state)
+	if got := testAttribute(t, state, "display_name"); got != "Changed" {
+		t.Errorf("Expected display_name %q after refresh, got %q", "Changed", got)
+	}
+
+	testDelete(t, r, state)
+	if role := client.object("role", "built-in/reader"); role != nil {
+		t.Errorf("Expected the role to be deleted, got %v", role)
+	}
+
+	var actions []string
+	for _, call := range client.calls {
+		actions = append(actions, call.Action+" "+call.ID)
+	}
+	if want := []string{"add-role built-in/reader", "delete-role built-in/reader"}; !slices.Equal(actions, want) {
+		t.Errorf("Expected calls %q, got %q", want, actions)
+	}
+}
+
+// TestRoleResource_fakeCasdoor runs the role resource against the fake
+// Casdoor server through the SDK client.
+func TestRoleResource_fakeCasdoor(t *testing.T) {
+	client, fake := newFakeSDKClient(t)
+	r, s := testResource(t, NewRoleResource(), client, CasdoorProviderData{})
+
+	state := testCreate(t, r, s, testObjectValue(t, s, map[string]tftypes.Value{
+		"owner":        tftypes.NewValue(tftypes.String, "built-in"),
+		"name":         tftypes.NewValue(tftypes.String, "writer"),
+		"display_name": tftypes.NewValue(tftypes.String, "Writer"),
+		"description":  tftypes.NewValue(tftypes.String, ""),
+		"is_enabled":   tftypes.NewValue(tftypes.Bool, true),
+	}))
+	if role := fake.object("role", "built-in/writer"); role == nil || role["displayName"] != "Writer" {
+		t.Fatalf("Expected the role to be added with its display name, got %v", role)
+	}
+
+	fake.set("role", "built-in/writer", "displayName", "Changed")
+	state = testRead(t, r, state)
+	if got := testAttribute(t, state, "display_name"); got != "Changed" {
+		t.Errorf("Expected display_name %q after refresh, got %q", "Changed", got)
+	}
+
+	testDelete(t, r, state)
+	if role := fake.object("role", "built-in/writer"); role != nil {
+		t.Errorf("Expected the role to be deleted, got %v", role)
+	}
+}
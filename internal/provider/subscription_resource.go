@@ -0,0 +1,595 @@
+// Copyright (c) HashiCorp, Inc.
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ resource.Resource                = &SubscriptionResource{}
+	_ resource.ResourceWithConfigure   = &SubscriptionResource{}
+	_ resource.ResourceWithImportState = &SubscriptionResource{}
+	_ resource.ResourceWithModifyPlan  = &SubscriptionResource{}
+)
+
+type SubscriptionResource struct {
+	client       casdoorClient
+	providerData *CasdoorProviderData
+}
+
+type SubscriptionResourceModel struct {
+	ID            types.String   `tfsdk:"id"`
+	Owner         types.String   `tfsdk:"owner"`
+	Name          types.String   `tfsdk:"name"`
+	CreatedTime   types.String   `tfsdk:"created_time"`
+	DisplayName   types.String   `tfsdk:"display_name"`
+	Description   types.String   `tfsdk:"description"`
+	User          types.String   `tfsdk:"user"`
+	Pricing       types.String   `tfsdk:"pricing"`
+	Plan          types.String   `tfsdk:"plan"`
+	Payment       types.String   `tfsdk:"payment"`
+	StartTime     types.String   `tfsdk:"start_time"`
+	EndTime       types.String   `tfsdk:"end_time"`
+	Period        types.String   `tfsdk:"period"`
+	State         types.String   `tfsdk:"state"`
+	Role          types.String   `tfsdk:"role"`
+	RoleGranted   types.Bool     `tfsdk:"role_granted"`
+	AdoptExisting types.Bool     `tfsdk:"adopt_existing"`
+	Timeouts      timeouts.Value `tfsdk:"timeouts"`
+}
+
+func NewSubscriptionResource() resource.Resource {
+	return &SubscriptionResource{}
+}
+
+func (r *SubscriptionResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_subscription"
+}
+
+func (r *SubscriptionResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Manages a Casdoor subscription, which subscribes a user to a plan of a pricing.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "The ID of the subscription in the format 'owner/name'.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"owner": ownerAttribute("subscription"),
+			"name": schema.StringAttribute{
+				Description: "The unique name of the subscription.",
+				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"created_time": schema.StringAttribute{
+				Description: "The time when the subscription was created.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"display_name": schema.StringAttribute{
+				Description: "The display name of the subscription.",
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString(""),
+			},
+			"description": schema.StringAttribute{
+				Description: "The description of the subscription.",
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString(""),
+			},
+			"user": schema.StringAttribute{
+				Description: "The name of the subscribed user, who belongs to the owner organization.",
+				Required:    true,
+			},
+			"pricing": schema.StringAttribute{
+				Description: "The name of the pricing the subscription is for.",
+				Required:    true,
+			},
+			"plan": schema.StringAttribute{
+				Description: "The name of the subscribed plan. It must be one of the plans of the pricing.",
+				Required:    true,
+			},
+			"payment": schema.StringAttribute{
+				Description: "The name of the payment the subscription was bought with, if any.",
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString(""),
+			},
+			"start_time": schema.StringAttribute{
+				Description: "The time the subscription starts, in RFC 3339 format. Defaults to the time it is created.",
+				Optional:    true,
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"end_time": schema.StringAttribute{
+				Description: "The time the subscription ends, in RFC 3339 format. Defaults to one period after start_time.",
+				Optional:    true,
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					useStateUnlessChanged(path.Root("plan"), path.Root("start_time"), path.Root("period")),
+				},
+			},
+			"period": schema.StringAttribute{
+				Description: "The billing period (e.g., 'Monthly', 'Yearly'). Defaults to the period of the plan.",
+				Optional:    true,
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					useStateUnlessChanged(path.Root("plan")),
+				},
+			},
+			"state": schema.StringAttribute{
+				Description: "The state of the subscription: 'Active', 'Pending', 'Error' or 'Suspended'. " +
+					"Casdoor moves active subscriptions between 'Upcoming', 'Active' and 'Expired' by their start and end time; " +
+					"these are not shown as changes.",
+				Optional: true,
+				Computed: true,
+				Default:  stringdefault.StaticString(string(casdoorsdk.SubStateActive)),
+			},
+			"role": schema.StringAttribute{
+				Description: "The ID of the role the plan grants, in the format 'owner/name', or empty if it grants none.",
+				Computed:    true,
+			},
+			"role_granted": schema.BoolAttribute{
+				Description: "Whether the user currently has the role the plan grants.",
+				Computed:    true,
+			},
+			"adopt_existing": adoptExistingAttribute("subscription"),
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Read:   true,
+				Update: true,
+				Delete: true,
+			}),
+		},
+	}
+}
+
+func (r *SubscriptionResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*CasdoorProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *CasdoorProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.client = data.Client
+	r.providerData = data
+}
+
+// ModifyPlan checks that the plan belongs to the pricing when both are
+// known and the pricing exists already. Create and Update check it again.
+func (r *SubscriptionResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	planDefaultOwner(ctx, req, resp, r.providerData)
+	if resp.Diagnostics.HasError() || r.providerData == nil || resp.Plan.Raw.IsNull() {
+		return
+	}
+
+	var owner, pricing, plan types.String
+	resp.Diagnostics.Append(resp.Plan.GetAttribute(ctx, path.Root("owner"), &owner)...)
+	resp.Diagnostics.Append(resp.Plan.GetAttribute(ctx, path.Root("pricing"), &pricing)...)
+	resp.Diagnostics.Append(resp.Plan.GetAttribute(ctx, path.Root("plan"), &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if !owner.IsUnknown() && !pricing.IsUnknown() && !plan.IsUnknown() {
+		resp.Diagnostics.Append(r.checkPlanInPricing(ctx, owner.ValueString(), pricing.ValueString(), plan.ValueString(), false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	planAdoption(ctx, req, resp, r.providerData, "subscription", r.Read)
+}
+
+// checkPlanInPricing reports an error unless plan is one of the plans of
+// pricing. A pricing that does not exist is an error only if mustExist is
+// set, as at plan time it may be created in the same apply.
+func (r *SubscriptionResource) checkPlanInPricing(ctx context.Context, owner, pricing, plan string, mustExist bool) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	pricingObj, err := r.client.Pricings().Get(ctx, owner+"/"+pricing)
+	if err != nil {
+		diags.AddError(
+			"Error Reading Pricing",
+			fmt.Sprintf("Could not read pricing %q to check plan %q: %s", owner+"/"+pricing, plan, err),
+		)
+		return diags
+	}
+
+	if pricingObj == nil {
+		if mustExist {
+			diags.AddAttributeError(
+				path.Root("pricing"),
+				"Pricing Not Found",
+				fmt.Sprintf("The pricing %q does not exist.", owner+"/"+pricing),
+			)
+		}
+		return diags
+	}
+
+	if !slices.Contains(pricingObj.Plans, plan) {
+		diags.AddAttributeError(
+			path.Root("plan"),
+			"Plan Not in Pricing",
+			fmt.Sprintf("The plan %q is not one of the plans of pricing %q (%s).", plan, owner+"/"+pricing, strings.Join(pricingObj.Plans, ", ")),
+		)
+	}
+
+	return diags
+}
+
+// resolvePeriod fills in the period and end time left unknown in plan: the
+// period of the subscribed plan, and one period after the start time.
+func (r *SubscriptionResource) resolvePeriod(ctx context.Context, plan *SubscriptionResourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if plan.Period.IsUnknown() {
+		planObj, err := r.client.Plans().Get(ctx, plan.Owner.ValueString()+"/"+plan.Plan.ValueString())
+		if err != nil {
+			diags.AddError(
+				"Error Reading Plan",
+				fmt.Sprintf("Could not read plan %q for its period: %s", plan.Plan.ValueString(), err),
+			)
+			return diags
+		}
+		period := ""
+		if planObj != nil {
+			period = planObj.Period
+		}
+		plan.Period = types.StringValue(period)
+	}
+	if plan.EndTime.IsUnknown() {
+		plan.EndTime = types.StringValue(subscriptionPeriodEnd(plan.StartTime.ValueString(), plan.Period.ValueString()))
+	}
+
+	return diags
+}
+
+// subscriptionPeriodEnd returns the end of a subscription starting at start
+// and lasting one period, or "" if the period is unknown or start cannot be
+// parsed.
+func subscriptionPeriodEnd(start, period string) string {
+	t, err := time.Parse(time.RFC3339, start)
+	if err != nil {
+		return ""
+	}
+
+	switch period {
+	case "Monthly":
+		return t.AddDate(0, 1, 0).Format(time.RFC3339)
+	case "Yearly":
+		return t.AddDate(1, 0, 0).Format(time.RFC3339)
+	}
+	return ""
+}
+
+// subscriptionTimedState reports whether state is one of the states Casdoor
+// moves an active subscription between as time passes.
+func subscriptionTimedState(state string) bool {
+	switch casdoorsdk.SubscriptionState(state) {
+	case casdoorsdk.SubStateActive, casdoorsdk.SubStateUpcoming, casdoorsdk.SubStateExpired:
+		return true
+	}
+	return false
+}
+
+// readRoleGrant sets role to the ID of the role the subscribed plan grants
+// and roleGranted to whether the user has it.
+func (r *SubscriptionResource) readRoleGrant(ctx context.Context, data *SubscriptionResourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	owner := data.Owner.ValueString()
+	data.Role = types.StringValue("")
+	data.RoleGranted = types.BoolValue(false)
+
+	planObj, err := r.client.Plans().Get(ctx, owner+"/"+data.Plan.ValueString())
+	if err != nil {
+		diags.AddError(
+			"Error Reading Plan",
+			fmt.Sprintf("Could not read plan %q of subscription %q: %s", data.Plan.ValueString(), data.Name.ValueString(), err),
+		)
+		return diags
+	}
+	if planObj == nil || planObj.Role == "" {
+		return diags
+	}
+
+	roleID := planObj.Role
+	if !strings.Contains(roleID, "/") {
+		roleID = owner + "/" + roleID
+	}
+	data.Role = types.StringValue(roleID)
+
+	role, err := r.client.Roles().Get(ctx, roleID)
+	if err != nil {
+		diags.AddError(
+			"Error Reading Role",
+			fmt.Sprintf("Could not read role %q of plan %q: %s", roleID, data.Plan.ValueString(), err),
+		)
+		return diags
+	}
+	if role != nil {
+		data.RoleGranted = types.BoolValue(slices.Contains(role.Users, owner+"/"+data.User.ValueString()))
+	}
+
+	return diags
+}
+
+func subscriptionPlanToSDK(plan SubscriptionResourceModel, createdTime string) *casdoorsdk.Subscription {
+	return &casdoorsdk.Subscription{
+		Owner:       plan.Owner.ValueString(),
+		Name:        plan.Name.ValueString(),
+		CreatedTime: createdTime,
+		DisplayName: plan.DisplayName.ValueString(),
+		Description: plan.Description.ValueString(),
+		User:        plan.User.ValueString(),
+		Pricing:     plan.Pricing.ValueString(),
+		Plan:        plan.Plan.ValueString(),
+		Payment:     plan.Payment.ValueString(),
+		StartTime:   plan.StartTime.ValueString(),
+		EndTime:     plan.EndTime.ValueString(),
+		Period:      plan.Period.ValueString(),
+		State:       casdoorsdk.SubscriptionState(plan.State.ValueString()),
+	}
+}
+
+func (r *SubscriptionResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	if checkUnconfigured(r.client, &resp.Diagnostics) {
+		return
+	}
+
+	var plan SubscriptionResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, done := startOperation(ctx, plan.Timeouts.Create, defaultOperationTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	owner := plan.Owner.ValueString()
+	resp.Diagnostics.Append(r.checkPlanInPricing(ctx, owner, plan.Pricing.ValueString(), plan.Plan.ValueString(), true)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	createdTime := plan.CreatedTime.ValueString()
+	if createdTime == "" {
+		createdTime = time.Now().UTC().Format(time.RFC3339)
+	}
+
+	if plan.StartTime.IsUnknown() {
+		plan.StartTime = types.StringValue(createdTime)
+	}
+	resp.Diagnostics.Append(r.resolvePeriod(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	subscription := subscriptionPlanToSDK(plan, createdTime)
+
+	adopted := adoptOnCreate(ctx, r.providerData, req.Plan, req.Config, &resp.Diagnostics, "subscription", owner+"/"+plan.Name.ValueString(), subscription, r.client.Subscriptions())
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !adopted {
+		ok, err := r.client.Subscriptions().Add(ctx, subscription)
+		if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("creating subscription %q", plan.Name.ValueString())) {
+			return
+		}
+	}
+
+	// Read back the subscription to get server-generated values.
+	createdSubscription, err := r.client.Subscriptions().Get(ctx, owner+"/"+plan.Name.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading Subscription",
+			fmt.Sprintf("Could not read subscription %q after creation: %s", plan.Name.ValueString(), err),
+		)
+		return
+	}
+
+	if createdSubscription == nil {
+		resp.Diagnostics.AddError(
+			"Error Reading Subscription",
+			fmt.Sprintf("Subscription %q not found after creation", plan.Name.ValueString()),
+		)
+		return
+	}
+
+	plan.CreatedTime = types.StringValue(createdSubscription.CreatedTime)
+	plan.StartTime = types.StringValue(createdSubscription.StartTime)
+	plan.EndTime = types.StringValue(createdSubscription.EndTime)
+	plan.Period = types.StringValue(createdSubscription.Period)
+
+	resp.Diagnostics.Append(r.readRoleGrant(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	plan.ID = types.StringValue(owner + "/" + plan.Name.ValueString())
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+}
+
+func (r *SubscriptionResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	if r.client == nil {
+		return
+	}
+
+	var state SubscriptionResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, done := startOperation(ctx, state.Timeouts.Read, defaultReadTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	subscription, err := r.client.Subscriptions().Get(ctx, state.Owner.ValueString()+"/"+state.Name.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading Subscription",
+			fmt.Sprintf("Could not read subscription %q: %s", state.Name.ValueString(), err),
+		)
+		return
+	}
+
+	if subscription == nil {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	state.ID = types.StringValue(subscription.Owner + "/" + subscription.Name)
+	state.Owner = types.StringValue(subscription.Owner)
+	state.Name = types.StringValue(subscription.Name)
+	state.CreatedTime = types.StringValue(subscription.CreatedTime)
+	state.DisplayName = types.StringValue(subscription.DisplayName)
+	state.Description = types.StringValue(subscription.Description)
+	state.User = types.StringValue(subscription.User)
+	state.Pricing = types.StringValue(subscription.Pricing)
+	state.Plan = types.StringValue(subscription.Plan)
+	state.Payment = types.StringValue(subscription.Payment)
+	state.StartTime = types.StringValue(subscription.StartTime)
+	state.EndTime = types.StringValue(subscription.EndTime)
+	state.Period = types.StringValue(subscription.Period)
+
+	// Casdoor moves active subscriptions between the timed states on its
+	// own; keep the state as last written unless it left them.
+	serverState := string(subscription.State)
+	if state.State.IsNull() || !subscriptionTimedState(serverState) || !subscriptionTimedState(state.State.ValueString()) {
+		state.State = types.StringValue(serverState)
+	}
+
+	resp.Diagnostics.Append(r.readRoleGrant(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(setObjectFingerprint(ctx, resp.Private, subscription)...)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+func (r *SubscriptionResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	if checkUnconfigured(r.client, &resp.Diagnostics) {
+		return
+	}
+
+	var plan, state SubscriptionResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, done := startOperation(ctx, plan.Timeouts.Update, defaultOperationTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if checkUpdateConflict(ctx, r.providerData, &resp.Diagnostics, req.Private, "subscription", state.Owner.ValueString()+"/"+state.Name.ValueString(), r.client.Subscriptions().Get) {
+		return
+	}
+
+	resp.Diagnostics.Append(r.checkPlanInPricing(ctx, plan.Owner.ValueString(), plan.Pricing.ValueString(), plan.Plan.ValueString(), true)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.resolvePeriod(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	subscription := subscriptionPlanToSDK(plan, plan.CreatedTime.ValueString())
+
+	ok, err := r.client.Subscriptions().Update(ctx, subscription)
+	if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("updating subscription %q", plan.Name.ValueString())) {
+		return
+	}
+
+	resp.Diagnostics.Append(r.readRoleGrant(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	plan.ID = types.StringValue(plan.Owner.ValueString() + "/" + plan.Name.ValueString())
+	resp.Diagnostics.Append(clearObjectFingerprint(ctx, resp.Private)...)
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+}
+
+func (r *SubscriptionResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	if checkUnconfigured(r.client, &resp.Diagnostics) {
+		return
+	}
+
+	var state SubscriptionResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, done := startOperation(ctx, state.Timeouts.Delete, defaultOperationTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	subscription := &casdoorsdk.Subscription{
+		Owner: state.Owner.ValueString(),
+		Name:  state.Name.ValueString(),
+	}
+
+	ok, err := r.client.Subscriptions().Delete(ctx, subscription)
+	if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("deleting subscription %q", state.Name.ValueString())) {
+		return
+	}
+}
+
+func (r *SubscriptionResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	importStateOwnerName(ctx, req, resp)
+}
@@ -0,0 +1,230 @@
+// Copyright (c) HashiCorp, Inc.
+
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccSubscriptionResource_basic(t *testing.T) {
+	config := setupTestConfig(t)
+	enableBuiltInUserCreation(t, config)
+	rName := "tf-test-" + acctest.RandStringFromCharSet(8, acctest.CharSetAlphaNum)
+	resourceName := "casdoor_subscription.test"
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories(config),
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: testAccProviderConfig(config) + testAccSubscriptionResourceConfig(rName, "Test Subscription", "Active"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "name", rName),
+					resource.TestCheckResourceAttr(resourceName, "owner", "built-in"),
+					resource.TestCheckResourceAttr(resourceName, "display_name", "Test Subscription"),
+					resource.TestCheckResourceAttr(resourceName, "user", rName),
+					resource.TestCheckResourceAttr(resourceName, "pricing", rName),
+					resource.TestCheckResourceAttr(resourceName, "plan", rName),
+					resource.TestCheckResourceAttr(resourceName, "period", "Monthly"),
+					resource.TestCheckResourceAttr(resourceName, "state", "Active"),
+					resource.TestCheckResourceAttr(resourceName, "role", "built-in/"+rName),
+					resource.TestCheckResourceAttr(resourceName, "role_granted", "false"),
+					resource.TestCheckResourceAttrSet(resourceName, "start_time"),
+					resource.TestCheckResourceAttrSet(resourceName, "end_time"),
+					resource.TestCheckResourceAttrSet(resourceName, "created_time"),
+				),
+			},
+			// Update and Read testing
+			{
+				Config: testAccProviderConfig(config) + testAccSubscriptionResourceConfig(rName, "Updated Subscription", "Suspended"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "display_name", "Updated Subscription"),
+					resource.TestCheckResourceAttr(resourceName, "state", "Suspended"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccSubscriptionResource_changePlan(t *testing.T) {
+	config := setupTestConfig(t)
+	enableBuiltInUserCreation(t, config)
+	rName := "tf-test-" + acctest.RandStringFromCharSet(8, acctest.CharSetAlphaNum)
+	resourceName := "casdoor_subscription.test"
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories(config),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(config) + testAccSubscriptionResourceChangePlanConfig(rName, "casdoor_plan.test.name"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "period", "Monthly"),
+				),
+			},
+			// Left out of configuration, the period follows the new plan.
+			{
+				Config: testAccProviderConfig(config) + testAccSubscriptionResourceChangePlanConfig(rName, "casdoor_plan.yearly.name"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "plan", rName+"-yearly"),
+					resource.TestCheckResourceAttr(resourceName, "period", "Yearly"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccSubscriptionResource_planNotInPricing(t *testing.T) {
+	config := setupTestConfig(t)
+	rName := "tf-test-" + acctest.RandStringFromCharSet(8, acctest.CharSetAlphaNum)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories(config),
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccProviderConfig(config) + testAccSubscriptionResourcePlanNotInPricingConfig(rName),
+				ExpectError: regexp.MustCompile("Plan Not in Pricing"),
+			},
+		},
+	})
+}
+
+func TestAccSubscriptionResource_import(t *testing.T) {
+	config := setupTestConfig(t)
+	enableBuiltInUserCreation(t, config)
+	rName := "tf-test-" + acctest.RandStringFromCharSet(8, acctest.CharSetAlphaNum)
+	resourceName := "casdoor_subscription.test"
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories(config),
+		Steps: []resource.TestStep{
+			// Create the resource first
+			{
+				Config: testAccProviderConfig(config) + testAccSubscriptionResourceConfig(rName, "Test Subscription", "Active"),
+			},
+			// ImportState testing
+			{
+				Config:            testAccProviderConfig(config) + testAccSubscriptionResourceConfig(rName, "Test Subscription", "Active"),
+				ResourceName:      resourceName,
+				ImportState:       true,
+				ImportStateId:     "built-in/" + rName,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+// testAccSubscriptionResourcePricingConfig returns a user and a pricing with
+// a monthly plan that grants a role, all named name.
+func testAccSubscriptionResourcePricingConfig(name string) string {
+	return fmt.Sprintf(`
+resource "casdoor_user" "test" {
+  owner = "built-in"
+  name  = %[1]q
+}
+
+resource "casdoor_role" "test" {
+  owner = "built-in"
+  name  = %[1]q
+}
+
+resource "casdoor_plan" "test" {
+  owner    = "built-in"
+  name     = %[1]q
+  currency = "USD"
+  period   = "Monthly"
+  role     = casdoor_role.test.name
+}
+
+resource "casdoor_pricing" "test" {
+  owner = "built-in"
+  name  = %[1]q
+  plans = [casdoor_plan.test.name]
+}
+`, name)
+}
+
+func testAccSubscriptionResourceConfig(name, displayName, state string) string {
+	return testAccSubscriptionResourcePricingConfig(name) + fmt.Sprintf(`
+resource "casdoor_subscription" "test" {
+  owner        = "built-in"
+  name         = %q
+  display_name = %q
+  user         = casdoor_user.test.name
+  pricing      = casdoor_pricing.test.name
+  plan         = casdoor_plan.test.name
+  state        = %q
+}
+`, name, displayName, state)
+}
+
+func testAccSubscriptionResourcePlanNotInPricingConfig(name string) string {
+	return fmt.Sprintf(`
+resource "casdoor_plan" "test" {
+  owner    = "built-in"
+  name     = %[1]q
+  currency = "USD"
+}
+
+resource "casdoor_pricing" "test" {
+  owner = "built-in"
+  name  = %[1]q
+}
+
+resource "casdoor_subscription" "test" {
+  owner   = "built-in"
+  name    = %[1]q
+  user    = "admin"
+  pricing = casdoor_pricing.test.name
+  plan    = casdoor_plan.test.name
+}
+`, name)
+}
+
+func testAccSubscriptionResourceChangePlanConfig(name, plan string) string {
+	return fmt.Sprintf(`
+resource "casdoor_user" "test" {
+  owner = "built-in"
+  name  = %[1]q
+}
+
+resource "casdoor_role" "test" {
+  owner = "built-in"
+  name  = %[1]q
+}
+
+resource "casdoor_plan" "test" {
+  owner    = "built-in"
+  name     = %[1]q
+  currency = "USD"
+  period   = "Monthly"
+  role     = casdoor_role.test.name
+}
+
+resource "casdoor_plan" "yearly" {
+  owner    = "built-in"
+  name     = "%[1]s-yearly"
+  currency = "USD"
+  period   = "Yearly"
+  role     = casdoor_role.test.name
+}
+
+resource "casdoor_pricing" "test" {
+  owner = "built-in"
+  name  = %[1]q
+  plans = [casdoor_plan.test.name, casdoor_plan.yearly.name]
+}
+
+resource "casdoor_subscription" "test" {
+  owner   = "built-in"
+  name    = %[1]q
+  user    = casdoor_user.test.name
+  pricing = casdoor_pricing.test.name
+  plan    = %[2]s
+}
+`, name, plan)
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
 	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
@@ -25,10 +26,12 @@ var (
 	_ resource.Resource                = &SyncerResource{}
 	_ resource.ResourceWithConfigure   = &SyncerResource{}
 	_ resource.ResourceWithImportState = &SyncerResource{}
+	_ resource.ResourceWithModifyPlan  = &SyncerResource{}
 )
 
 type SyncerResource struct {
-	client *casdoorsdk.Client
+	client       casdoorClient
+	providerData *CasdoorProviderData
 }
 
 type TableColumnModel struct {
@@ -41,33 +44,35 @@ type TableColumnModel struct {
 }
 
 type SyncerResourceModel struct {
-	ID               types.String `tfsdk:"id"`
-	Owner            types.String `tfsdk:"owner"`
-	Name             types.String `tfsdk:"name"`
-	CreatedTime      types.String `tfsdk:"created_time"`
-	Organization     types.String `tfsdk:"organization"`
-	Type             types.String `tfsdk:"type"`
-	Host             types.String `tfsdk:"host"`
-	Port             types.Int64  `tfsdk:"port"`
-	User             types.String `tfsdk:"user"`
-	Password         types.String `tfsdk:"password"`
-	DatabaseType     types.String `tfsdk:"database_type"`
-	SslMode          types.String `tfsdk:"ssl_mode"`
-	SshType          types.String `tfsdk:"ssh_type"`
-	SshHost          types.String `tfsdk:"ssh_host"`
-	SshPort          types.Int64  `tfsdk:"ssh_port"`
-	SshUser          types.String `tfsdk:"ssh_user"`
-	SshPassword      types.String `tfsdk:"ssh_password"`
-	Cert             types.String `tfsdk:"cert"`
-	Database         types.String `tfsdk:"database"`
-	Table            types.String `tfsdk:"table"`
-	TableColumns     types.List   `tfsdk:"table_columns"`
-	AffiliationTable types.String `tfsdk:"affiliation_table"`
-	AvatarBaseUrl    types.String `tfsdk:"avatar_base_url"`
-	ErrorText        types.String `tfsdk:"error_text"`
-	SyncInterval     types.Int64  `tfsdk:"sync_interval"`
-	IsReadOnly       types.Bool   `tfsdk:"is_read_only"`
-	IsEnabled        types.Bool   `tfsdk:"is_enabled"`
+	ID               types.String   `tfsdk:"id"`
+	Owner            types.String   `tfsdk:"owner"`
+	Name             types.String   `tfsdk:"name"`
+	CreatedTime      types.String   `tfsdk:"created_time"`
+	Organization     types.String   `tfsdk:"organization"`
+	Type             types.String   `tfsdk:"type"`
+	Host             types.String   `tfsdk:"host"`
+	Port             types.Int64    `tfsdk:"port"`
+	User             types.String   `tfsdk:"user"`
+	Password         types.String   `tfsdk:"password"`
+	DatabaseType     types.String   `tfsdk:"database_type"`
+	SslMode          types.String   `tfsdk:"ssl_mode"`
+	SshType          types.String   `tfsdk:"ssh_type"`
+	SshHost          types.String   `tfsdk:"ssh_host"`
+	SshPort          types.Int64    `tfsdk:"ssh_port"`
+	SshUser          types.String   `tfsdk:"ssh_user"`
+	SshPassword      types.String   `tfsdk:"ssh_password"`
+	Cert             types.String   `tfsdk:"cert"`
+	Database         types.String   `tfsdk:"database"`
+	Table            types.String   `tfsdk:"table"`
+	TableColumns     types.List     `tfsdk:"table_columns"`
+	AffiliationTable types.String   `tfsdk:"affiliation_table"`
+	AvatarBaseUrl    types.String   `tfsdk:"avatar_base_url"`
+	ErrorText        types.String   `tfsdk:"error_text"`
+	SyncInterval     types.Int64    `tfsdk:"sync_interval"`
+	IsReadOnly       types.Bool     `tfsdk:"is_read_only"`
+	IsEnabled        types.Bool     `tfsdk:"is_enabled"`
+	AdoptExisting    types.Bool     `tfsdk:"adopt_existing"`
+	Timeouts         timeouts.Value `tfsdk:"timeouts"`
 }
 
 func NewSyncerResource() resource.Resource {
@@ -87,7 +92,7 @@ var tableColumnAttrTypes = map[string]attr.Type{
 	"values":       types.ListType{ElemType: types.StringType},
 }
 
-func (r *SyncerResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+func (r *SyncerResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		Description: "Manages a Casdoor syncer for external system synchronization.",
 		Attributes: map[string]schema.Attribute{
@@ -98,13 +103,7 @@ func (r *SyncerResource) Schema(_ context.Context, _ resource.SchemaRequest, res
 					stringplanmodifier.UseStateForUnknown(),
 				},
 			},
-			"owner": schema.StringAttribute{
-				Description: "The organization that owns this syncer.",
-				Required:    true,
-				PlanModifiers: []planmodifier.String{
-					stringplanmodifier.RequiresReplace(),
-				},
-			},
+			"owner": ownerAttribute("syncer"),
 			"name": schema.StringAttribute{
 				Description: "The unique name of the syncer.",
 				Required:    true,
@@ -300,6 +299,15 @@ func (r *SyncerResource) Schema(_ context.Context, _ resource.SchemaRequest, res
 				Computed:    true,
 				Default:     booldefault.StaticBool(true),
 			},
+			"adopt_existing": adoptExistingAttribute("syncer"),
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Read:   true,
+				Update: true,
+				Delete: true,
+			}),
 		},
 	}
 }
@@ -309,16 +317,26 @@ func (r *SyncerResource) Configure(_ context.Context, req resource.ConfigureRequ
 		return
 	}
 
-	client, ok := req.ProviderData.(*casdoorsdk.Client)
+	data, ok := req.ProviderData.(*CasdoorProviderData)
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Resource Configure Type",
-			fmt.Sprintf("Expected *casdoorsdk.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected *CasdoorProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 		return
 	}
 
-	r.client = client
+	r.client = data.Client
+	r.providerData = data
+}
+
+func (r *SyncerResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	planDefaultOwner(ctx, req, resp, r.providerData)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	planAdoption(ctx, req, resp, r.providerData, "syncer", r.Read)
 }
 
 func syncerTableColumnsToSDK(ctx context.Context, plan SyncerResourceModel) ([]*casdoorsdk.TableColumn, error) {
@@ -426,6 +444,10 @@ func syncerPlanToSDK(ctx context.Context, plan SyncerResourceModel, createdTime
 }
 
 func (r *SyncerResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	if checkUnconfigured(r.client, &resp.Diagnostics) {
+		return
+	}
+
 	var plan SyncerResourceModel
 
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
@@ -433,6 +455,12 @@ func (r *SyncerResource) Create(ctx context.Context, req resource.CreateRequest,
 		return
 	}
 
+	ctx, done := startOperation(ctx, plan.Timeouts.Create, defaultOperationTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	createdTime := plan.CreatedTime.ValueString()
 	if createdTime == "" {
 		createdTime = time.Now().UTC().Format(time.RFC3339)
@@ -444,13 +472,20 @@ func (r *SyncerResource) Create(ctx context.Context, req resource.CreateRequest,
 		return
 	}
 
-	ok, err := r.client.AddSyncer(syncer)
-	if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("creating syncer %q", plan.Name.ValueString())) {
+	adopted := adoptOnCreate(ctx, r.providerData, req.Plan, req.Config, &resp.Diagnostics, "syncer", plan.Owner.ValueString()+"/"+plan.Name.ValueString(), syncer, r.client.Syncers())
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
+	if !adopted {
+		ok, err := r.client.Syncers().Add(ctx, syncer)
+		if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("creating syncer %q", plan.Name.ValueString())) {
+			return
+		}
+	}
+
 	// Read back the syncer to get server-generated values.
-	createdSyncer, err := r.client.GetSyncer(plan.Owner.ValueString() + "/" + plan.Name.ValueString())
+	createdSyncer, err := r.client.Syncers().Get(ctx, plan.Owner.ValueString()+"/"+plan.Name.ValueString())
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Reading Syncer",
@@ -475,6 +510,10 @@ func (r *SyncerResource) Create(ctx context.Context, req resource.CreateRequest,
 }
 
 func (r *SyncerResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	if r.client == nil {
+		return
+	}
+
 	var state SyncerResourceModel
 
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
@@ -482,7 +521,13 @@ func (r *SyncerResource) Read(ctx context.Context, req resource.ReadRequest, res
 		return
 	}
 
-	syncer, err := r.client.GetSyncer(state.Owner.ValueString() + "/" + state.Name.ValueString())
+	ctx, done := startOperation(ctx, state.Timeouts.Read, defaultReadTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	syncer, err := r.client.Syncers().Get(ctx, state.Owner.ValueString()+"/"+state.Name.ValueString())
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Reading Syncer",
@@ -536,39 +581,60 @@ func (r *SyncerResource) Read(ctx context.Context, req resource.ReadRequest, res
 	}
 	state.TableColumns = tableColumnsList
 
+	resp.Diagnostics.Append(setObjectFingerprint(ctx, resp.Private, syncer)...)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
 }
 
 func (r *SyncerResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	var plan SyncerResourceModel
+	if checkUnconfigured(r.client, &resp.Diagnostics) {
+		return
+	}
+
+	var plan, state SyncerResourceModel
 
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
+	ctx, done := startOperation(ctx, plan.Timeouts.Update, defaultOperationTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if checkUpdateConflict(ctx, r.providerData, &resp.Diagnostics, req.Private, "syncer", state.Owner.ValueString()+"/"+state.Name.ValueString(), r.client.Syncers().Get) {
+		return
+	}
+
 	syncer, diags := syncerPlanToSDK(ctx, plan, plan.CreatedTime.ValueString())
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	ok, err := r.client.UpdateSyncer(syncer)
+	ok, err := r.client.Syncers().Update(ctx, syncer)
 	if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("updating syncer %q", plan.Name.ValueString())) {
 		return
 	}
 
 	// Read back to get error_text if any.
-	updatedSyncer, err := r.client.GetSyncer(plan.Owner.ValueString() + "/" + plan.Name.ValueString())
+	updatedSyncer, err := r.client.Syncers().Get(ctx, plan.Owner.ValueString()+"/"+plan.Name.ValueString())
 	if err == nil && updatedSyncer != nil {
 		plan.ErrorText = types.StringValue(updatedSyncer.ErrorText)
 	}
 
 	plan.ID = types.StringValue(plan.Owner.ValueString() + "/" + plan.Name.ValueString())
+	resp.Diagnostics.Append(clearObjectFingerprint(ctx, resp.Private)...)
 	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
 }
 
 func (r *SyncerResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	if checkUnconfigured(r.client, &resp.Diagnostics) {
+		return
+	}
+
 	var state SyncerResourceModel
 
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
@@ -576,12 +642,18 @@ func (r *SyncerResource) Delete(ctx context.Context, req resource.DeleteRequest,
 		return
 	}
 
+	ctx, done := startOperation(ctx, state.Timeouts.Delete, defaultOperationTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	syncer := &casdoorsdk.Syncer{
 		Owner: state.Owner.ValueString(),
 		Name:  state.Name.ValueString(),
 	}
 
-	_, err := r.client.DeleteSyncer(syncer)
+	_, err := r.client.Syncers().Delete(ctx, syncer)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Deleting Syncer",
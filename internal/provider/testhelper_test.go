@@ -71,10 +71,12 @@ type CasdoorTestConfig struct {
 	ApplicationName  string
 }
 
-// TestEnv holds the test environment including optional container.
+// TestEnv holds the test environment including optional container or fake
+// server.
 type TestEnv struct {
 	Config    CasdoorTestConfig
 	Container testcontainers.Container
+	Fake      *fakeCasdoor
 }
 
 // useLocalContainer returns true if tests should use a local Docker container
@@ -84,13 +86,18 @@ func useLocalContainer() bool {
 }
 
 // setupTestEnv sets up the test environment. If CASDOOR_TEST_LOCAL=1, it starts
-// a local Casdoor container. Otherwise, it uses the demo server.
+// a local Casdoor container for full fidelity. Otherwise, in short mode, it
+// starts an in-process fake Casdoor server, and it uses the demo server if
+// not.
 func setupTestEnv(ctx context.Context, t *testing.T) *TestEnv {
 	t.Helper()
 
 	if useLocalContainer() {
 		return setupLocalContainer(ctx, t)
 	}
+	if testing.Short() {
+		return setupFakeCasdoor(t)
+	}
 
 	return &TestEnv{
 		Config: getDemoConfig(),
@@ -106,6 +113,9 @@ func cleanupTestEnv(ctx context.Context, t *testing.T, env *TestEnv) {
 			t.Logf("Failed to terminate container: %v", err)
 		}
 	}
+	if env.Fake != nil {
+		env.Fake.Close()
+	}
 }
 
 // setupLocalContainer starts a local Casdoor container and returns the test environment.
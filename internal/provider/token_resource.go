@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
@@ -22,32 +23,36 @@ var (
 	_ resource.Resource                = &TokenResource{}
 	_ resource.ResourceWithConfigure   = &TokenResource{}
 	_ resource.ResourceWithImportState = &TokenResource{}
+	_ resource.ResourceWithModifyPlan  = &TokenResource{}
 )
 
 type TokenResource struct {
-	client *casdoorsdk.Client
+	client       casdoorClient
+	providerData *CasdoorProviderData
 }
 
 type TokenResourceModel struct {
-	ID               types.String `tfsdk:"id"`
-	Owner            types.String `tfsdk:"owner"`
-	Name             types.String `tfsdk:"name"`
-	CreatedTime      types.String `tfsdk:"created_time"`
-	Application      types.String `tfsdk:"application"`
-	Organization     types.String `tfsdk:"organization"`
-	User             types.String `tfsdk:"user"`
-	Code             types.String `tfsdk:"code"`
-	AccessToken      types.String `tfsdk:"access_token"`
-	RefreshToken     types.String `tfsdk:"refresh_token"`
-	AccessTokenHash  types.String `tfsdk:"access_token_hash"`
-	RefreshTokenHash types.String `tfsdk:"refresh_token_hash"`
-	ExpiresIn        types.Int64  `tfsdk:"expires_in"`
-	Scope            types.String `tfsdk:"scope"`
-	TokenType        types.String `tfsdk:"token_type"`
-	CodeChallenge    types.String `tfsdk:"code_challenge"`
-	CodeIsUsed       types.Bool   `tfsdk:"code_is_used"`
-	CodeExpireIn     types.Int64  `tfsdk:"code_expire_in"`
-	Resource         types.String `tfsdk:"resource"`
+	ID               types.String   `tfsdk:"id"`
+	Owner            types.String   `tfsdk:"owner"`
+	Name             types.String   `tfsdk:"name"`
+	CreatedTime      types.String   `tfsdk:"created_time"`
+	Application      types.String   `tfsdk:"application"`
+	Organization     types.String   `tfsdk:"organization"`
+	User             types.String   `tfsdk:"user"`
+	Code             types.String   `tfsdk:"code"`
+	AccessToken      types.String   `tfsdk:"access_token"`
+	RefreshToken     types.String   `tfsdk:"refresh_token"`
+	AccessTokenHash  types.String   `tfsdk:"access_token_hash"`
+	RefreshTokenHash types.String   `tfsdk:"refresh_token_hash"`
+	ExpiresIn        types.Int64    `tfsdk:"expires_in"`
+	Scope            types.String   `tfsdk:"scope"`
+	TokenType        types.String   `tfsdk:"token_type"`
+	CodeChallenge    types.String   `tfsdk:"code_challenge"`
+	CodeIsUsed       types.Bool     `tfsdk:"code_is_used"`
+	CodeExpireIn     types.Int64    `tfsdk:"code_expire_in"`
+	Resource         types.String   `tfsdk:"resource"`
+	AdoptExisting    types.Bool     `tfsdk:"adopt_existing"`
+	Timeouts         timeouts.Value `tfsdk:"timeouts"`
 }
 
 func NewTokenResource() resource.Resource {
@@ -58,7 +63,7 @@ func (r *TokenResource) Metadata(_ context.Context, req resource.MetadataRequest
 	resp.TypeName = req.ProviderTypeName + "_token"
 }
 
-func (r *TokenResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+func (r *TokenResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		Description: "Manages a Casdoor token.",
 		Attributes: map[string]schema.Attribute{
@@ -69,13 +74,7 @@ func (r *TokenResource) Schema(_ context.Context, _ resource.SchemaRequest, resp
 					stringplanmodifier.UseStateForUnknown(),
 				},
 			},
-			"owner": schema.StringAttribute{
-				Description: "The organization that owns this token.",
-				Required:    true,
-				PlanModifiers: []planmodifier.String{
-					stringplanmodifier.RequiresReplace(),
-				},
-			},
+			"owner": adminOwnerAttribute("token"),
 			"name": schema.StringAttribute{
 				Description: "The unique name of the token.",
 				Required:    true,
@@ -181,6 +180,15 @@ func (r *TokenResource) Schema(_ context.Context, _ resource.SchemaRequest, resp
 				Computed:    true,
 				Default:     stringdefault.StaticString(""),
 			},
+			"adopt_existing": adoptExistingAttribute("token"),
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Read:   true,
+				Update: true,
+				Delete: true,
+			}),
 		},
 	}
 }
@@ -190,16 +198,21 @@ func (r *TokenResource) Configure(_ context.Context, req resource.ConfigureReque
 		return
 	}
 
-	client, ok := req.ProviderData.(*casdoorsdk.Client)
+	data, ok := req.ProviderData.(*CasdoorProviderData)
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Resource Configure Type",
-			fmt.Sprintf("Expected *casdoorsdk.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected *CasdoorProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 		return
 	}
 
-	r.client = client
+	r.client = data.Client
+	r.providerData = data
+}
+
+func (r *TokenResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	planAdoption(ctx, req, resp, r.providerData, "token", r.Read)
 }
 
 func tokenPlanToSDK(plan TokenResourceModel, createdTime string) *casdoorsdk.Token {
@@ -224,6 +237,10 @@ func tokenPlanToSDK(plan TokenResourceModel, createdTime string) *casdoorsdk.Tok
 }
 
 func (r *TokenResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	if checkUnconfigured(r.client, &resp.Diagnostics) {
+		return
+	}
+
 	var plan TokenResourceModel
 
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
@@ -231,6 +248,12 @@ func (r *TokenResource) Create(ctx context.Context, req resource.CreateRequest,
 		return
 	}
 
+	ctx, done := startOperation(ctx, plan.Timeouts.Create, defaultOperationTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	createdTime := plan.CreatedTime.ValueString()
 	if createdTime == "" {
 		createdTime = time.Now().UTC().Format(time.RFC3339)
@@ -238,13 +261,20 @@ func (r *TokenResource) Create(ctx context.Context, req resource.CreateRequest,
 
 	token := tokenPlanToSDK(plan, createdTime)
 
-	ok, err := r.client.AddToken(token)
-	if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("creating token %q", plan.Name.ValueString())) {
+	adopted := adoptOnCreate(ctx, r.providerData, req.Plan, req.Config, &resp.Diagnostics, "token", plan.Owner.ValueString()+"/"+plan.Name.ValueString(), token, r.client.Tokens())
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
+	if !adopted {
+		ok, err := r.client.Tokens().Add(ctx, token)
+		if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("creating token %q", plan.Name.ValueString())) {
+			return
+		}
+	}
+
 	// Read back the token to get generated values.
-	createdToken, err := r.client.GetToken(plan.Owner.ValueString() + "/" + plan.Name.ValueString())
+	createdToken, err := r.client.Tokens().Get(ctx, plan.Owner.ValueString()+"/"+plan.Name.ValueString())
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Reading Token After Create",
@@ -266,6 +296,10 @@ func (r *TokenResource) Create(ctx context.Context, req resource.CreateRequest,
 }
 
 func (r *TokenResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	if r.client == nil {
+		return
+	}
+
 	var state TokenResourceModel
 
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
@@ -273,7 +307,13 @@ func (r *TokenResource) Read(ctx context.Context, req resource.ReadRequest, resp
 		return
 	}
 
-	token, err := r.client.GetToken(state.Owner.ValueString() + "/" + state.Name.ValueString())
+	ctx, done := startOperation(ctx, state.Timeouts.Read, defaultReadTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	token, err := r.client.Tokens().Get(ctx, state.Owner.ValueString()+"/"+state.Name.ValueString())
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Reading Token",
@@ -307,29 +347,50 @@ func (r *TokenResource) Read(ctx context.Context, req resource.ReadRequest, resp
 	state.CodeExpireIn = types.Int64Value(token.CodeExpireIn)
 	state.Resource = types.StringValue(token.Resource)
 
+	resp.Diagnostics.Append(setObjectFingerprint(ctx, resp.Private, token)...)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
 }
 
 func (r *TokenResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	var plan TokenResourceModel
+	if checkUnconfigured(r.client, &resp.Diagnostics) {
+		return
+	}
+
+	var plan, state TokenResourceModel
 
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, done := startOperation(ctx, plan.Timeouts.Update, defaultOperationTimeout, &resp.Diagnostics)
+	defer done()
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
+	if checkUpdateConflict(ctx, r.providerData, &resp.Diagnostics, req.Private, "token", state.Owner.ValueString()+"/"+state.Name.ValueString(), r.client.Tokens().Get) {
+		return
+	}
+
 	token := tokenPlanToSDK(plan, plan.CreatedTime.ValueString())
 
-	ok, err := r.client.UpdateToken(token)
+	ok, err := r.client.Tokens().Update(ctx, token)
 	if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("updating token %q", plan.Name.ValueString())) {
 		return
 	}
 
 	plan.ID = types.StringValue(plan.Owner.ValueString() + "/" + plan.Name.ValueString())
+	resp.Diagnostics.Append(clearObjectFingerprint(ctx, resp.Private)...)
 	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
 }
 
 func (r *TokenResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	if checkUnconfigured(r.client, &resp.Diagnostics) {
+		return
+	}
+
 	var state TokenResourceModel
 
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
@@ -337,12 +398,18 @@ func (r *TokenResource) Delete(ctx context.Context, req resource.DeleteRequest,
 		return
 	}
 
+	ctx, done := startOperation(ctx, state.Timeouts.Delete, defaultOperationTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	token := &casdoorsdk.Token{
 		Owner: state.Owner.ValueString(),
 		Name:  state.Name.ValueString(),
 	}
 
-	_, err := r.client.DeleteToken(token)
+	_, err := r.client.Tokens().Delete(ctx, token)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Deleting Token",
@@ -0,0 +1,159 @@
+// Copyright (c) HashiCorp, Inc.
+
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ datasource.DataSource              = &TransactionsDataSource{}
+	_ datasource.DataSourceWithConfigure = &TransactionsDataSource{}
+)
+
+type TransactionsDataSource struct {
+	client       casdoorClient
+	providerData *CasdoorProviderData
+}
+
+type TransactionModel struct {
+	Name        types.String  `tfsdk:"name"`
+	CreatedTime types.String  `tfsdk:"created_time"`
+	DisplayName types.String  `tfsdk:"display_name"`
+	Application types.String  `tfsdk:"application"`
+	Domain      types.String  `tfsdk:"domain"`
+	Category    types.String  `tfsdk:"category"`
+	Type        types.String  `tfsdk:"type"`
+	Subtype     types.String  `tfsdk:"subtype"`
+	Provider    types.String  `tfsdk:"provider"`
+	User        types.String  `tfsdk:"user"`
+	Tag         types.String  `tfsdk:"tag"`
+	Amount      types.Float64 `tfsdk:"amount"`
+	Currency    types.String  `tfsdk:"currency"`
+	Payment     types.String  `tfsdk:"payment"`
+	State       types.String  `tfsdk:"state"`
+}
+
+// transactionRecords are the records listed by the casdoor_transactions
+// data source. Transactions record no products, so they cannot be filtered
+// by one.
+var transactionRecords = billingRecords[casdoorsdk.Transaction, TransactionModel]{
+	kind:        "transaction",
+	plural:      "transactions",
+	description: "Lists the transactions of a Casdoor organization, optionally filtered by user, state and creation time.",
+	attributes: map[string]schema.Attribute{
+		"application": schema.StringAttribute{
+			Description: "The application the transaction was made in.",
+			Computed:    true,
+		},
+		"domain": schema.StringAttribute{
+			Description: "The domain the transaction was made on.",
+			Computed:    true,
+		},
+		"category": schema.StringAttribute{
+			Description: "The category of the transaction.",
+			Computed:    true,
+		},
+		"type": schema.StringAttribute{
+			Description: "The type of the transaction.",
+			Computed:    true,
+		},
+		"subtype": schema.StringAttribute{
+			Description: "The subtype of the transaction.",
+			Computed:    true,
+		},
+		"provider": schema.StringAttribute{
+			Description: "The provider the transaction was made with.",
+			Computed:    true,
+		},
+		"user": schema.StringAttribute{
+			Description: "The name of the user the transaction belongs to.",
+			Computed:    true,
+		},
+		"tag": schema.StringAttribute{
+			Description: "The tag of the transaction.",
+			Computed:    true,
+		},
+		"amount": schema.Float64Attribute{
+			Description: "The amount of the transaction, negative for a debit.",
+			Computed:    true,
+		},
+		"currency": schema.StringAttribute{
+			Description: "The currency of the amount.",
+			Computed:    true,
+		},
+		"payment": schema.StringAttribute{
+			Description: "The name of the payment the transaction belongs to, if any.",
+			Computed:    true,
+		},
+		"state": schema.StringAttribute{
+			Description: "The state of the transaction.",
+			Computed:    true,
+		},
+	},
+
+	list: casdoorClient.ListTransactions,
+	match: func(filter billingFilter, transaction *casdoorsdk.Transaction) bool {
+		return filter.match(transaction.User, nil, transaction.State, transaction.CreatedTime)
+	},
+	flatten: func(_ context.Context, transaction *casdoorsdk.Transaction) (TransactionModel, diag.Diagnostics) {
+		return TransactionModel{
+			Name:        types.StringValue(transaction.Name),
+			CreatedTime: types.StringValue(transaction.CreatedTime),
+			DisplayName: types.StringValue(transaction.DisplayName),
+			Application: types.StringValue(transaction.Application),
+			Domain:      types.StringValue(transaction.Domain),
+			Category:    types.StringValue(transaction.Category),
+			Type:        types.StringValue(transaction.Type),
+			Subtype:     types.StringValue(transaction.Subtype),
+			Provider:    types.StringValue(transaction.Provider),
+			User:        types.StringValue(transaction.User),
+			Tag:         types.StringValue(transaction.Tag),
+			Amount:      types.Float64Value(transaction.Amount),
+			Currency:    types.StringValue(transaction.Currency),
+			Payment:     types.StringValue(transaction.Payment),
+			State:       types.StringValue(transaction.State),
+		}, nil
+	},
+}
+
+func NewTransactionsDataSource() datasource.DataSource {
+	return &TransactionsDataSource{}
+}
+
+func (d *TransactionsDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_transactions"
+}
+
+func (d *TransactionsDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = transactionRecords.schema()
+}
+
+func (d *TransactionsDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*CasdoorProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *CasdoorProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	d.client = data.Client
+	d.providerData = data
+}
+
+func (d *TransactionsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	transactionRecords.read(ctx, d.client, req, resp)
+}
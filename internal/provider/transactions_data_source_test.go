@@ -0,0 +1,83 @@
+// Copyright (c) HashiCorp, Inc.
+
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+// addTestTransactions adds three transactions of users named after name: a
+// credit in January and a debit in March of "<name>-alice", and a credit in
+// February of "<name>-bob".
+func addTestTransactions(t *testing.T, config CasdoorTestConfig, name string) {
+	t.Helper()
+
+	transactions := []*casdoorsdk.Transaction{
+		{Owner: "built-in", Name: name + "-1", CreatedTime: "2026-01-15T10:00:00Z", User: name + "-alice", Category: "Recharge", Amount: 50, Currency: "USD", State: "Paid"},
+		{Owner: "built-in", Name: name + "-2", CreatedTime: "2026-02-15T10:00:00Z", User: name + "-bob", Category: "Recharge", Amount: 20, Currency: "USD", State: "Paid"},
+		{Owner: "built-in", Name: name + "-3", CreatedTime: "2026-03-15T10:00:00Z", User: name + "-alice", Category: "Purchase", Amount: -30, Currency: "USD", State: "Canceled"},
+	}
+	addTestBillingRecords(t, config, "transaction", transactions,
+		func(client *casdoorsdk.Client, transaction *casdoorsdk.Transaction) (bool, error) {
+			ok, _, err := client.AddTransaction(transaction)
+			return ok, err
+		},
+		func(client *casdoorsdk.Client, transaction *casdoorsdk.Transaction) (bool, error) {
+			return client.DeleteTransaction(transaction)
+		},
+	)
+}
+
+func TestAccTransactionsDataSource_basic(t *testing.T) {
+	config := setupTestConfig(t)
+	rName := "tf-test-" + acctest.RandStringFromCharSet(8, acctest.CharSetAlphaNum)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories(config),
+		Steps: []resource.TestStep{
+			{
+				PreConfig: func() { addTestTransactions(t, config, rName) },
+				Config:    testAccProviderConfig(config) + testAccTransactionsDataSourceConfig(rName),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.casdoor_transactions.user", "id", "built-in"),
+					resource.TestCheckResourceAttr("data.casdoor_transactions.user", "transactions.#", "2"),
+					resource.TestCheckResourceAttr("data.casdoor_transactions.user", "transactions.0.name", rName+"-1"),
+					resource.TestCheckResourceAttr("data.casdoor_transactions.user", "transactions.0.amount", "50"),
+					resource.TestCheckResourceAttr("data.casdoor_transactions.user", "transactions.1.amount", "-30"),
+					resource.TestCheckResourceAttr("data.casdoor_transactions.paid", "transactions.#", "1"),
+					resource.TestCheckResourceAttr("data.casdoor_transactions.paid", "transactions.0.name", rName+"-1"),
+					resource.TestCheckResourceAttr("data.casdoor_transactions.february", "transactions.#", "1"),
+					resource.TestCheckResourceAttr("data.casdoor_transactions.february", "transactions.0.user", rName+"-bob"),
+				),
+			},
+		},
+	})
+}
+
+func testAccTransactionsDataSourceConfig(name string) string {
+	return fmt.Sprintf(`
+data "casdoor_transactions" "user" {
+  owner     = "built-in"
+  user      = "%[1]s-alice"
+  page_size = 2
+}
+
+data "casdoor_transactions" "paid" {
+  owner = "built-in"
+  user  = "%[1]s-alice"
+  state = "Paid"
+}
+
+data "casdoor_transactions" "february" {
+  owner          = "built-in"
+  user           = "%[1]s-bob"
+  created_after  = "2026-02-01T00:00:00Z"
+  created_before = "2026-03-01T00:00:00Z"
+}
+`, name)
+}
@@ -5,12 +5,15 @@ package provider
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
 	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
@@ -23,13 +26,16 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
 )
 
 var (
 	_ resource.Resource                = &UserResource{}
 	_ resource.ResourceWithConfigure   = &UserResource{}
 	_ resource.ResourceWithImportState = &UserResource{}
+	_ resource.ResourceWithModifyPlan  = &UserResource{}
 )
 
 // socialLoginFields maps TF map keys to SDK User struct getters/setters.
@@ -236,101 +242,159 @@ func ProductInfoAttrTypes() map[string]attr.Type {
 }
 
 type UserResource struct {
-	client *casdoorsdk.Client
+	client       casdoorClient
+	providerData *CasdoorProviderData
 }
 
 type UserResourceModel struct {
-	Owner                  types.String  `tfsdk:"owner"`
-	Name                   types.String  `tfsdk:"name"`
-	ID                     types.String  `tfsdk:"id"`
-	Type                   types.String  `tfsdk:"type"`
-	Password               types.String  `tfsdk:"password"`
-	PasswordType           types.String  `tfsdk:"password_type"`
-	DisplayName            types.String  `tfsdk:"display_name"`
-	FirstName              types.String  `tfsdk:"first_name"`
-	LastName               types.String  `tfsdk:"last_name"`
-	Avatar                 types.String  `tfsdk:"avatar"`
-	Email                  types.String  `tfsdk:"email"`
-	EmailVerified          types.Bool    `tfsdk:"email_verified"`
-	Phone                  types.String  `tfsdk:"phone"`
-	CountryCode            types.String  `tfsdk:"country_code"`
-	Region                 types.String  `tfsdk:"region"`
-	Location               types.String  `tfsdk:"location"`
-	Affiliation            types.String  `tfsdk:"affiliation"`
-	Title                  types.String  `tfsdk:"title"`
-	Homepage               types.String  `tfsdk:"homepage"`
-	Bio                    types.String  `tfsdk:"bio"`
-	Tag                    types.String  `tfsdk:"tag"`
-	Language               types.String  `tfsdk:"language"`
-	Gender                 types.String  `tfsdk:"gender"`
-	Birthday               types.String  `tfsdk:"birthday"`
-	Education              types.String  `tfsdk:"education"`
-	Score                  types.Int64   `tfsdk:"score"`
-	Karma                  types.Int64   `tfsdk:"karma"`
-	Ranking                types.Int64   `tfsdk:"ranking"`
-	IsAdmin                types.Bool    `tfsdk:"is_admin"`
-	IsForbidden            types.Bool    `tfsdk:"is_forbidden"`
-	IsDeleted              types.Bool    `tfsdk:"is_deleted"`
-	SignupApplication      types.String  `tfsdk:"signup_application"`
-	CreatedTime            types.String  `tfsdk:"created_time"`
-	UpdatedTime            types.String  `tfsdk:"updated_time"`
-	DeletedTime            types.String  `tfsdk:"deleted_time"`
-	ExternalId             types.String  `tfsdk:"external_id"`
-	PasswordSalt           types.String  `tfsdk:"password_salt"`
-	AvatarType             types.String  `tfsdk:"avatar_type"`
-	PermanentAvatar        types.String  `tfsdk:"permanent_avatar"`
-	Address                types.List    `tfsdk:"address"`
-	Addresses              types.List    `tfsdk:"addresses"`
-	IdCardType             types.String  `tfsdk:"id_card_type"`
-	IdCard                 types.String  `tfsdk:"id_card"`
-	RealName               types.String  `tfsdk:"real_name"`
-	IsVerified             types.Bool    `tfsdk:"is_verified"`
-	IsDefaultAvatar        types.Bool    `tfsdk:"is_default_avatar"`
-	IsOnline               types.Bool    `tfsdk:"is_online"`
-	Hash                   types.String  `tfsdk:"hash"`
-	PreHash                types.String  `tfsdk:"pre_hash"`
-	Balance                types.Float64 `tfsdk:"balance"`
-	BalanceCredit          types.Float64 `tfsdk:"balance_credit"`
-	Currency               types.String  `tfsdk:"currency"`
-	BalanceCurrency        types.String  `tfsdk:"balance_currency"`
-	RegisterType           types.String  `tfsdk:"register_type"`
-	RegisterSource         types.String  `tfsdk:"register_source"`
-	AccessKey              types.String  `tfsdk:"access_key"`
-	AccessSecret           types.String  `tfsdk:"access_secret"`
-	AccessToken            types.String  `tfsdk:"access_token"`
-	OriginalToken          types.String  `tfsdk:"original_token"`
-	OriginalRefreshToken   types.String  `tfsdk:"original_refresh_token"`
-	CreatedIp              types.String  `tfsdk:"created_ip"`
-	LastSigninTime         types.String  `tfsdk:"last_signin_time"`
-	LastSigninIp           types.String  `tfsdk:"last_signin_ip"`
-	SocialLogins           types.Map     `tfsdk:"social_logins"`
-	Invitation             types.String  `tfsdk:"invitation"`
-	InvitationCode         types.String  `tfsdk:"invitation_code"`
-	Ldap                   types.String  `tfsdk:"ldap"`
-	Properties             types.Map     `tfsdk:"properties"`
-	NeedUpdatePassword     types.Bool    `tfsdk:"need_update_password"`
-	LastChangePasswordTime types.String  `tfsdk:"last_change_password_time"`
-	LastSigninWrongTime    types.String  `tfsdk:"last_signin_wrong_time"`
-	SigninWrongTimes       types.Int64   `tfsdk:"signin_wrong_times"`
-	PreferredMfaType       types.String  `tfsdk:"preferred_mfa_type"`
-	RecoveryCodes          types.List    `tfsdk:"recovery_codes"`
-	TotpSecret             types.String  `tfsdk:"totp_secret"`
-	MfaPhoneEnabled        types.Bool    `tfsdk:"mfa_phone_enabled"`
-	MfaEmailEnabled        types.Bool    `tfsdk:"mfa_email_enabled"`
-	MfaRadiusEnabled       types.Bool    `tfsdk:"mfa_radius_enabled"`
-	MfaRadiusUsername      types.String  `tfsdk:"mfa_radius_username"`
-	MfaRadiusProvider      types.String  `tfsdk:"mfa_radius_provider"`
-	MfaPushEnabled         types.Bool    `tfsdk:"mfa_push_enabled"`
-	MfaPushReceiver        types.String  `tfsdk:"mfa_push_receiver"`
-	MfaPushProvider        types.String  `tfsdk:"mfa_push_provider"`
-	MfaRememberDeadline    types.String  `tfsdk:"mfa_remember_deadline"`
-	IpWhitelist            types.String  `tfsdk:"ip_whitelist"`
-	ManagedAccounts        types.List    `tfsdk:"managed_accounts"`
-	MfaAccounts            types.List    `tfsdk:"mfa_accounts"`
-	MfaItems               types.List    `tfsdk:"mfa_items"`
-	FaceIds                types.List    `tfsdk:"face_ids"`
-	Cart                   types.List    `tfsdk:"cart"`
-	Groups                 types.List    `tfsdk:"groups"`
+	Owner                  types.String   `tfsdk:"owner"`
+	Name                   types.String   `tfsdk:"name"`
+	ID                     types.String   `tfsdk:"id"`
+	Type                   types.String   `tfsdk:"type"`
+	Password               types.String   `tfsdk:"password"`
+	PasswordType           types.String   `tfsdk:"password_type"`
+	DisplayName            types.String   `tfsdk:"display_name"`
+	FirstName              types.String   `tfsdk:"first_name"`
+	LastName               types.String   `tfsdk:"last_name"`
+	Avatar                 types.String   `tfsdk:"avatar"`
+	Email                  types.String   `tfsdk:"email"`
+	EmailVerified          types.Bool     `tfsdk:"email_verified"`
+	Phone                  types.String   `tfsdk:"phone"`
+	CountryCode            types.String   `tfsdk:"country_code"`
+	Region                 types.String   `tfsdk:"region"`
+	Location               types.String   `tfsdk:"location"`
+	Affiliation            types.String   `tfsdk:"affiliation"`
+	Title                  types.String   `tfsdk:"title"`
+	Homepage               types.String   `tfsdk:"homepage"`
+	Bio                    types.String   `tfsdk:"bio"`
+	Tag                    types.String   `tfsdk:"tag"`
+	Language               types.String   `tfsdk:"language"`
+	Gender                 types.String   `tfsdk:"gender"`
+	Birthday               types.String   `tfsdk:"birthday"`
+	Education              types.String   `tfsdk:"education"`
+	Score                  types.Int64    `tfsdk:"score"`
+	Karma                  types.Int64    `tfsdk:"karma"`
+	Ranking                types.Int64    `tfsdk:"ranking"`
+	IsAdmin                types.Bool     `tfsdk:"is_admin"`
+	IsForbidden            types.Bool     `tfsdk:"is_forbidden"`
+	IsDeleted              types.Bool     `tfsdk:"is_deleted"`
+	SignupApplication      types.String   `tfsdk:"signup_application"`
+	CreatedTime            types.String   `tfsdk:"created_time"`
+	UpdatedTime            types.String   `tfsdk:"updated_time"`
+	DeletedTime            types.String   `tfsdk:"deleted_time"`
+	ExternalId             types.String   `tfsdk:"external_id"`
+	PasswordSalt           types.String   `tfsdk:"password_salt"`
+	AvatarType             types.String   `tfsdk:"avatar_type"`
+	PermanentAvatar        types.String   `tfsdk:"permanent_avatar"`
+	Address                types.List     `tfsdk:"address"`
+	Addresses              types.List     `tfsdk:"addresses"`
+	IdCardType             types.String   `tfsdk:"id_card_type"`
+	IdCard                 types.String   `tfsdk:"id_card"`
+	RealName               types.String   `tfsdk:"real_name"`
+	IsVerified             types.Bool     `tfsdk:"is_verified"`
+	IsDefaultAvatar        types.Bool     `tfsdk:"is_default_avatar"`
+	IsOnline               types.Bool     `tfsdk:"is_online"`
+	Hash                   types.String   `tfsdk:"hash"`
+	PreHash                types.String   `tfsdk:"pre_hash"`
+	Balance                types.Float64  `tfsdk:"balance"`
+	BalanceCredit          types.Float64  `tfsdk:"balance_credit"`
+	Currency               types.String   `tfsdk:"currency"`
+	BalanceCurrency        types.String   `tfsdk:"balance_currency"`
+	RegisterType           types.String   `tfsdk:"register_type"`
+	RegisterSource         types.String   `tfsdk:"register_source"`
+	AccessKey              types.String   `tfsdk:"access_key"`
+	AccessSecret           types.String   `tfsdk:"access_secret"`
+	AccessToken            types.String   `tfsdk:"access_token"`
+	OriginalToken          types.String   `tfsdk:"original_token"`
+	OriginalRefreshToken   types.String   `tfsdk:"original_refresh_token"`
+	CreatedIp              types.String   `tfsdk:"created_ip"`
+	LastSigninTime         types.String   `tfsdk:"last_signin_time"`
+	LastSigninIp           types.String   `tfsdk:"last_signin_ip"`
+	SocialLogins           types.Map      `tfsdk:"social_logins"`
+	Invitation             types.String   `tfsdk:"invitation"`
+	InvitationCode         types.String   `tfsdk:"invitation_code"`
+	Ldap                   types.String   `tfsdk:"ldap"`
+	Properties             types.Map      `tfsdk:"properties"`
+	NeedUpdatePassword     types.Bool     `tfsdk:"need_update_password"`
+	LastChangePasswordTime types.String   `tfsdk:"last_change_password_time"`
+	LastSigninWrongTime    types.String   `tfsdk:"last_signin_wrong_time"`
+	SigninWrongTimes       types.Int64    `tfsdk:"signin_wrong_times"`
+	PreferredMfaType       types.String   `tfsdk:"preferred_mfa_type"`
+	RecoveryCodes          types.List     `tfsdk:"recovery_codes"`
+	TotpSecret             types.String   `tfsdk:"totp_secret"`
+	MfaPhoneEnabled        types.Bool     `tfsdk:"mfa_phone_enabled"`
+	MfaEmailEnabled        types.Bool     `tfsdk:"mfa_email_enabled"`
+	MfaRadiusEnabled       types.Bool     `tfsdk:"mfa_radius_enabled"`
+	MfaRadiusUsername      types.String   `tfsdk:"mfa_radius_username"`
+	MfaRadiusProvider      types.String   `tfsdk:"mfa_radius_provider"`
+	MfaPushEnabled         types.Bool     `tfsdk:"mfa_push_enabled"`
+	MfaPushReceiver        types.String   `tfsdk:"mfa_push_receiver"`
+	MfaPushProvider        types.String   `tfsdk:"mfa_push_provider"`
+	MfaRememberDeadline    types.String   `tfsdk:"mfa_remember_deadline"`
+	IpWhitelist            types.String   `tfsdk:"ip_whitelist"`
+	ManagedAccounts        types.List     `tfsdk:"managed_accounts"`
+	MfaAccounts            types.List     `tfsdk:"mfa_accounts"`
+	MfaItems               types.List     `tfsdk:"mfa_items"`
+	FaceIds                types.List     `tfsdk:"face_ids"`
+	Cart                   types.List     `tfsdk:"cart"`
+	Groups                 types.List     `tfsdk:"groups"`
+	UpdateMode             types.String   `tfsdk:"update_mode"`
+	AdoptExisting          types.Bool     `tfsdk:"adopt_existing"`
+	DeletionProtection     types.Bool     `tfsdk:"deletion_protection"`
+	KeepSoftDeleted        types.Bool     `tfsdk:"keep_soft_deleted"`
+	RestoreSoftDeleted     types.Bool     `tfsdk:"restore_soft_deleted"`
+	Timeouts               timeouts.Value `tfsdk:"timeouts"`
+}
+
+// userBalanceAttributes are the attributes Casdoor maintains from the user's
+// transactions. They are never written, and are planned as unknown, as they
+// may change between plan and apply.
+var userBalanceAttributes = map[string]bool{
+	"balance":          true,
+	"balance_credit":   true,
+	"balance_currency": true,
+}
+
+// userUpdateColumns are the database columns of the user written by Update:
+// those of the attributes the resource manages, like Casdoor's default update
+// columns without the balance. The columns Casdoor maintains itself, such as
+// the sign-in records, the hash or the password salt, are never written back.
+var userUpdateColumns = []string{
+	"owner", "name", "display_name", "first_name", "last_name", "avatar", "avatar_type", "permanent_avatar",
+	"email", "email_verified", "phone", "country_code", "region", "location", "address", "addresses",
+	"affiliation", "title", "id_card_type", "id_card", "real_name", "homepage", "bio", "tag", "type",
+	"language", "gender", "birthday", "education", "score", "karma", "ranking", "currency",
+	"is_verified", "is_admin", "is_forbidden", "signup_application", "register_type", "register_source",
+	"external_id", "ldap", "properties", "groups", "invitation", "invitation_code", "ip_whitelist",
+	"access_key", "access_secret", "access_token", "original_token", "original_refresh_token",
+	"password_type", "need_update_password", "totp_secret", "recovery_codes", "preferred_mfa_type",
+	"mfa_phone_enabled", "mfa_email_enabled", "mfa_radius_enabled", "mfa_radius_username", "mfa_radius_provider",
+	"mfa_push_enabled", "mfa_push_receiver", "mfa_push_provider", "mfa_remember_deadline", "mfa_items",
+	"managedAccounts", "mfaAccounts", "face_ids", "cart",
+}
+
+// userUpdateColumnsFor returns the columns to write for an update: the
+// managed columns and one per social login provider, with the password only
+// when it changes, as Casdoor stores it hashed.
+func userUpdateColumnsFor(passwordChanged bool) []string {
+	columns := slices.Clone(userUpdateColumns)
+	for _, f := range socialLoginFields {
+		columns = append(columns, f.Key)
+	}
+	if passwordChanged {
+		columns = append(columns, "password")
+	}
+
+	return columns
+}
+
+// userSettingAttributes are the attributes that configure how the resource
+// behaves rather than a field of the Casdoor user.
+var userSettingAttributes = map[string]bool{
+	"update_mode":          true,
+	"deletion_protection":  true,
+	"keep_soft_deleted":    true,
+	"restore_soft_deleted": true,
 }
 
 func NewUserResource() resource.Resource {
@@ -341,29 +405,20 @@ func (r *UserResource) Metadata(_ context.Context, req resource.MetadataRequest,
 	resp.TypeName = req.ProviderTypeName + "_user"
 }
 
-func (r *UserResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+func (r *UserResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		Description: "Manages a Casdoor user.",
 		Attributes: map[string]schema.Attribute{
-			"owner": schema.StringAttribute{
-				Description: "The organization that owns this user.",
-				Required:    true,
-				PlanModifiers: []planmodifier.String{
-					stringplanmodifier.RequiresReplace(),
-				},
-			},
+			"owner": ownerAttribute("user"),
 			"name": schema.StringAttribute{
-				Description: "The unique username.",
+				Description: "The unique username. Changing it renames the user in place.",
 				Required:    true,
-				PlanModifiers: []planmodifier.String{
-					stringplanmodifier.RequiresReplace(),
-				},
 			},
 			"id": schema.StringAttribute{
 				Description: "The ID of the user in the format 'owner/name'.",
 				Computed:    true,
 				PlanModifiers: []planmodifier.String{
-					stringplanmodifier.UseStateForUnknown(),
+					useStateUnlessChanged(path.Root("name")),
 				},
 			},
 			"type": schema.StringAttribute{
@@ -528,10 +583,12 @@ func (r *UserResource) Schema(_ context.Context, _ resource.SchemaRequest, resp
 				Default:     booldefault.StaticBool(false),
 			},
 			"is_deleted": schema.BoolAttribute{
-				Description: "Whether the user is soft-deleted.",
-				Optional:    true,
-				Computed:    true,
-				Default:     booldefault.StaticBool(false),
+				Description: "Whether the user is soft-deleted. Server-managed; set when the user is deleted from an " +
+					"organization with enable_soft_deletion.",
+				Computed: true,
+				PlanModifiers: []planmodifier.Bool{
+					boolplanmodifier.UseStateForUnknown(),
+				},
 			},
 			"signup_application": schema.StringAttribute{
 				Description: "The application through which the user signed up.",
@@ -699,16 +756,13 @@ func (r *UserResource) Schema(_ context.Context, _ resource.SchemaRequest, resp
 				},
 			},
 			"balance": schema.Float64Attribute{
-				Description: "The user's balance.",
-				Optional:    true,
-				Computed:    true,
-				Default:     float64default.StaticFloat64(0),
+				Description: "The user's balance. Casdoor maintains it from the user's transactions; " +
+					"change it with casdoor_balance_adjustment.",
+				Computed: true,
 			},
 			"balance_credit": schema.Float64Attribute{
-				Description: "The user's balance credit.",
-				Optional:    true,
+				Description: "The user's balance credit, maintained by Casdoor.",
 				Computed:    true,
-				Default:     float64default.StaticFloat64(0),
 			},
 			"currency": schema.StringAttribute{
 				Description: "The user's currency.",
@@ -717,12 +771,8 @@ func (r *UserResource) Schema(_ context.Context, _ resource.SchemaRequest, resp
 				Default:     stringdefault.StaticString(""),
 			},
 			"balance_currency": schema.StringAttribute{
-				Description: "The user's balance currency.",
-				Optional:    true,
+				Description: "The user's balance currency, maintained by Casdoor.",
 				Computed:    true,
-				PlanModifiers: []planmodifier.String{
-					stringplanmodifier.UseStateForUnknown(),
-				},
 			},
 			"register_type": schema.StringAttribute{
 				Description: "The registration type.",
@@ -1142,6 +1192,39 @@ func (r *UserResource) Schema(_ context.Context, _ resource.SchemaRequest, resp
 				Optional:    true,
 				ElementType: types.StringType,
 			},
+			"update_mode": schema.StringAttribute{
+				Description: "How updates are sent to Casdoor. 'full' (default) sends every attribute, resetting " +
+					"attributes missing from configuration to their defaults. 'partial' sends only the attributes " +
+					"set in configuration and merges them onto the user as it is on the server, so that fields " +
+					"users change themselves (avatar, bio, MFA settings, ...) are preserved and show no drift.",
+				Optional: true,
+				Computed: true,
+				Default:  stringdefault.StaticString(updateModeFull),
+			},
+			"adopt_existing":      adoptExistingAttribute("user"),
+			"deletion_protection": deletionProtectionAttribute("user"),
+			"keep_soft_deleted": schema.BoolAttribute{
+				Description: "Whether a soft-deleted user stays in state with is_deleted = true. By default a user " +
+					"soft-deleted outside Terraform is treated as gone and planned to be created again.",
+				Optional: true,
+				Computed: true,
+				Default:  booldefault.StaticBool(false),
+			},
+			"restore_soft_deleted": schema.BoolAttribute{
+				Description: "Whether creating the user restores a soft-deleted user of the same name, keeping its " +
+					"ID, creation time and history, instead of failing because the name is taken.",
+				Optional: true,
+				Computed: true,
+				Default:  booldefault.StaticBool(false),
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Read:   true,
+				Update: true,
+				Delete: true,
+			}),
 		},
 	}
 }
@@ -1151,16 +1234,17 @@ func (r *UserResource) Configure(_ context.Context, req resource.ConfigureReques
 		return
 	}
 
-	client, ok := req.ProviderData.(*casdoorsdk.Client)
+	data, ok := req.ProviderData.(*CasdoorProviderData)
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Resource Configure Type",
-			fmt.Sprintf("Expected *casdoorsdk.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected *CasdoorProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 		return
 	}
 
-	r.client = client
+	r.client = data.Client
+	r.providerData = data
 }
 
 // userPlanToSDK converts a UserResourceModel into a casdoorsdk.User struct.
@@ -1338,10 +1422,7 @@ func userPlanToSDK(ctx context.Context, plan UserResourceModel, createdTime, upd
 		IdCard:               plan.IdCard.ValueString(),
 		RealName:             plan.RealName.ValueString(),
 		IsVerified:           plan.IsVerified.ValueBool(),
-		Balance:              plan.Balance.ValueFloat64(),
-		BalanceCredit:        plan.BalanceCredit.ValueFloat64(),
 		Currency:             plan.Currency.ValueString(),
-		BalanceCurrency:      plan.BalanceCurrency.ValueString(),
 		RegisterType:         plan.RegisterType.ValueString(),
 		RegisterSource:       plan.RegisterSource.ValueString(),
 		AccessKey:            plan.AccessKey.ValueString(),
@@ -1385,7 +1466,143 @@ func userPlanToSDK(ctx context.Context, plan UserResourceModel, createdTime, upd
 	return user, diags
 }
 
+func (r *UserResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	r.planPartialUpdate(ctx, req, resp)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	planDefaultOwner(ctx, req, resp, r.providerData)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	planAdoption(ctx, req, resp, r.providerData, "user", r.Read)
+}
+
+// planPartialUpdate validates update_mode and, in partial mode, plans every
+// attribute left out of configuration with its current value instead of its
+// default, so that values managed outside Terraform show no drift.
+func (r *UserResource) planPartialUpdate(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var updateMode types.String
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("update_mode"), &updateMode)...)
+	if resp.Diagnostics.HasError() || updateMode.IsUnknown() {
+		return
+	}
+
+	switch updateMode.ValueString() {
+	case updateModeFull:
+		return
+	case updateModePartial:
+	default:
+		resp.Diagnostics.AddAttributeError(
+			path.Root("update_mode"),
+			"Invalid Update Mode",
+			fmt.Sprintf("Expected %q or %q, got: %q", updateModeFull, updateModePartial, updateMode.ValueString()),
+		)
+		return
+	}
+
+	unconfigured, err := unconfiguredAttributes(req.Config)
+	if err != nil {
+		resp.Diagnostics.AddError("Error Planning User", fmt.Sprintf("Could not read configuration: %s", err))
+		return
+	}
+
+	// Settings of the resource itself keep their defaults.
+	var names []string
+	for _, name := range unconfigured {
+		if !userSettingAttributes[name] {
+			names = append(names, name)
+		}
+	}
+
+	var prior map[string]tftypes.Value
+	if !req.State.Raw.IsNull() {
+		if err := req.State.Raw.As(&prior); err != nil {
+			resp.Diagnostics.AddError("Error Planning User", fmt.Sprintf("Could not read prior state: %s", err))
+			return
+		}
+	}
+
+	// Unconfigured attributes keep their prior value, or are left for Casdoor
+	// to decide when the user is created.
+	resp.Plan.Raw, err = replaceAttributes(resp.Plan.Raw, names, func(name string, planned tftypes.Value) tftypes.Value {
+		if v, ok := prior[name]; ok && !userBalanceAttributes[name] {
+			return v
+		}
+		return tftypes.NewValue(planned.Type(), tftypes.UnknownValue)
+	})
+	if err != nil {
+		resp.Diagnostics.AddError("Error Planning User", fmt.Sprintf("Could not plan unconfigured attributes: %s", err))
+	}
+}
+
+// restoreSoftDeleted is called from Create. If a soft-deleted user already
+// holds the planned name and restore_soft_deleted is set, the user is brought
+// back with the planned attributes, keeping its internal ID and creation time.
+// Without restore_soft_deleted an error explaining the options is recorded,
+// as adding the user would fail on the taken name. It returns true if the
+// user was restored.
+func (r *UserResource) restoreSoftDeleted(ctx context.Context, plan UserResourceModel, user *casdoorsdk.User, diags *diag.Diagnostics) bool {
+	id := plan.Owner.ValueString() + "/" + plan.Name.ValueString()
+
+	existing, err := r.client.Users().Get(ctx, id)
+	if err != nil {
+		diags.AddError(
+			"Error Checking for Existing Object",
+			fmt.Sprintf("Could not check for existing user %q: %s", id, err),
+		)
+		return false
+	}
+	if existing == nil || !existing.IsDeleted {
+		return false
+	}
+
+	if !plan.RestoreSoftDeleted.ValueBool() {
+		diags.AddError(
+			"Soft-Deleted User Exists",
+			fmt.Sprintf("The user %q was soft-deleted and still holds its name. Set restore_soft_deleted = true to "+
+				"restore it together with its history, or delete it permanently in Casdoor first.", id),
+		)
+		return false
+	}
+
+	user.Id = existing.Id
+	user.CreatedTime = existing.CreatedTime
+	user.Balance = existing.Balance
+	user.BalanceCredit = existing.BalanceCredit
+	user.BalanceCurrency = existing.BalanceCurrency
+	user.IsDeleted = false
+	user.DeletedTime = ""
+
+	ok, err := r.client.Users().Update(ctx, user)
+	if sdkError(diags, ok, err, fmt.Sprintf("restoring user %q", id)) {
+		return false
+	}
+
+	// Clear the deletion flag explicitly, in case the server's default update
+	// columns leave it out. Nothing is affected if they did not.
+	if _, err := r.client.UpdateUserColumns(ctx, id, user, []string{"is_deleted", "deleted_time"}); err != nil {
+		diags.AddError(
+			"Error Restoring User",
+			fmt.Sprintf("Could not clear the deletion flag of user %q: %s", id, err),
+		)
+		return false
+	}
+
+	return true
+}
+
 func (r *UserResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	if checkUnconfigured(r.client, &resp.Diagnostics) {
+		return
+	}
+
 	var plan UserResourceModel
 
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
@@ -1393,6 +1610,23 @@ func (r *UserResource) Create(ctx context.Context, req resource.CreateRequest, r
 		return
 	}
 
+	ctx, done := startOperation(ctx, plan.Timeouts.Create, defaultOperationTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// In partial update mode, attributes left out of configuration are
+	// planned as unknown and take whatever value Casdoor gives the new user.
+	unknown, err := unknownAttributes(req.Plan.Raw)
+	if err != nil {
+		resp.Diagnostics.AddError("Error Creating User", fmt.Sprintf("Could not read plan: %s", err))
+		return
+	}
+	if plan.Type.IsUnknown() {
+		plan.Type = types.StringValue("normal-user")
+	}
+
 	createdTime := plan.CreatedTime.ValueString()
 	if createdTime == "" {
 		createdTime = time.Now().UTC().Format(time.RFC3339)
@@ -1404,13 +1638,33 @@ func (r *UserResource) Create(ctx context.Context, req resource.CreateRequest, r
 		return
 	}
 
-	ok, err := r.client.AddUser(user)
-	if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("creating user %q", plan.Name.ValueString())) {
+	// Social logins are separate fields of the Casdoor user, not one map.
+	var socialLoginKeys []string
+	if !plan.SocialLogins.IsNull() && !plan.SocialLogins.IsUnknown() {
+		for _, f := range socialLoginFields {
+			socialLoginKeys = append(socialLoginKeys, f.Key)
+		}
+	}
+
+	restored := r.restoreSoftDeleted(ctx, plan, user, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
+	adopted := restored || adoptOnCreate(ctx, r.providerData, req.Plan, req.Config, &resp.Diagnostics, "user", plan.Owner.ValueString()+"/"+plan.Name.ValueString(), user, r.client.Users(), socialLoginKeys...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !adopted {
+		ok, err := r.client.Users().Add(ctx, user)
+		if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("creating user %q", plan.Name.ValueString())) {
+			return
+		}
+	}
+
 	// Read back the user to get the generated ID.
-	createdUser, err := r.client.GetUser(plan.Owner.ValueString() + "/" + plan.Name.ValueString())
+	createdUser, err := r.client.Users().Get(ctx, plan.Owner.ValueString()+"/"+plan.Name.ValueString())
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Reading User After Create",
@@ -1419,64 +1673,54 @@ func (r *UserResource) Create(ctx context.Context, req resource.CreateRequest, r
 		return
 	}
 
-	if createdUser != nil {
-		plan.ID = types.StringValue(plan.Owner.ValueString() + "/" + plan.Name.ValueString())
-		plan.CreatedTime = types.StringValue(createdUser.CreatedTime)
-		plan.UpdatedTime = types.StringValue(createdUser.UpdatedTime)
-		plan.DeletedTime = types.StringValue(createdUser.DeletedTime)
-		plan.IsDefaultAvatar = types.BoolValue(createdUser.IsDefaultAvatar)
-		plan.IsOnline = types.BoolValue(createdUser.IsOnline)
-		plan.Hash = types.StringValue(createdUser.Hash)
-		plan.PreHash = types.StringValue(createdUser.PreHash)
-		plan.CreatedIp = types.StringValue(createdUser.CreatedIp)
-		plan.LastSigninTime = types.StringValue(createdUser.LastSigninTime)
-		plan.LastSigninIp = types.StringValue(createdUser.LastSigninIp)
-		plan.LastChangePasswordTime = types.StringValue(createdUser.LastChangePasswordTime)
-		plan.LastSigninWrongTime = types.StringValue(createdUser.LastSigninWrongTime)
-		plan.SigninWrongTimes = types.Int64Value(int64(createdUser.SigninWrongTimes))
-		plan.BalanceCurrency = types.StringValue(createdUser.BalanceCurrency)
-		plan.RegisterType = types.StringValue(createdUser.RegisterType)
-		// Preserve server-generated sensitive values.
-		if createdUser.PasswordSalt != "***" {
-			plan.PasswordSalt = types.StringValue(createdUser.PasswordSalt)
-		}
-		if createdUser.AccessKey != "***" {
-			plan.AccessKey = types.StringValue(createdUser.AccessKey)
-		}
-		if createdUser.AccessSecret != "***" {
-			plan.AccessSecret = types.StringValue(createdUser.AccessSecret)
-		}
-		if createdUser.TotpSecret != "***" {
-			plan.TotpSecret = types.StringValue(createdUser.TotpSecret)
-		}
-		if createdUser.AccessToken != "***" {
-			plan.AccessToken = types.StringValue(createdUser.AccessToken)
-		}
-		if createdUser.OriginalToken != "***" {
-			plan.OriginalToken = types.StringValue(createdUser.OriginalToken)
-		}
-		if createdUser.OriginalRefreshToken != "***" {
-			plan.OriginalRefreshToken = types.StringValue(createdUser.OriginalRefreshToken)
-		}
-	} else {
-		// GetUser uses the provider's OrganizationName which may differ from the
-		// user's owner. Set computed fields to defaults to avoid unknown values.
-		plan.ID = types.StringValue(plan.Owner.ValueString() + "/" + plan.Name.ValueString())
-		plan.CreatedTime = types.StringValue(createdTime)
-		plan.UpdatedTime = types.StringValue(createdTime)
-		plan.DeletedTime = types.StringValue("")
-		plan.IsDefaultAvatar = types.BoolValue(false)
-		plan.IsOnline = types.BoolValue(false)
-		plan.Hash = types.StringValue("")
-		plan.PreHash = types.StringValue("")
-		plan.CreatedIp = types.StringValue("")
-		plan.LastSigninTime = types.StringValue("")
-		plan.LastSigninIp = types.StringValue("")
-		plan.LastChangePasswordTime = types.StringValue("")
-		plan.LastSigninWrongTime = types.StringValue("")
-		plan.SigninWrongTimes = types.Int64Value(0)
-		plan.BalanceCurrency = types.StringValue("")
-		plan.RegisterType = types.StringValue("")
+	if createdUser == nil {
+		resp.Diagnostics.AddError(
+			"Error Reading User",
+			fmt.Sprintf("User %q not found after creation", plan.Owner.ValueString()+"/"+plan.Name.ValueString()),
+		)
+		return
+	}
+
+	plan.ID = types.StringValue(plan.Owner.ValueString() + "/" + plan.Name.ValueString())
+	plan.CreatedTime = types.StringValue(createdUser.CreatedTime)
+	plan.UpdatedTime = types.StringValue(createdUser.UpdatedTime)
+	plan.DeletedTime = types.StringValue(createdUser.DeletedTime)
+	plan.IsDeleted = types.BoolValue(createdUser.IsDeleted)
+	plan.IsDefaultAvatar = types.BoolValue(createdUser.IsDefaultAvatar)
+	plan.IsOnline = types.BoolValue(createdUser.IsOnline)
+	plan.Hash = types.StringValue(createdUser.Hash)
+	plan.PreHash = types.StringValue(createdUser.PreHash)
+	plan.CreatedIp = types.StringValue(createdUser.CreatedIp)
+	plan.LastSigninTime = types.StringValue(createdUser.LastSigninTime)
+	plan.LastSigninIp = types.StringValue(createdUser.LastSigninIp)
+	plan.LastChangePasswordTime = types.StringValue(createdUser.LastChangePasswordTime)
+	plan.LastSigninWrongTime = types.StringValue(createdUser.LastSigninWrongTime)
+	plan.SigninWrongTimes = types.Int64Value(int64(createdUser.SigninWrongTimes))
+	plan.Balance = types.Float64Value(createdUser.Balance)
+	plan.BalanceCredit = types.Float64Value(createdUser.BalanceCredit)
+	plan.BalanceCurrency = types.StringValue(createdUser.BalanceCurrency)
+	plan.RegisterType = types.StringValue(createdUser.RegisterType)
+	// Preserve server-generated sensitive values.
+	if createdUser.PasswordSalt != "***" {
+		plan.PasswordSalt = types.StringValue(createdUser.PasswordSalt)
+	}
+	if createdUser.AccessKey != "***" {
+		plan.AccessKey = types.StringValue(createdUser.AccessKey)
+	}
+	if createdUser.AccessSecret != "***" {
+		plan.AccessSecret = types.StringValue(createdUser.AccessSecret)
+	}
+	if createdUser.TotpSecret != "***" {
+		plan.TotpSecret = types.StringValue(createdUser.TotpSecret)
+	}
+	if createdUser.AccessToken != "***" {
+		plan.AccessToken = types.StringValue(createdUser.AccessToken)
+	}
+	if createdUser.OriginalToken != "***" {
+		plan.OriginalToken = types.StringValue(createdUser.OriginalToken)
+	}
+	if createdUser.OriginalRefreshToken != "***" {
+		plan.OriginalRefreshToken = types.StringValue(createdUser.OriginalRefreshToken)
 	}
 
 	plan.Groups, diags = stringListFromSDK(ctx, user.Groups)
@@ -1508,9 +1752,29 @@ func (r *UserResource) Create(ctx context.Context, req resource.CreateRequest, r
 	}
 
 	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+	if resp.Diagnostics.HasError() || plan.UpdateMode.ValueString() != updateModePartial {
+		return
+	}
+
+	read := plan
+	resp.Diagnostics.Append(userToState(ctx, &read, createdUser)...)
+	readState := tfsdk.State{Schema: req.Plan.Schema}
+	resp.Diagnostics.Append(readState.Set(ctx, read)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.State.Raw, err = copyAttributes(resp.State.Raw, readState.Raw, unknown)
+	if err != nil {
+		resp.Diagnostics.AddError("Error Creating User", fmt.Sprintf("Could not record attributes of user %q: %s", plan.Name.ValueString(), err))
+	}
 }
 
 func (r *UserResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	if r.client == nil {
+		return
+	}
+
 	var state UserResourceModel
 
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
@@ -1518,7 +1782,13 @@ func (r *UserResource) Read(ctx context.Context, req resource.ReadRequest, resp
 		return
 	}
 
-	user, err := r.client.GetUser(state.Owner.ValueString() + "/" + state.Name.ValueString())
+	ctx, done := startOperation(ctx, state.Timeouts.Read, defaultReadTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	user, err := r.client.Users().Get(ctx, state.Owner.ValueString()+"/"+state.Name.ValueString())
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Reading User",
@@ -1527,11 +1797,70 @@ func (r *UserResource) Read(ctx context.Context, req resource.ReadRequest, resp
 		return
 	}
 
-	if user == nil {
+	if user == nil || (user.IsDeleted && !state.KeepSoftDeleted.ValueBool()) {
 		resp.State.RemoveResource(ctx)
 		return
 	}
 
+	// In partial update mode, lists that were left out of configuration are
+	// not managed and stay null regardless of what the server holds.
+	prior := state
+
+	resp.Diagnostics.Append(userToState(ctx, &state, user)...)
+
+	if state.UpdateMode.IsNull() {
+		state.UpdateMode = types.StringValue(updateModeFull)
+	}
+	if state.DeletionProtection.IsNull() {
+		state.DeletionProtection = types.BoolValue(false)
+	}
+	if state.KeepSoftDeleted.IsNull() {
+		state.KeepSoftDeleted = types.BoolValue(false)
+	}
+	if state.RestoreSoftDeleted.IsNull() {
+		state.RestoreSoftDeleted = types.BoolValue(false)
+	}
+	if state.UpdateMode.ValueString() == updateModePartial {
+		if prior.Address.IsNull() {
+			state.Address = types.ListNull(types.StringType)
+		}
+		if prior.RecoveryCodes.IsNull() {
+			state.RecoveryCodes = types.ListNull(types.StringType)
+		}
+		if prior.Groups.IsNull() {
+			state.Groups = types.ListNull(types.StringType)
+		}
+	}
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(setObjectFingerprint(ctx, resp.Private, userWithoutBalance(user))...)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// userWithoutBalance returns a copy of user without its balance. Transactions
+// change the balance, which is no reason to refuse an update, so update
+// conflicts are detected on the rest. A transaction also bumps updatedTime,
+// which is left out too, so that the rest of the user is always hashed.
+func userWithoutBalance(user *casdoorsdk.User) *casdoorsdk.User {
+	if user == nil {
+		return nil
+	}
+
+	withoutBalance := *user
+	withoutBalance.Balance = 0
+	withoutBalance.BalanceCredit = 0
+	withoutBalance.BalanceCurrency = ""
+	withoutBalance.UpdatedTime = ""
+	return &withoutBalance
+}
+
+// userToState copies the attributes of a Casdoor user into the model.
+func userToState(ctx context.Context, state *UserResourceModel, user *casdoorsdk.User) diag.Diagnostics {
+	var diags diag.Diagnostics
+
 	state.Owner = types.StringValue(user.Owner)
 	state.Name = types.StringValue(user.Name)
 	state.ID = types.StringValue(user.Owner + "/" + user.Name)
@@ -1642,15 +1971,15 @@ func (r *UserResource) Read(ctx context.Context, req resource.ReadRequest, resp
 	}
 
 	// Read address ([]string).
-	var diags diag.Diagnostics
-	state.Address, diags = stringListFromSDK(ctx, user.Address)
-	resp.Diagnostics.Append(diags...)
+	var d diag.Diagnostics
+	state.Address, d = stringListFromSDK(ctx, user.Address)
+	diags.Append(d...)
 
 	// Read addresses ([]*Address).
 	if len(user.Addresses) > 0 {
 		objList := make([]attr.Value, 0, len(user.Addresses))
 		for _, item := range user.Addresses {
-			obj, diags := types.ObjectValue(AddressAttrTypes(), map[string]attr.Value{
+			obj, d := types.ObjectValue(AddressAttrTypes(), map[string]attr.Value{
 				"tag":      types.StringValue(item.Tag),
 				"line1":    types.StringValue(item.Line1),
 				"line2":    types.StringValue(item.Line2),
@@ -1659,11 +1988,11 @@ func (r *UserResource) Read(ctx context.Context, req resource.ReadRequest, resp
 				"zip_code": types.StringValue(item.ZipCode),
 				"region":   types.StringValue(item.Region),
 			})
-			resp.Diagnostics.Append(diags...)
+			diags.Append(d...)
 			objList = append(objList, obj)
 		}
-		list, diags := types.ListValue(types.ObjectType{AttrTypes: AddressAttrTypes()}, objList)
-		resp.Diagnostics.Append(diags...)
+		list, d := types.ListValue(types.ObjectType{AttrTypes: AddressAttrTypes()}, objList)
+		diags.Append(d...)
 		state.Addresses = list
 	} else {
 		state.Addresses = types.ListNull(types.ObjectType{AttrTypes: AddressAttrTypes()})
@@ -1673,17 +2002,17 @@ func (r *UserResource) Read(ctx context.Context, req resource.ReadRequest, resp
 	if len(user.ManagedAccounts) > 0 {
 		objList := make([]attr.Value, 0, len(user.ManagedAccounts))
 		for _, item := range user.ManagedAccounts {
-			obj, diags := types.ObjectValue(ManagedAccountAttrTypes(), map[string]attr.Value{
+			obj, d := types.ObjectValue(ManagedAccountAttrTypes(), map[string]attr.Value{
 				"application": types.StringValue(item.Application),
 				"username":    types.StringValue(item.Username),
 				"password":    types.StringValue(item.Password),
 				"signin_url":  types.StringValue(item.SigninUrl),
 			})
-			resp.Diagnostics.Append(diags...)
+			diags.Append(d...)
 			objList = append(objList, obj)
 		}
-		list, diags := types.ListValue(types.ObjectType{AttrTypes: ManagedAccountAttrTypes()}, objList)
-		resp.Diagnostics.Append(diags...)
+		list, d := types.ListValue(types.ObjectType{AttrTypes: ManagedAccountAttrTypes()}, objList)
+		diags.Append(d...)
 		state.ManagedAccounts = list
 	} else {
 		state.ManagedAccounts = types.ListNull(types.ObjectType{AttrTypes: ManagedAccountAttrTypes()})
@@ -1693,17 +2022,17 @@ func (r *UserResource) Read(ctx context.Context, req resource.ReadRequest, resp
 	if len(user.MfaAccounts) > 0 {
 		objList := make([]attr.Value, 0, len(user.MfaAccounts))
 		for _, item := range user.MfaAccounts {
-			obj, diags := types.ObjectValue(MfaAccountAttrTypes(), map[string]attr.Value{
+			obj, d := types.ObjectValue(MfaAccountAttrTypes(), map[string]attr.Value{
 				"account_name": types.StringValue(item.AccountName),
 				"issuer":       types.StringValue(item.Issuer),
 				"secret_key":   types.StringValue(item.SecretKey),
 				"origin":       types.StringValue(item.Origin),
 			})
-			resp.Diagnostics.Append(diags...)
+			diags.Append(d...)
 			objList = append(objList, obj)
 		}
-		list, diags := types.ListValue(types.ObjectType{AttrTypes: MfaAccountAttrTypes()}, objList)
-		resp.Diagnostics.Append(diags...)
+		list, d := types.ListValue(types.ObjectType{AttrTypes: MfaAccountAttrTypes()}, objList)
+		diags.Append(d...)
 		state.MfaAccounts = list
 	} else {
 		state.MfaAccounts = types.ListNull(types.ObjectType{AttrTypes: MfaAccountAttrTypes()})
@@ -1713,15 +2042,15 @@ func (r *UserResource) Read(ctx context.Context, req resource.ReadRequest, resp
 	if len(user.MfaItems) > 0 {
 		objList := make([]attr.Value, 0, len(user.MfaItems))
 		for _, item := range user.MfaItems {
-			obj, diags := types.ObjectValue(MfaItemAttrTypes(), map[string]attr.Value{
+			obj, d := types.ObjectValue(MfaItemAttrTypes(), map[string]attr.Value{
 				"name": types.StringValue(item.Name),
 				"rule": types.StringValue(item.Rule),
 			})
-			resp.Diagnostics.Append(diags...)
+			diags.Append(d...)
 			objList = append(objList, obj)
 		}
-		list, diags := types.ListValue(types.ObjectType{AttrTypes: MfaItemAttrTypes()}, objList)
-		resp.Diagnostics.Append(diags...)
+		list, d := types.ListValue(types.ObjectType{AttrTypes: MfaItemAttrTypes()}, objList)
+		diags.Append(d...)
 		state.MfaItems = list
 	} else {
 		state.MfaItems = types.ListNull(types.ObjectType{AttrTypes: MfaItemAttrTypes()})
@@ -1741,16 +2070,16 @@ func (r *UserResource) Read(ctx context.Context, req resource.ReadRequest, resp
 			} else {
 				faceIdDataVal = types.ListNull(types.Float64Type)
 			}
-			obj, diags := types.ObjectValue(FaceIdAttrTypes(), map[string]attr.Value{
+			obj, d := types.ObjectValue(FaceIdAttrTypes(), map[string]attr.Value{
 				"name":         types.StringValue(item.Name),
 				"face_id_data": faceIdDataVal,
 				"image_url":    types.StringValue(item.ImageUrl),
 			})
-			resp.Diagnostics.Append(diags...)
+			diags.Append(d...)
 			objList = append(objList, obj)
 		}
-		list, diags := types.ListValue(types.ObjectType{AttrTypes: FaceIdAttrTypes()}, objList)
-		resp.Diagnostics.Append(diags...)
+		list, d := types.ListValue(types.ObjectType{AttrTypes: FaceIdAttrTypes()}, objList)
+		diags.Append(d...)
 		state.FaceIds = list
 	} else {
 		state.FaceIds = types.ListNull(types.ObjectType{AttrTypes: FaceIdAttrTypes()})
@@ -1760,7 +2089,7 @@ func (r *UserResource) Read(ctx context.Context, req resource.ReadRequest, resp
 	if len(user.Cart) > 0 {
 		objList := make([]attr.Value, 0, len(user.Cart))
 		for _, item := range user.Cart {
-			obj, diags := types.ObjectValue(ProductInfoAttrTypes(), map[string]attr.Value{
+			obj, d := types.ObjectValue(ProductInfoAttrTypes(), map[string]attr.Value{
 				"owner":        types.StringValue(item.Owner),
 				"name":         types.StringValue(item.Name),
 				"display_name": types.StringValue(item.DisplayName),
@@ -1773,48 +2102,67 @@ func (r *UserResource) Read(ctx context.Context, req resource.ReadRequest, resp
 				"pricing_name": types.StringValue(item.PricingName),
 				"plan_name":    types.StringValue(item.PlanName),
 			})
-			resp.Diagnostics.Append(diags...)
+			diags.Append(d...)
 			objList = append(objList, obj)
 		}
-		list, diags := types.ListValue(types.ObjectType{AttrTypes: ProductInfoAttrTypes()}, objList)
-		resp.Diagnostics.Append(diags...)
+		list, d := types.ListValue(types.ObjectType{AttrTypes: ProductInfoAttrTypes()}, objList)
+		diags.Append(d...)
 		state.Cart = list
 	} else {
 		state.Cart = types.ListNull(types.ObjectType{AttrTypes: ProductInfoAttrTypes()})
 	}
 
 	// Read recovery_codes.
-	state.RecoveryCodes, diags = stringListFromSDK(ctx, user.RecoveryCodes)
-	resp.Diagnostics.Append(diags...)
+	state.RecoveryCodes, d = stringListFromSDK(ctx, user.RecoveryCodes)
+	diags.Append(d...)
 
 	// Read properties.
 	if len(user.Properties) > 0 {
-		props, diags := types.MapValueFrom(ctx, types.StringType, user.Properties)
-		resp.Diagnostics.Append(diags...)
+		props, d := types.MapValueFrom(ctx, types.StringType, user.Properties)
+		diags.Append(d...)
 		state.Properties = props
 	} else {
 		state.Properties = types.MapValueMust(types.StringType, map[string]attr.Value{})
 	}
 
 	// Read groups.
-	state.Groups, diags = stringListFromSDK(ctx, user.Groups)
-	resp.Diagnostics.Append(diags...)
-
-	if resp.Diagnostics.HasError() {
-		return
-	}
+	state.Groups, d = stringListFromSDK(ctx, user.Groups)
+	diags.Append(d...)
 
-	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+	return diags
 }
 
 func (r *UserResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	var plan UserResourceModel
+	if checkUnconfigured(r.client, &resp.Diagnostics) {
+		return
+	}
+
+	var plan, state UserResourceModel
 
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
+	ctx, done := startOperation(ctx, plan.Timeouts.Update, defaultOperationTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if checkBuiltInModify(r.providerData, &resp.Diagnostics, "user", state.Owner.ValueString()+"/"+state.Name.ValueString()) {
+		return
+	}
+
+	getWithoutBalance := func(ctx context.Context, id string) (*casdoorsdk.User, error) {
+		user, err := r.client.Users().Get(ctx, id)
+		return userWithoutBalance(user), err
+	}
+	if checkUpdateConflict(ctx, r.providerData, &resp.Diagnostics, req.Private, "user", state.Owner.ValueString()+"/"+state.Name.ValueString(), getWithoutBalance) {
+		return
+	}
+
 	groups := make([]string, 0)
 	if !plan.Groups.IsNull() && !plan.Groups.IsUnknown() {
 		resp.Diagnostics.Append(plan.Groups.ElementsAs(ctx, &groups, false)...)
@@ -1945,11 +2293,11 @@ func (r *UserResource) Update(ctx context.Context, req resource.UpdateRequest, r
 
 	// Read the existing user to preserve the Casdoor-internal Id field,
 	// which is immutable and must not change during updates.
-	existingUser, err := r.client.GetUser(plan.Owner.ValueString() + "/" + plan.Name.ValueString())
+	existingUser, err := r.client.Users().Get(ctx, state.Owner.ValueString()+"/"+state.Name.ValueString())
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Reading User Before Update",
-			fmt.Sprintf("Could not read user %q before update: %s", plan.Name.ValueString(), err),
+			fmt.Sprintf("Could not read user %q before update: %s", state.Name.ValueString(), err),
 		)
 		return
 	}
@@ -2002,10 +2350,7 @@ func (r *UserResource) Update(ctx context.Context, req resource.UpdateRequest, r
 		IdCard:               plan.IdCard.ValueString(),
 		RealName:             plan.RealName.ValueString(),
 		IsVerified:           plan.IsVerified.ValueBool(),
-		Balance:              plan.Balance.ValueFloat64(),
-		BalanceCredit:        plan.BalanceCredit.ValueFloat64(),
 		Currency:             plan.Currency.ValueString(),
-		BalanceCurrency:      plan.BalanceCurrency.ValueString(),
 		RegisterType:         plan.RegisterType.ValueString(),
 		RegisterSource:       plan.RegisterSource.ValueString(),
 		AccessKey:            plan.AccessKey.ValueString(),
@@ -2046,8 +2391,40 @@ func (r *UserResource) Update(ctx context.Context, req resource.UpdateRequest, r
 		}
 	}
 
-	ok, err := r.client.UpdateUser(user)
-	if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("updating user %q", plan.Name.ValueString())) {
+	// In partial update mode, only the configured attributes are merged onto
+	// the user as it is on the server.
+	if plan.UpdateMode.ValueString() == updateModePartial && existingUser != nil {
+		configured, err := configuredAttributes(req.Config)
+		if err != nil {
+			resp.Diagnostics.AddError("Error Updating User", fmt.Sprintf("Could not read configuration: %s", err))
+			return
+		}
+
+		keys := make([]string, 0, len(configured))
+		for _, name := range configured {
+			if name == "social_logins" {
+				for _, f := range socialLoginFields {
+					keys = append(keys, f.Key)
+				}
+				continue
+			}
+			keys = append(keys, attributeJSONKey(name))
+		}
+
+		user, err = overlayJSON(existingUser, user, keys)
+		if err != nil {
+			resp.Diagnostics.AddError("Error Updating User", fmt.Sprintf("Could not merge user %q: %s", plan.Name.ValueString(), err))
+			return
+		}
+	}
+
+	// Only the columns of the managed attributes are written, leaving out the
+	// balance, which transactions may change meanwhile.
+	columns := userUpdateColumnsFor(!plan.Password.Equal(state.Password))
+
+	if renameUpdateWith(ctx, resp, r.client, "user", plan.Owner.ValueString(), state.Name.ValueString(), plan.Name.ValueString(), func(id string) (bool, error) {
+		return r.client.UpdateUserColumns(ctx, id, user, columns)
+	}) {
 		return
 	}
 
@@ -2082,10 +2459,27 @@ func (r *UserResource) Update(ctx context.Context, req resource.UpdateRequest, r
 		plan.Cart = types.ListNull(types.ObjectType{AttrTypes: ProductInfoAttrTypes()})
 	}
 
+	// The balance is not written, so state keeps the one read before the
+	// update.
+	var balance, balanceCredit float64
+	var balanceCurrency string
+	if existingUser != nil {
+		balance, balanceCredit, balanceCurrency = existingUser.Balance, existingUser.BalanceCredit, existingUser.BalanceCurrency
+	}
+	plan.Balance = types.Float64Value(balance)
+	plan.BalanceCredit = types.Float64Value(balanceCredit)
+	plan.BalanceCurrency = types.StringValue(balanceCurrency)
+
+	plan.ID = types.StringValue(plan.Owner.ValueString() + "/" + plan.Name.ValueString())
+	resp.Diagnostics.Append(clearObjectFingerprint(ctx, resp.Private)...)
 	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
 }
 
 func (r *UserResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	if checkUnconfigured(r.client, &resp.Diagnostics) {
+		return
+	}
+
 	var state UserResourceModel
 
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
@@ -2093,12 +2487,55 @@ func (r *UserResource) Delete(ctx context.Context, req resource.DeleteRequest, r
 		return
 	}
 
+	ctx, done := startOperation(ctx, state.Timeouts.Delete, defaultOperationTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	id := state.Owner.ValueString() + "/" + state.Name.ValueString()
+	if checkDeletionProtection(&resp.Diagnostics, state.DeletionProtection, "user", id) ||
+		checkBuiltInDelete(r.providerData, &resp.Diagnostics, "user", id) {
+		return
+	}
+
+	// Organizations with soft deletion keep deleted users around, flagged as
+	// deleted, so that they can be restored later.
+	org, err := r.client.Organizations().Get(ctx, state.Owner.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Deleting User",
+			fmt.Sprintf("Could not read organization %q of user %q: %s", state.Owner.ValueString(), state.Name.ValueString(), err),
+		)
+		return
+	}
+	if org != nil && org.EnableSoftDeletion {
+		existing, err := r.client.Users().Get(ctx, id)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error Deleting User",
+				fmt.Sprintf("Could not read user %q before deletion: %s", state.Name.ValueString(), err),
+			)
+			return
+		}
+		if existing == nil || existing.IsDeleted {
+			return
+		}
+
+		existing.IsDeleted = true
+		existing.DeletedTime = time.Now().UTC().Format(time.RFC3339)
+
+		ok, err := r.client.UpdateUserColumns(ctx, existing.GetId(), existing, []string{"is_deleted", "deleted_time"})
+		sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("soft-deleting user %q", state.Name.ValueString()))
+		return
+	}
+
 	user := &casdoorsdk.User{
 		Owner: state.Owner.ValueString(),
 		Name:  state.Name.ValueString(),
 	}
 
-	_, err := r.client.DeleteUser(user)
+	_, err = r.client.Users().Delete(ctx, user)
 	if err != nil {
 		// Casdoor returns "session is nil" when deleting users from the built-in
 		// organization. This is a known server-side bug; treat it as a warning
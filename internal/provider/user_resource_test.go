@@ -4,11 +4,15 @@ package provider
 
 import (
 	"fmt"
+	"slices"
 	"testing"
 
 	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
 	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
 )
 
 // enableBuiltInUserCreation enables user creation in the built-in org by
@@ -98,6 +102,161 @@ func TestAccUserResource_import(t *testing.T) {
 	})
 }
 
+func TestAccUserResource_partialUpdate(t *testing.T) {
+	config := setupTestConfig(t)
+	enableBuiltInUserCreation(t, config)
+	rName := "tf-test-" + acctest.RandStringFromCharSet(8, acctest.CharSetAlphaNum)
+	resourceName := "casdoor_user.test"
+
+	// setBio changes the user's bio outside of Terraform.
+	setBio := func() {
+		client := casdoorsdk.NewClient(
+			config.Endpoint,
+			config.ClientID,
+			config.ClientSecret,
+			config.Certificate,
+			config.OrganizationName,
+			config.ApplicationName,
+		)
+
+		user, err := client.GetUser(config.OrganizationName + "/" + rName)
+		if err != nil || user == nil {
+			t.Fatalf("Failed to get user: %v", err)
+		}
+
+		user.Bio = "Set outside Terraform"
+		if _, err := client.UpdateUser(user); err != nil {
+			t.Fatalf("Failed to update user: %v", err)
+		}
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories(config),
+		Steps: []resource.TestStep{
+			// Create in partial mode
+			{
+				Config: testAccProviderConfig(config) + testAccUserResourcePartialConfig(config.OrganizationName, rName, "Test User"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "update_mode", "partial"),
+					resource.TestCheckResourceAttr(resourceName, "display_name", "Test User"),
+					resource.TestCheckResourceAttr(resourceName, "type", "normal-user"),
+				),
+			},
+			// A field changed outside Terraform shows no drift
+			{
+				PreConfig: setBio,
+				Config:    testAccProviderConfig(config) + testAccUserResourcePartialConfig(config.OrganizationName, rName, "Test User"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectEmptyPlan(),
+					},
+				},
+				Check: resource.TestCheckResourceAttr(resourceName, "bio", "Set outside Terraform"),
+			},
+			// Updating a managed attribute preserves the unmanaged one
+			{
+				Config: testAccProviderConfig(config) + testAccUserResourcePartialConfig(config.OrganizationName, rName, "Updated User"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "display_name", "Updated User"),
+					resource.TestCheckResourceAttr(resourceName, "bio", "Set outside Terraform"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccUserResource_softDeletion(t *testing.T) {
+	config := setupTestConfig(t)
+	orgName := "tf-test-" + acctest.RandStringFromCharSet(8, acctest.CharSetAlphaNum)
+	rName := "tf-test-" + acctest.RandStringFromCharSet(8, acctest.CharSetAlphaNum)
+	resourceName := "casdoor_user.test"
+
+	// checkSoftDeleted verifies that the user is still in Casdoor, flagged as
+	// deleted.
+	checkSoftDeleted := func(*terraform.State) error {
+		client := casdoorsdk.NewClient(
+			config.Endpoint,
+			config.ClientID,
+			config.ClientSecret,
+			config.Certificate,
+			config.OrganizationName,
+			config.ApplicationName,
+		)
+
+		user, err := client.GetUser(orgName + "/" + rName)
+		if err != nil {
+			return err
+		}
+		if user == nil {
+			return fmt.Errorf("user %s/%s was deleted permanently", orgName, rName)
+		}
+		if !user.IsDeleted {
+			return fmt.Errorf("user %s/%s is not flagged as deleted", orgName, rName)
+		}
+		return nil
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories(config),
+		Steps: []resource.TestStep{
+			// Create a user in an organization with soft deletion
+			{
+				Config: testAccProviderConfig(config) + testAccUserResourceSoftDeletionConfig(orgName, rName, true),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "is_deleted", "false"),
+					resource.TestCheckResourceAttr(resourceName, "restore_soft_deleted", "true"),
+				),
+			},
+			// Destroying the user only flags it as deleted
+			{
+				Config: testAccProviderConfig(config) + testAccUserResourceSoftDeletionConfig(orgName, rName, false),
+				Check:  checkSoftDeleted,
+			},
+			// Creating it again restores it
+			{
+				Config: testAccProviderConfig(config) + testAccUserResourceSoftDeletionConfig(orgName, rName, true),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "is_deleted", "false"),
+					resource.TestCheckResourceAttr(resourceName, "deleted_time", ""),
+				),
+			},
+		},
+	})
+}
+
+func TestAccUserResource_otherOrganization(t *testing.T) {
+	config := setupTestConfig(t)
+	rName := "tf-test-" + acctest.RandStringFromCharSet(8, acctest.CharSetAlphaNum)
+	orgName := "tf-test-org-" + acctest.RandStringFromCharSet(8, acctest.CharSetAlphaNum)
+	resourceName := "casdoor_user.test"
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories(config),
+		Steps: []resource.TestStep{
+			// Create in an organization other than the provider's, and read
+			// the user back from its own organization.
+			{
+				Config: testAccProviderConfig(config) + testAccUserResourceOtherOrganizationConfig(orgName, rName),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "owner", orgName),
+					resource.TestCheckResourceAttr(resourceName, "name", rName),
+					resource.TestCheckResourceAttr(resourceName, "id", orgName+"/"+rName),
+					resource.TestCheckResourceAttrSet(resourceName, "created_time"),
+				),
+			},
+			// ImportState testing
+			{
+				Config:                  testAccProviderConfig(config) + testAccUserResourceOtherOrganizationConfig(orgName, rName),
+				ResourceName:            resourceName,
+				ImportState:             true,
+				ImportStateId:           orgName + "/" + rName,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"password", "password_salt", "access_key", "access_secret", "totp_secret", "recovery_codes", "id_card"},
+			},
+		},
+	})
+}
+
 func testAccUserResourceConfig(owner, name, displayName string) string {
 	return fmt.Sprintf(`
 resource "casdoor_user" "test" {
@@ -107,3 +266,142 @@ resource "casdoor_user" "test" {
 }
 `, owner, name, displayName)
 }
+
+func testAccUserResourcePartialConfig(owner, name, displayName string) string {
+	return fmt.Sprintf(`
+resource "casdoor_user" "test" {
+  owner        = %q
+  name         = %q
+  display_name = %q
+  update_mode  = "partial"
+}
+`, owner, name, displayName)
+}
+
+func testAccUserResourceSoftDeletionConfig(orgName, name string, withUser bool) string {
+	config := fmt.Sprintf(`
+resource "casdoor_organization" "test" {
+  name                  = %q
+  display_name          = "Soft Deletion Organization"
+  enable_soft_deletion  = true
+  has_privilege_consent = true
+  delete_policy         = "cascade"
+}
+`, orgName)
+
+	if withUser {
+		config += fmt.Sprintf(`
+resource "casdoor_user" "test" {
+  owner                = casdoor_organization.test.name
+  name                 = %q
+  display_name         = "Soft Deleted User"
+  restore_soft_deleted = true
+}
+`, name)
+	}
+
+	return config
+}
+
+func testAccUserResourceOtherOrganizationConfig(orgName, name string) string {
+	return fmt.Sprintf(`
+resource "casdoor_organization" "test" {
+  name                  = %q
+  display_name          = "Other Organization"
+  has_privilege_consent = true
+}
+
+resource "casdoor_user" "test" {
+  owner        = casdoor_organization.test.name
+  name         = %q
+  display_name = "Other Organization User"
+}
+`, orgName, name)
+}
+
+func TestUserResource_updateColumns(t *testing.T) {
+	client := newFakeClient()
+	r, s := testResource(t, NewUserResource(), client, CasdoorProviderData{})
+
+	config := testObjectValue(t, s, map[string]tftypes.Value{
+		"owner":        tftypes.NewValue(tftypes.String, "built-in"),
+		"name":         tftypes.NewValue(tftypes.String, "columns"),
+		"display_name": tftypes.NewValue(tftypes.String, "Columns"),
+		"password":     tftypes.NewValue(tftypes.String, "secret"),
+	})
+	state := testCreate(t, r, s, config)
+	state = testRead(t, r, state)
+
+	lastColumns := func() []string {
+		t.Helper()
+		call := client.calls[len(client.calls)-1]
+		if call.Action != "update-user" || call.ID != "built-in/columns" {
+			t.Fatalf("Expected an update of built-in/columns, got %s %s", call.Action, call.ID)
+		}
+		return call.Columns
+	}
+
+	config = testWithAttributes(t, config, map[string]tftypes.Value{
+		"display_name": tftypes.NewValue(tftypes.String, "Renamed"),
+	})
+	plan := testWithAttributes(t, state.Raw, map[string]tftypes.Value{
+		"display_name": tftypes.NewValue(tftypes.String, "Renamed"),
+	})
+	state = testUpdate(t, r, state, config, plan)
+
+	columns := lastColumns()
+	if !slices.Equal(columns, userUpdateColumnsFor(false)) {
+		t.Errorf("Expected the managed columns %q, got %q", userUpdateColumnsFor(false), columns)
+	}
+	for _, column := range []string{
+		"balance", "balance_credit", "balance_currency", "password", "password_salt", "hash", "pre_hash",
+		"created_ip", "last_signin_time", "last_signin_ip", "signin_wrong_times", "updated_time", "deleted_time",
+	} {
+		if slices.Contains(columns, column) {
+			t.Errorf("Expected column %q not to be written", column)
+		}
+	}
+	if !slices.Contains(columns, "display_name") || !slices.Contains(columns, "github") {
+		t.Errorf("Expected the display name and social login columns to be written, got %q", columns)
+	}
+
+	// A new password is written.
+	config = testWithAttributes(t, config, map[string]tftypes.Value{
+		"password": tftypes.NewValue(tftypes.String, "changed"),
+	})
+	plan = testWithAttributes(t, state.Raw, map[string]tftypes.Value{
+		"password": tftypes.NewValue(tftypes.String, "changed"),
+	})
+	testUpdate(t, r, state, config, plan)
+
+	if columns := lastColumns(); !slices.Contains(columns, "password") {
+		t.Errorf("Expected the changed password to be written, got %q", columns)
+	}
+}
+
+func TestUserWithoutBalance(t *testing.T) {
+	before := &casdoorsdk.User{Owner: "built-in", Name: "alice", DisplayName: "Alice", Balance: 10, BalanceCurrency: "USD", UpdatedTime: "2026-01-01T00:00:00Z"}
+	after := *before
+	after.Balance = 20
+	after.BalanceCredit = 5
+	after.UpdatedTime = "2026-01-02T00:00:00Z"
+
+	fingerprint := func(user *casdoorsdk.User) string {
+		t.Helper()
+		got, err := objectFingerprint(userWithoutBalance(user))
+		if err != nil {
+			t.Fatalf("Failed to fingerprint user: %v", err)
+		}
+		return got
+	}
+
+	// A transaction changes the balance and bumps updatedTime.
+	if a, b := fingerprint(before), fingerprint(&after); a != b {
+		t.Errorf("Expected a balance-only change to keep the fingerprint, got %q and %q", a, b)
+	}
+
+	after.DisplayName = "Changed"
+	if a, b := fingerprint(before), fingerprint(&after); a == b {
+		t.Errorf("Expected a changed display name to change the fingerprint, got %q for both", a)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
 	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
@@ -24,10 +25,12 @@ var (
 	_ resource.Resource                = &WebhookResource{}
 	_ resource.ResourceWithConfigure   = &WebhookResource{}
 	_ resource.ResourceWithImportState = &WebhookResource{}
+	_ resource.ResourceWithModifyPlan  = &WebhookResource{}
 )
 
 type WebhookResource struct {
-	client *casdoorsdk.Client
+	client       casdoorClient
+	providerData *CasdoorProviderData
 }
 
 type HeaderModel struct {
@@ -36,21 +39,23 @@ type HeaderModel struct {
 }
 
 type WebhookResourceModel struct {
-	ID             types.String `tfsdk:"id"`
-	Owner          types.String `tfsdk:"owner"`
-	Name           types.String `tfsdk:"name"`
-	CreatedTime    types.String `tfsdk:"created_time"`
-	Organization   types.String `tfsdk:"organization"`
-	Url            types.String `tfsdk:"url"`
-	Method         types.String `tfsdk:"method"`
-	ContentType    types.String `tfsdk:"content_type"`
-	Headers        types.List   `tfsdk:"headers"`
-	Events         types.List   `tfsdk:"events"`
-	TokenFields    types.List   `tfsdk:"token_fields"`
-	ObjectFields   types.List   `tfsdk:"object_fields"`
-	IsUserExtended types.Bool   `tfsdk:"is_user_extended"`
-	SingleOrgOnly  types.Bool   `tfsdk:"single_org_only"`
-	IsEnabled      types.Bool   `tfsdk:"is_enabled"`
+	ID             types.String   `tfsdk:"id"`
+	Owner          types.String   `tfsdk:"owner"`
+	Name           types.String   `tfsdk:"name"`
+	CreatedTime    types.String   `tfsdk:"created_time"`
+	Organization   types.String   `tfsdk:"organization"`
+	Url            types.String   `tfsdk:"url"`
+	Method         types.String   `tfsdk:"method"`
+	ContentType    types.String   `tfsdk:"content_type"`
+	Headers        types.List     `tfsdk:"headers"`
+	Events         types.List     `tfsdk:"events"`
+	TokenFields    types.List     `tfsdk:"token_fields"`
+	ObjectFields   types.List     `tfsdk:"object_fields"`
+	IsUserExtended types.Bool     `tfsdk:"is_user_extended"`
+	SingleOrgOnly  types.Bool     `tfsdk:"single_org_only"`
+	IsEnabled      types.Bool     `tfsdk:"is_enabled"`
+	AdoptExisting  types.Bool     `tfsdk:"adopt_existing"`
+	Timeouts       timeouts.Value `tfsdk:"timeouts"`
 }
 
 func NewWebhookResource() resource.Resource {
@@ -66,7 +71,7 @@ var headerAttrTypes = map[string]attr.Type{
 	"value": types.StringType,
 }
 
-func (r *WebhookResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+func (r *WebhookResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		Description: "Manages a Casdoor webhook for event notifications.",
 		Attributes: map[string]schema.Attribute{
@@ -77,13 +82,7 @@ func (r *WebhookResource) Schema(_ context.Context, _ resource.SchemaRequest, re
 					stringplanmodifier.UseStateForUnknown(),
 				},
 			},
-			"owner": schema.StringAttribute{
-				Description: "The organization that owns this webhook.",
-				Required:    true,
-				PlanModifiers: []planmodifier.String{
-					stringplanmodifier.RequiresReplace(),
-				},
-			},
+			"owner": ownerAttribute("webhook"),
 			"name": schema.StringAttribute{
 				Description: "The unique name of the webhook.",
 				Required:    true,
@@ -185,6 +184,15 @@ func (r *WebhookResource) Schema(_ context.Context, _ resource.SchemaRequest, re
 				Computed:    true,
 				Default:     booldefault.StaticBool(true),
 			},
+			"adopt_existing": adoptExistingAttribute("webhook"),
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Read:   true,
+				Update: true,
+				Delete: true,
+			}),
 		},
 	}
 }
@@ -194,16 +202,26 @@ func (r *WebhookResource) Configure(_ context.Context, req resource.ConfigureReq
 		return
 	}
 
-	client, ok := req.ProviderData.(*casdoorsdk.Client)
+	data, ok := req.ProviderData.(*CasdoorProviderData)
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Resource Configure Type",
-			fmt.Sprintf("Expected *casdoorsdk.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected *CasdoorProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 		return
 	}
 
-	r.client = client
+	r.client = data.Client
+	r.providerData = data
+}
+
+func (r *WebhookResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	planDefaultOwner(ctx, req, resp, r.providerData)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	planAdoption(ctx, req, resp, r.providerData, "webhook", r.Read)
 }
 
 func webhookHeadersToSDK(ctx context.Context, plan WebhookResourceModel) ([]*casdoorsdk.Header, error) {
@@ -289,6 +307,10 @@ func webhookPlanToSDK(ctx context.Context, plan WebhookResourceModel, createdTim
 }
 
 func (r *WebhookResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	if checkUnconfigured(r.client, &resp.Diagnostics) {
+		return
+	}
+
 	var plan WebhookResourceModel
 
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
@@ -296,6 +318,12 @@ func (r *WebhookResource) Create(ctx context.Context, req resource.CreateRequest
 		return
 	}
 
+	ctx, done := startOperation(ctx, plan.Timeouts.Create, defaultOperationTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	createdTime := plan.CreatedTime.ValueString()
 	if createdTime == "" {
 		createdTime = time.Now().UTC().Format(time.RFC3339)
@@ -307,13 +335,20 @@ func (r *WebhookResource) Create(ctx context.Context, req resource.CreateRequest
 		return
 	}
 
-	ok, err := r.client.AddWebhook(webhook)
-	if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("creating webhook %q", plan.Name.ValueString())) {
+	adopted := adoptOnCreate(ctx, r.providerData, req.Plan, req.Config, &resp.Diagnostics, "webhook", plan.Owner.ValueString()+"/"+plan.Name.ValueString(), webhook, r.client.Webhooks())
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
+	if !adopted {
+		ok, err := r.client.Webhooks().Add(ctx, webhook)
+		if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("creating webhook %q", plan.Name.ValueString())) {
+			return
+		}
+	}
+
 	// Read back the webhook to get server-generated values.
-	createdWebhook, err := r.client.GetWebhook(plan.Owner.ValueString() + "/" + plan.Name.ValueString())
+	createdWebhook, err := r.client.Webhooks().Get(ctx, plan.Owner.ValueString()+"/"+plan.Name.ValueString())
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Reading Webhook",
@@ -344,6 +379,10 @@ func (r *WebhookResource) Create(ctx context.Context, req resource.CreateRequest
 }
 
 func (r *WebhookResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	if r.client == nil {
+		return
+	}
+
 	var state WebhookResourceModel
 
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
@@ -351,7 +390,13 @@ func (r *WebhookResource) Read(ctx context.Context, req resource.ReadRequest, re
 		return
 	}
 
-	webhook, err := r.client.GetWebhook(state.Owner.ValueString() + "/" + state.Name.ValueString())
+	ctx, done := startOperation(ctx, state.Timeouts.Read, defaultReadTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	webhook, err := r.client.Webhooks().Get(ctx, state.Owner.ValueString()+"/"+state.Name.ValueString())
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Reading Webhook",
@@ -391,33 +436,54 @@ func (r *WebhookResource) Read(ctx context.Context, req resource.ReadRequest, re
 	objectFieldsList, _ := types.ListValueFrom(ctx, types.StringType, webhook.ObjectFields)
 	state.ObjectFields = objectFieldsList
 
+	resp.Diagnostics.Append(setObjectFingerprint(ctx, resp.Private, webhook)...)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
 }
 
 func (r *WebhookResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	var plan WebhookResourceModel
+	if checkUnconfigured(r.client, &resp.Diagnostics) {
+		return
+	}
+
+	var plan, state WebhookResourceModel
 
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
+	ctx, done := startOperation(ctx, plan.Timeouts.Update, defaultOperationTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if checkUpdateConflict(ctx, r.providerData, &resp.Diagnostics, req.Private, "webhook", state.Owner.ValueString()+"/"+state.Name.ValueString(), r.client.Webhooks().Get) {
+		return
+	}
+
 	webhook, diags := webhookPlanToSDK(ctx, plan, plan.CreatedTime.ValueString())
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	ok, err := r.client.UpdateWebhook(webhook)
+	ok, err := r.client.Webhooks().Update(ctx, webhook)
 	if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("updating webhook %q", plan.Name.ValueString())) {
 		return
 	}
 
 	plan.ID = types.StringValue(plan.Owner.ValueString() + "/" + plan.Name.ValueString())
+	resp.Diagnostics.Append(clearObjectFingerprint(ctx, resp.Private)...)
 	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
 }
 
 func (r *WebhookResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	if checkUnconfigured(r.client, &resp.Diagnostics) {
+		return
+	}
+
 	var state WebhookResourceModel
 
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
@@ -425,12 +491,18 @@ func (r *WebhookResource) Delete(ctx context.Context, req resource.DeleteRequest
 		return
 	}
 
+	ctx, done := startOperation(ctx, state.Timeouts.Delete, defaultOperationTimeout, &resp.Diagnostics)
+	defer done()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	webhook := &casdoorsdk.Webhook{
 		Owner: state.Owner.ValueString(),
 		Name:  state.Name.ValueString(),
 	}
 
-	ok, err := r.client.DeleteWebhook(webhook)
+	ok, err := r.client.Webhooks().Delete(ctx, webhook)
 	if sdkError(&resp.Diagnostics, ok, err, fmt.Sprintf("deleting webhook %q", state.Name.ValueString())) {
 		return
 	}